@@ -61,6 +61,11 @@ func Run() {
 		log.Fatalf("Error loading configuration file (%s).\n", configLoadError.Error())
 	}
 
+	aliasesLoadError := config.LoadAliases()
+	if aliasesLoadError != nil {
+		log.Fatalf("Error loading aliases file (%s).\n", aliasesLoadError.Error())
+	}
+
 	app.MouseEnabled = configuration.MouseEnabled
 
 	go func() {
@@ -121,9 +126,32 @@ func Run() {
 			window.RegisterCommand(commandimpls.NewUserCommand(userSetCmd, userGetCmd))
 			serverJoinCmd := commandimpls.NewServerJoinCommand(window, discord)
 			serverLeaveCmd := commandimpls.NewServerLeaveCommand(window, discord)
+			serverChannelCmd := commandimpls.NewServerChannelCommand(window, discord)
+			serverInviteCmd := commandimpls.NewServerInviteCommand(window, discord)
+			serverMembersCmd := commandimpls.NewServerMembersCommand(window, discord)
+			serverRolesCmd := commandimpls.NewServerRolesCommand(window, discord)
 			window.RegisterCommand(serverJoinCmd)
 			window.RegisterCommand(serverLeaveCmd)
-			window.RegisterCommand(commandimpls.NewServerCommand(serverJoinCmd, serverLeaveCmd))
+			window.RegisterCommand(serverChannelCmd)
+			window.RegisterCommand(serverInviteCmd)
+			window.RegisterCommand(serverMembersCmd)
+			window.RegisterCommand(serverRolesCmd)
+			window.RegisterCommand(commandimpls.NewServerCommand(serverJoinCmd, serverLeaveCmd,
+				serverChannelCmd, serverInviteCmd, serverMembersCmd, serverRolesCmd))
+			window.RegisterCommand(commandimpls.NewKickCommand(window, discord))
+			window.RegisterCommand(commandimpls.NewBanCommand(window, discord))
+			window.RegisterCommand(commandimpls.NewUnbanCommand(window, discord))
+			window.RegisterCommand(commandimpls.NewTimeoutCommand(window, discord))
+			window.RegisterCommand(commandimpls.NewPurgeCommand(window, discord))
+			window.RegisterCommand(commandimpls.NewRoleCommand(window, discord))
+			window.RegisterCommand(commandimpls.NewScriptsCommand(window.GetScriptEngine(), window.ReformatMessages))
+			window.RegisterCommand(commandimpls.NewGrepCommand())
+			window.RegisterCommand(commandimpls.NewAliasCommand())
+			window.RegisterCommand(commandimpls.NewHistoryCommand(window.GetCommandHistory()))
+			window.RegisterCommand(commandimpls.NewSearchCommand(window, discord))
+			window.RegisterCommand(commandimpls.NewExportCommand(window, discord))
+			window.RegisterCommand(commandimpls.NewReactCommand(window, discord))
+			window.RegisterCommand(commandimpls.NewReadCommand(window, discord))
 		})
 	}()
 
@@ -0,0 +1,84 @@
+package commands
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// ExpandAlias substitutes the given parameters into the command lines of an
+// alias. "$1", "$2" and so on are replaced with the parameter at that
+// position, "$@" is replaced with all parameters and "$$" is replaced with
+// a single "$". If none of the command lines contain a placeholder, the
+// parameters are appended to the last command line instead. Parameters are
+// quoted where necessary, so that they are parsed as a single parameter
+// again.
+func ExpandAlias(commandLines []string, parameters []string) []string {
+	expanded := make([]string, 0, len(commandLines))
+	var usedPlaceholder bool
+	for _, commandLine := range commandLines {
+		var result strings.Builder
+		runes := []rune(commandLine)
+		for index := 0; index < len(runes); index++ {
+			char := runes[index]
+			if char != '$' || index == len(runes)-1 {
+				result.WriteRune(char)
+				continue
+			}
+
+			next := runes[index+1]
+			if next == '$' {
+				result.WriteRune('$')
+				index++
+			} else if next == '@' {
+				usedPlaceholder = true
+				result.WriteString(joinParameters(parameters))
+				index++
+			} else if unicode.IsDigit(next) {
+				usedPlaceholder = true
+				end := index + 1
+				for end < len(runes) && unicode.IsDigit(runes[end]) {
+					end++
+				}
+				position, _ := strconv.Atoi(string(runes[index+1 : end]))
+				if position > 0 && position <= len(parameters) {
+					result.WriteString(QuoteParameter(parameters[position-1]))
+				}
+				index = end - 1
+			} else {
+				result.WriteRune(char)
+			}
+		}
+
+		expanded = append(expanded, result.String())
+	}
+
+	if !usedPlaceholder && len(parameters) > 0 && len(expanded) > 0 {
+		expanded[len(expanded)-1] += " " + joinParameters(parameters)
+	}
+
+	return expanded
+}
+
+func joinParameters(parameters []string) string {
+	quoted := make([]string, 0, len(parameters))
+	for _, parameter := range parameters {
+		quoted = append(quoted, QuoteParameter(parameter))
+	}
+
+	return strings.Join(quoted, " ")
+}
+
+// QuoteParameter quotes the given parameter if it contains characters that
+// would otherwise cause it to be split or interpreted by ParseCommandLine.
+// Backslashes are escaped before the quotes, so that the backslashes added
+// for the quotes aren't escaped again.
+func QuoteParameter(parameter string) string {
+	if parameter != "" && !strings.ContainsAny(parameter, " \t\"\\;|>") {
+		return parameter
+	}
+
+	escaped := strings.Replace(parameter, "\\", "\\\\", -1)
+	escaped = strings.Replace(escaped, "\"", "\\\"", -1)
+	return "\"" + escaped + "\""
+}
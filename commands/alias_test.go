@@ -0,0 +1,63 @@
+package commands
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestExpandAlias(t *testing.T) {
+	tests := []struct {
+		name         string
+		commandLines []string
+		parameters   []string
+		want         []string
+	}{
+		{
+			name:         "appends parameters without placeholders",
+			commandLines: []string{"status set"},
+			parameters:   []string{"online"},
+			want:         []string{"status set online"},
+		}, {
+			name:         "positional parameters",
+			commandLines: []string{"friends list | grep $1", "echo $2 $3"},
+			parameters:   []string{"online", "two"},
+			want:         []string{"friends list | grep online", "echo two "},
+		}, {
+			name:         "all parameters",
+			commandLines: []string{"grep $@"},
+			parameters:   []string{"-i", "hello world"},
+			want:         []string{`grep -i "hello world"`},
+		}, {
+			name:         "literal dollar signs",
+			commandLines: []string{"echo $$1 $x $"},
+			parameters:   []string{"a"},
+			want:         []string{"echo $1 $x $ a"},
+		}, {
+			name:         "quoting",
+			commandLines: []string{"echo $1 $2 $3"},
+			parameters:   []string{`say "hi"`, "a|b", ""},
+			want:         []string{`echo "say \"hi\"" "a|b" ""`},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ExpandAlias(tt.commandLines, tt.parameters); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ExpandAlias() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQuoteParameterRoundTrip(t *testing.T) {
+	parameters := []string{"simple", "with space", `"quoted"`, "semi;colon", "pi|pe", "re>direct", `back\slash`, `trailing\`, `escaped\"quote`, `double\\backslash`, ""}
+	for _, parameter := range parameters {
+		pipelines, err := ParseCommandLine("echo " + QuoteParameter(parameter))
+		if err != nil {
+			t.Fatalf("ParseCommandLine() error = %v for %q", err, parameter)
+		}
+		want := [][]string{{"echo", parameter}}
+		if len(pipelines) != 1 || !reflect.DeepEqual(pipelines[0].Commands, want) {
+			t.Errorf("Quoted %q was parsed as %v", parameter, pipelines[0].Commands)
+		}
+	}
+}
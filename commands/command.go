@@ -21,8 +21,19 @@ type Command interface {
 	Aliases() []string
 }
 
+// SensitiveCommand is a Command whose parameters can contain secrets, such
+// as tokens. Command lines that pass secrets to such a command aren't saved
+// in the command history.
+type SensitiveCommand interface {
+	Command
+
+	// IsSensitive checks whether the given parameters contain secrets.
+	IsSensitive(parameters []string) bool
+}
+
 // ParseCommand takes an arbitrary input string and splits it into parameters.
-// The first parameter (index 0) will always be the command itself.
+// The first parameter (index 0) will always be the command itself. Quotes
+// and backslashes can be escaped with a backslash.
 func ParseCommand(input string) []string {
 	if len(input) == 0 || len(strings.TrimSpace(input)) == 0 {
 		return nil
@@ -50,8 +61,8 @@ OUTER_LOOP:
 		} else if char == '\\' {
 			if index == length-1 {
 				lastArgument = append(lastArgument, char)
-			} else if trimmedWhiteSpace[index+1] == '"' {
-				lastArgument = append(lastArgument, '"')
+			} else if nextChar := trimmedWhiteSpace[index+1]; nextChar == '"' || nextChar == '\\' {
+				lastArgument = append(lastArgument, nextChar)
 				index++
 				continue OUTER_LOOP
 			}
@@ -59,10 +70,11 @@ OUTER_LOOP:
 			if index == 0 || trimmedWhiteSpace[index] != '\\' {
 				for index2 := index + 1; index2 < length; index2++ {
 					nextChar := trimmedWhiteSpace[index2]
-					if nextChar == '"' && trimmedWhiteSpace[index2-1] != '\\' {
-						lastArgument = trimmedWhiteSpace[index+1 : index2]
-						replacedEscapedQuotes := strings.Replace(string(lastArgument), "\\\"", "\"", -1)
-						parameters = append(parameters, replacedEscapedQuotes)
+					if nextChar == '\\' {
+						//Skips the escaped character, so that it can't end the quote.
+						index2++
+					} else if nextChar == '"' {
+						parameters = append(parameters, unescapeQuoted(trimmedWhiteSpace[index+1:index2]))
 						lastArgument = make([]rune, 0)
 						index = index2
 						continue OUTER_LOOP
@@ -83,3 +95,18 @@ OUTER_LOOP:
 
 	return parameters
 }
+
+// unescapeQuoted removes the backslashes in front of escaped quotes and
+// backslashes. Other backslashes are kept as they are.
+func unescapeQuoted(quoted []rune) string {
+	unescaped := make([]rune, 0, len(quoted))
+	for index := 0; index < len(quoted); index++ {
+		if quoted[index] == '\\' && index < len(quoted)-1 &&
+			(quoted[index+1] == '"' || quoted[index+1] == '\\') {
+			index++
+		}
+		unescaped = append(unescaped, quoted[index])
+	}
+
+	return string(unescaped)
+}
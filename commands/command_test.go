@@ -79,6 +79,22 @@ func TestParseCommand(t *testing.T) {
 			name:  "command with one simple argument and a string containg an escaped quote",
 			input: "command argument \"argument2 is \\\" long\"",
 			want:  []string{"command", "argument", "argument2 is \" long"},
+		}, {
+			name:  "quoted windows path",
+			input: `file-send "C:\Users\Marcel\My Files\cat.png"`,
+			want:  []string{"file-send", `C:\Users\Marcel\My Files\cat.png`},
+		}, {
+			name:  "quoted windows network path with escaped backslashes",
+			input: `file-send "\\\\server\\share\\cat.png"`,
+			want:  []string{"file-send", `\\server\share\cat.png`},
+		}, {
+			name:  "quoted regex",
+			input: `grep "^\d+ \w+\.$"`,
+			want:  []string{"grep", `^\d+ \w+\.$`},
+		}, {
+			name:  "quoted regex matching a backslash",
+			input: `grep "a\\\\b"`,
+			want:  []string{"grep", `a\\b`},
 		},
 	}
 
@@ -227,6 +227,26 @@ func (account *Account) addCurrentAccount(writer io.Writer, name string) {
 	account.addAcount(writer, []string{name, config.GetConfig().Token})
 }
 
+// Complete offers the subcommands and the names of all accounts.
+func (account *Account) Complete(parameters []string, index int) []string {
+	if index == 0 {
+		return []string{"add", "delete", "switch", "list", "current", "add-current", "logout"}
+	}
+
+	if index == 1 {
+		switch parameters[0] {
+		case "delete", "remove", "switch", "change":
+			names := make([]string, 0, len(config.GetConfig().Accounts))
+			for _, acc := range config.GetConfig().Accounts {
+				names = append(names, acc.Name)
+			}
+			return names
+		}
+	}
+
+	return nil
+}
+
 func (account *Account) Name() string {
 	return "account"
 }
@@ -235,7 +255,21 @@ func (account *Account) Aliases() []string {
 	return []string{"profile"}
 }
 
+// IsSensitive checks whether the parameters contain a token, which is the
+// case when adding an account.
+func (account *Account) IsSensitive(parameters []string) bool {
+	if len(parameters) == 0 {
+		return false
+	}
+
+	switch parameters[0] {
+	case "add", "create", "new":
+		return true
+	}
+	return false
+}
+
 // PrintHelp prints a static help page for this command
 func (account *Account) PrintHelp(writer io.Writer) {
-	fmt.Fprintln(writer, accountDocumentation)
+	fmt.Fprint(writer, accountDocumentation)
 }
@@ -0,0 +1,178 @@
+package commandimpls
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/Bios-Marcel/cordless/config"
+	"github.com/Bios-Marcel/tview"
+)
+
+const aliasHelpPage = `[::b]NAME
+	alias - define shorthands for commands
+
+[::b]SYNPOSIS
+	[::b]alias[::-] [list[]
+	[::b]alias set[::-] <name> <command>...
+	[::b]alias remove[::-] <name>
+
+[::b]DESCRIPTION
+	This command allows you to define your own commands. An alias consists
+	of a name and one or more commands. If there's more than one command,
+	the alias is a macro and all of its commands are executed one after
+	another when it is called. Aliases take precedence over all other
+	commands, but an alias is ignored while it is being executed. That way
+	an alias can be used to change the behaviour of an existing command.
+
+	The parameters passed to an alias can be used in its commands. "$1",
+	"$2" and so on are replaced with the parameter at the given position,
+	"$@" is replaced with all parameters and "$$" is replaced with "$". If
+	none of the commands use any of the parameters, the parameters are
+	appended to the last command.
+
+	Aliases are saved in the file "aliases.json" next to the configuration
+	file.
+
+[::b]SUBCOMMANDS
+	[::b]list (default)
+		lists all aliases and the commands they run
+	[::b]set <name> <command>...
+		creates or replaces an alias, each command has to be quoted
+	[::b]remove <name>
+		deletes the alias with the given name
+
+[::b]EXAMPLES
+	[gray]$ alias set online "friends list | grep -i $1"
+	[gray]$ online marcel
+
+	[gray]$ alias set morning "status set online" "friends list"
+	[gray]$ morning
+
+	[gray]$ alias remove morning`
+
+// AliasCmd manages the user-defined aliases.
+type AliasCmd struct{}
+
+// NewAliasCommand creates a ready-to-use alias command.
+func NewAliasCommand() *AliasCmd {
+	return &AliasCmd{}
+}
+
+// Execute runs the command piping its output into the supplied writer.
+func (cmd *AliasCmd) Execute(writer io.Writer, parameters []string) {
+	if len(parameters) == 0 {
+		cmd.printAliases(writer)
+		return
+	}
+
+	switch parameters[0] {
+	case "list", "ls":
+		cmd.printAliases(writer)
+	case "set", "add":
+		if len(parameters) < 3 {
+			fmt.Fprintln(writer, "[red]Usage: alias set <name> <command>...")
+			return
+		}
+		cmd.setAlias(writer, parameters[1], parameters[2:])
+	case "remove", "rm", "delete":
+		if len(parameters) != 2 {
+			fmt.Fprintln(writer, "[red]Usage: alias remove <name>")
+			return
+		}
+		cmd.removeAlias(writer, parameters[1])
+	default:
+		fmt.Fprintf(writer, "[red]The subcommand '%s' does not exist\n", parameters[0])
+		cmd.PrintHelp(writer)
+	}
+}
+
+func (cmd *AliasCmd) printAliases(writer io.Writer) {
+	aliases := config.GetAliases()
+	if len(aliases) == 0 {
+		fmt.Fprintln(writer, "There are no aliases yet.")
+		return
+	}
+
+	names := make([]string, 0, len(aliases))
+	for name := range aliases {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		fmt.Fprintf(writer, "[::b]%s[::-]\n", name)
+		for _, commandLine := range aliases[name] {
+			fmt.Fprintf(writer, "\t%s\n", tview.Escape(commandLine))
+		}
+	}
+}
+
+func (cmd *AliasCmd) setAlias(writer io.Writer, name string, commandLines []string) {
+	//The alias command itself mustn't be shadowed, as it couldn't be used
+	//to remove the alias anymore.
+	if name == "" || name == cmd.Name() || name == "aliases" || strings.ContainsAny(name, " \t\"\\;|>$") {
+		fmt.Fprintf(writer, "[red]'%s' can't be used as the name of an alias.\n", name)
+		return
+	}
+
+	config.GetAliases()[name] = commandLines
+	persistError := config.PersistAliases()
+	if persistError != nil {
+		fmt.Fprintf(writer, "[red]Error saving aliases:\n\t[red]%s\n", persistError)
+		return
+	}
+
+	fmt.Fprintf(writer, "The alias '%s' has been saved.\n", name)
+}
+
+func (cmd *AliasCmd) removeAlias(writer io.Writer, name string) {
+	aliases := config.GetAliases()
+	if _, exists := aliases[name]; !exists {
+		fmt.Fprintf(writer, "[red]The alias '%s' couldn't be found.\n", name)
+		return
+	}
+
+	delete(aliases, name)
+	persistError := config.PersistAliases()
+	if persistError != nil {
+		fmt.Fprintf(writer, "[red]Error saving aliases:\n\t[red]%s\n", persistError)
+		return
+	}
+
+	fmt.Fprintf(writer, "The alias '%s' has been removed.\n", name)
+}
+
+// Complete offers the subcommands and the names of all aliases.
+func (cmd *AliasCmd) Complete(parameters []string, index int) []string {
+	if index == 0 {
+		return []string{"list", "set", "remove"}
+	}
+
+	if index == 1 && (parameters[0] == "remove" || parameters[0] == "rm" || parameters[0] == "delete") {
+		names := make([]string, 0, len(config.GetAliases()))
+		for name := range config.GetAliases() {
+			names = append(names, name)
+		}
+		return names
+	}
+
+	return nil
+}
+
+// Name returns the primary name for this command. This name will also be
+// used for listing the command in the commandlist.
+func (cmd *AliasCmd) Name() string {
+	return "alias"
+}
+
+// Aliases are a list of aliases for this command. There might be none.
+func (cmd *AliasCmd) Aliases() []string {
+	return []string{"aliases"}
+}
+
+// PrintHelp prints a static help page for this command
+func (cmd *AliasCmd) PrintHelp(writer io.Writer) {
+	fmt.Fprintln(writer, aliasHelpPage)
+}
@@ -0,0 +1,460 @@
+package commandimpls
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html/template"
+	"io"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Bios-Marcel/cordless/commands"
+	"github.com/Bios-Marcel/cordless/discordutil"
+	"github.com/Bios-Marcel/cordless/ui"
+	"github.com/Bios-Marcel/cordless/ui/tviewutil"
+	"github.com/Bios-Marcel/discordgo"
+	"github.com/Bios-Marcel/tview"
+)
+
+const exportHelpPage = `[::b]NAME
+	export - saves the history of a channel into a file
+
+[::b]SYNPOSIS
+	[::b]export[::-] [OPTION[]... <file>
+
+[::b]DESCRIPTION
+	This command requests the complete history of a channel from Discord
+	and saves it as plain text, JSON or HTML. Mentions are replaced with
+	names and attachments are saved as links. The export runs in the
+	background and reports its progress in the command output.
+
+	Downloaded messages are collected in a file with the same name, but
+	ending with ".partial". If the export gets interrupted, for example by
+	closing cordless, running the same command again continues where the
+	export stopped. The partial file is removed as soon as the export has
+	been written.
+
+%s
+
+[::b]EXAMPLES
+	[gray]$ export ~/general.txt
+	[gray]$ export --channel "#announcements" ~/announcements.html
+	[gray]$ export -c Marcel -f json ~/marcel.log`
+
+var exportFlags = &commands.FlagSet{
+	Flags: []*commands.Flag{
+		{
+			Short:       "c",
+			Long:        "channel",
+			Type:        commands.StringFlag,
+			ValueName:   "channel",
+			Description: "the channel to export, either '#name', a DM partner or an ID; defaults to the loaded channel",
+		}, {
+			Short:       "f",
+			Long:        "format",
+			Type:        commands.StringFlag,
+			ValueName:   "format",
+			Description: "either text, json or html; defaults to the extension of the file or text",
+		},
+	},
+}
+
+const exportTimeFormat = "2006-01-02 15:04:05"
+
+var exportHTMLTemplate = template.Must(template.New("export").
+	Funcs(template.FuncMap{"formatTime": formatExportTime}).
+	Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Channel}}</title>
+<style>
+body { font-family: sans-serif; background-color: #36393f; color: #dcddde; }
+a { color: #00b0f4; }
+.message { margin: 0.6em 0; }
+.time, .edited { color: #72767d; font-size: 0.8em; }
+.author { color: #ffffff; font-weight: bold; }
+.content { white-space: pre-wrap; }
+</style>
+</head>
+<body>
+<h1>{{.Channel}}</h1>
+{{range .Messages}}<div class="message" id="{{.ID}}">
+<span class="time">{{formatTime .Timestamp}}</span> <span class="author">{{.Author}}</span>{{if .EditedTimestamp}} <span class="edited">(edited {{formatTime .EditedTimestamp}})</span>{{end}}
+<div class="content">{{.Content}}</div>{{range .Attachments}}
+<div class="attachment"><a href="{{.URL}}">{{.Filename}}</a></div>{{end}}
+</div>
+{{end}}</body>
+</html>
+`))
+
+// ExportCmd saves the history of a channel into a file.
+type ExportCmd struct {
+	window  *ui.Window
+	session *discordgo.Session
+
+	// running contains the paths of all exports that are in progress.
+	running map[string]bool
+	mutex   *sync.Mutex
+}
+
+// exportedMessage is the representation of a message in an export.
+type exportedMessage struct {
+	ID              string               `json:"id"`
+	Timestamp       string               `json:"timestamp"`
+	EditedTimestamp string               `json:"edited_timestamp,omitempty"`
+	AuthorID        string               `json:"author_id"`
+	Author          string               `json:"author"`
+	Content         string               `json:"content"`
+	Attachments     []exportedAttachment `json:"attachments,omitempty"`
+}
+
+type exportedAttachment struct {
+	Filename string `json:"filename"`
+	URL      string `json:"url"`
+}
+
+// exportHeader is the first line of a partial export, making sure that an
+// export is only resumed for the same channel.
+type exportHeader struct {
+	ChannelID string `json:"channel_id"`
+}
+
+// NewExportCommand creates a ready-to-use export command.
+func NewExportCommand(window *ui.Window, session *discordgo.Session) *ExportCmd {
+	return &ExportCmd{
+		window:  window,
+		session: session,
+		running: make(map[string]bool),
+		mutex:   &sync.Mutex{},
+	}
+}
+
+// Execute runs the command piping its output into the supplied writer.
+func (cmd *ExportCmd) Execute(writer io.Writer, parameters []string) {
+	flags, parseError := exportFlags.Parse(parameters)
+	if parseError == nil && len(flags.Arguments) != 1 {
+		parseError = errors.New("exactly one file is required")
+	}
+	if parseError != nil {
+		fmt.Fprintf(writer, "[red]Error parsing parameters:\n\t[red]%s\n", parseError)
+		return
+	}
+
+	var channel *discordgo.Channel
+	if flags.IsSet("channel") {
+		var guildID string
+		if selectedGuild := cmd.window.GetSelectedGuild(); selectedGuild != nil {
+			guildID = selectedGuild.ID
+		}
+		channel = discordutil.FindChannel(cmd.session.State, guildID, flags.String("channel"))
+		if channel == nil && guildID != "" {
+			channel = discordutil.FindChannel(cmd.session.State, "", flags.String("channel"))
+		}
+		if channel == nil {
+			fmt.Fprintf(writer, "[red]The channel '%s' couldn't be found.\n", tview.Escape(flags.String("channel")))
+			return
+		}
+	} else {
+		channel = cmd.window.GetSelectedChannel()
+		if channel == nil {
+			fmt.Fprintln(writer, "[red]No channel is loaded, use --channel in order to choose one.")
+			return
+		}
+	}
+
+	path := flags.Arguments[0]
+	if strings.HasPrefix(path, "~") {
+		currentUser, userResolveError := user.Current()
+		if userResolveError != nil {
+			fmt.Fprintf(writer, "[red]Error resolving path:\n\t[red]%s\n", userResolveError)
+			return
+		}
+
+		path = filepath.Join(currentUser.HomeDir, strings.TrimPrefix(path, "~"))
+	}
+
+	format := strings.ToLower(flags.String("format"))
+	if format == "" {
+		switch strings.ToLower(filepath.Ext(path)) {
+		case ".json":
+			format = "json"
+		case ".html", ".htm":
+			format = "html"
+		default:
+			format = "text"
+		}
+	}
+	if format != "text" && format != "json" && format != "html" {
+		fmt.Fprintf(writer, "[red]Unknown format '%s', use text, json or html.\n", tview.Escape(format))
+		return
+	}
+
+	cmd.mutex.Lock()
+	if cmd.running[path] {
+		cmd.mutex.Unlock()
+		fmt.Fprintf(writer, "[red]There's already an export into '%s' in progress.\n", tview.Escape(path))
+		return
+	}
+	cmd.running[path] = true
+	cmd.mutex.Unlock()
+
+	// The download keeps running after Execute has returned, therefore its
+	// output goes directly into the command view.
+	go func() {
+		cmd.export(cmd.window.GetBackgroundOutput(), channel, format, path)
+
+		cmd.mutex.Lock()
+		delete(cmd.running, path)
+		cmd.mutex.Unlock()
+	}()
+}
+
+// export downloads all messages of the channel that haven't been downloaded
+// yet and writes the export once all messages are present.
+func (cmd *ExportCmd) export(writer io.Writer, channel *discordgo.Channel, format, path string) {
+	channelName := cmd.channelName(channel)
+	partialPath := path + ".partial"
+	messages, readError := readPartialExport(partialPath, channel.ID)
+	if readError != nil {
+		fmt.Fprintf(writer, "[red]Error reading partial export '%s':\n\t[red]%s\n", tview.Escape(partialPath), readError)
+		return
+	}
+
+	partialFile, openError := os.OpenFile(partialPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if openError != nil {
+		fmt.Fprintf(writer, "[red]Error creating partial export:\n\t[red]%s\n", openError)
+		return
+	}
+	encoder := json.NewEncoder(partialFile)
+
+	if messages == nil {
+		fmt.Fprintf(writer, "Exporting %s, this might take a while.\n", tview.Escape(channelName))
+		if encodeError := encoder.Encode(&exportHeader{ChannelID: channel.ID}); encodeError != nil {
+			partialFile.Close()
+			fmt.Fprintf(writer, "[red]Error writing partial export:\n\t[red]%s\n", encodeError)
+			return
+		}
+	} else {
+		fmt.Fprintf(writer, "Resuming export of %s, %d messages had already been downloaded.\n",
+			tview.Escape(channelName), len(messages))
+	}
+
+	before := oldestMessageID(messages)
+	for page := 1; ; page++ {
+		pageMessages, discordError := cmd.session.ChannelMessages(channel.ID, 100, before, "", "")
+		if discordError != nil {
+			partialFile.Close()
+			fmt.Fprintf(writer, "[red]Error downloading messages, run the command again in order to resume:\n\t[red]%s\n", discordError)
+			return
+		}
+
+		for _, message := range pageMessages {
+			message.GuildID = channel.GuildID
+			if encodeError := encoder.Encode(message); encodeError != nil {
+				partialFile.Close()
+				fmt.Fprintf(writer, "[red]Error writing partial export:\n\t[red]%s\n", encodeError)
+				return
+			}
+		}
+		messages = append(messages, pageMessages...)
+
+		if len(pageMessages) < 100 {
+			break
+		}
+		// Discord returns the newest messages first.
+		before = pageMessages[len(pageMessages)-1].ID
+
+		if page%10 == 0 {
+			fmt.Fprintf(writer, "[gray]Downloaded %d messages of %s.\n", len(messages), tview.Escape(channelName))
+		}
+	}
+
+	if closeError := partialFile.Close(); closeError != nil {
+		fmt.Fprintf(writer, "[red]Error writing partial export:\n\t[red]%s\n", closeError)
+		return
+	}
+
+	discordutil.SortMessagesByTimestamp(messages)
+	if writeError := cmd.writeExport(channelName, messages, format, path); writeError != nil {
+		fmt.Fprintf(writer, "[red]Error writing export:\n\t[red]%s\n", writeError)
+		return
+	}
+
+	if removeError := os.Remove(partialPath); removeError != nil {
+		fmt.Fprintf(writer, "[red]Error removing partial export:\n\t[red]%s\n", removeError)
+	}
+	fmt.Fprintf(writer, "Exported %d messages of %s into '%s'.\n", len(messages), tview.Escape(channelName), tview.Escape(path))
+}
+
+// readPartialExport reads all messages of a previously interrupted export.
+// If there's no partial export, nil is returned.
+func readPartialExport(path, channelID string) ([]*discordgo.Message, error) {
+	file, openError := os.Open(path)
+	if os.IsNotExist(openError) {
+		return nil, nil
+	}
+	if openError != nil {
+		return nil, openError
+	}
+	defer file.Close()
+
+	messages := make([]*discordgo.Message, 0)
+	scanner := bufio.NewScanner(file)
+	// A single message can be a lot larger than the default maximum.
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	if !scanner.Scan() {
+		return nil, scanner.Err()
+	}
+
+	var header exportHeader
+	if unmarshalError := json.Unmarshal(scanner.Bytes(), &header); unmarshalError != nil {
+		return nil, unmarshalError
+	}
+	if header.ChannelID != channelID {
+		return nil, errors.New("the file belongs to the export of a different channel, delete it or choose a different file")
+	}
+
+	validLength := int64(len(scanner.Bytes()) + 1)
+	for scanner.Scan() {
+		var message *discordgo.Message
+		if unmarshalError := json.Unmarshal(scanner.Bytes(), &message); unmarshalError != nil {
+			// The last line might be incomplete if cordless was closed
+			// while writing it. It is cut off, so that new messages can
+			// be appended and the message will be downloaded again.
+			return messages, os.Truncate(path, validLength)
+		}
+		messages = append(messages, message)
+		validLength += int64(len(scanner.Bytes()) + 1)
+	}
+
+	return messages, scanner.Err()
+}
+
+func (cmd *ExportCmd) writeExport(channelName string, messages []*discordgo.Message, format, path string) error {
+	exportedMessages := make([]*exportedMessage, 0, len(messages))
+	for _, message := range messages {
+		exportedMessages = append(exportedMessages, cmd.convertMessage(message))
+	}
+
+	file, createError := os.Create(path)
+	if createError != nil {
+		return createError
+	}
+
+	var writeError error
+	switch format {
+	case "json":
+		encoder := json.NewEncoder(file)
+		encoder.SetIndent("", "    ")
+		writeError = encoder.Encode(exportedMessages)
+	case "html":
+		writeError = exportHTMLTemplate.Execute(file, struct {
+			Channel  string
+			Messages []*exportedMessage
+		}{channelName, exportedMessages})
+	default:
+		writeError = writeTextExport(file, exportedMessages)
+	}
+
+	closeError := file.Close()
+	if writeError != nil {
+		return writeError
+	}
+	return closeError
+}
+
+func writeTextExport(writer io.Writer, messages []*exportedMessage) error {
+	buffer := bufio.NewWriter(writer)
+	for _, message := range messages {
+		fmt.Fprintf(buffer, "[%s] %s", formatExportTime(message.Timestamp), message.Author)
+		if message.EditedTimestamp != "" {
+			fmt.Fprintf(buffer, " (edited %s)", formatExportTime(message.EditedTimestamp))
+		}
+		fmt.Fprintf(buffer, ": %s\n", strings.Replace(message.Content, "\n", "\n    ", -1))
+		for _, attachment := range message.Attachments {
+			fmt.Fprintf(buffer, "    Attachment: %s\n", attachment.URL)
+		}
+	}
+
+	return buffer.Flush()
+}
+
+func (cmd *ExportCmd) convertMessage(message *discordgo.Message) *exportedMessage {
+	exported := &exportedMessage{
+		ID:              message.ID,
+		Timestamp:       string(message.Timestamp),
+		EditedTimestamp: string(message.EditedTimestamp),
+		Content: discordutil.ResolveMentions(cmd.session.State, message, message.Content, func(name string, mentionsSelf bool) string {
+			return name
+		}),
+	}
+
+	if message.Author != nil {
+		exported.AuthorID = message.Author.ID
+		exported.Author = discordutil.GetDisplayName(cmd.session.State, message.GuildID, message.Author)
+	}
+
+	for _, attachment := range message.Attachments {
+		exported.Attachments = append(exported.Attachments, exportedAttachment{
+			Filename: attachment.Filename,
+			URL:      attachment.URL,
+		})
+	}
+
+	return exported
+}
+
+// channelName returns the unescaped name of the channel, including the
+// guild name for guild channels.
+func (cmd *ExportCmd) channelName(channel *discordgo.Channel) string {
+	if channel.GuildID == "" {
+		return tviewutil.StripTags(discordutil.GetPrivateChannelName(channel))
+	}
+
+	guild, cacheError := cmd.session.State.Guild(channel.GuildID)
+	if cacheError != nil {
+		return "#" + channel.Name
+	}
+	return "#" + channel.Name + " (" + guild.Name + ")"
+}
+
+func formatExportTime(timestamp string) string {
+	parsed, parseError := time.Parse(time.RFC3339, timestamp)
+	if parseError != nil {
+		return timestamp
+	}
+
+	return parsed.Local().Format(exportTimeFormat)
+}
+
+// Complete offers the formats and file paths.
+func (cmd *ExportCmd) Complete(parameters []string, index int) []string {
+	if index > 0 && (parameters[index-1] == "-f" || parameters[index-1] == "--format") {
+		return []string{"text", "json", "html"}
+	}
+
+	return commands.CompleteFilePath(parameters[index])
+}
+
+// Name returns the primary name for this command. This name will also be
+// used for listing the command in the commandlist.
+func (cmd *ExportCmd) Name() string {
+	return "export"
+}
+
+// Aliases are a list of aliases for this command. There might be none.
+func (cmd *ExportCmd) Aliases() []string {
+	return nil
+}
+
+// PrintHelp prints a static help page for this command
+func (cmd *ExportCmd) PrintHelp(writer io.Writer) {
+	fmt.Fprintf(writer, exportHelpPage+"\n", exportFlags.OptionsHelp())
+}
@@ -10,6 +10,7 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/Bios-Marcel/cordless/commands"
 	"github.com/Bios-Marcel/cordless/ui"
 	"github.com/Bios-Marcel/discordgo"
 )
@@ -21,10 +22,16 @@ The file-send command allows you to send multiple files to your current channel.
 Usage:
 	file-send <FILE_PATH>...
 
+Paths containing backslashes, such as Windows paths, have to be quoted.
+Inside quotes, a backslash in front of another backslash or a quote
+escapes it, so two consecutive backslashes have to be written as four.
+
 Examples:
 	file-send ~/file.txt
 	file-send ~/file1.txt ~/file2.txt
 	file-send "~/file one.txt" ~/file2.txt
+	file-send "C:\Users\Marcel\file.txt"
+	file-send "\\\\server\share\file.txt"
 `
 
 // FileSend represents the command used to send multiple files to a channel.
@@ -88,6 +95,11 @@ func (cmd *FileSend) Execute(writer io.Writer, parameters []string) {
 	}
 }
 
+// Complete offers the paths of local files for all parameters.
+func (cmd *FileSend) Complete(parameters []string, index int) []string {
+	return commands.CompleteFilePath(parameters[index])
+}
+
 func (cmd *FileSend) Name() string {
 	return "file-send"
 }
@@ -110,5 +110,5 @@ func (fixLayout *FixLayout) Aliases() []string {
 
 // PrintHelp prints a static help page for this command
 func (fixLayout *FixLayout) PrintHelp(writer io.Writer) {
-	fmt.Fprintln(writer, fixLayoutDocumentation)
+	fmt.Fprint(writer, fixLayoutDocumentation)
 }
@@ -6,6 +6,7 @@ import (
 	"strings"
 	"unicode"
 
+	"github.com/Bios-Marcel/cordless/commands"
 	"github.com/Bios-Marcel/discordgo"
 )
 
@@ -23,11 +24,30 @@ The friend currently command offers the following subcommands:
   * list     - shows all friends
   * remove   - removes a friend from your friendslist
 
+The requests subcommand shows both the incomming and the outgoing requests,
+unless one of the following options is used:
+
+%s
+
 The following features are currently unsupported:
   * Blocking users
   * Unblocking users
 `
 
+var friendsFlags = &commands.FlagSet{
+	Flags: []*commands.Flag{
+		{
+			Short:       "i",
+			Long:        "incoming",
+			Description: "only shows the incoming requests",
+		}, {
+			Short:       "o",
+			Long:        "outgoing",
+			Description: "only shows the outgoing requests",
+		},
+	},
+}
+
 // Friends is the command for managing discord friends.
 type Friends struct {
 	session *discordgo.Session
@@ -42,12 +62,19 @@ func NewFriendsCommand(session *discordgo.Session) *Friends {
 
 // Execute handles all input for the friends command.
 func (f *Friends) Execute(writer io.Writer, parameters []string) {
-	if len(parameters) == 0 {
+	flags, parseError := friendsFlags.Parse(parameters)
+	if parseError != nil {
+		fmt.Fprintf(writer, "[red]Error parsing parameters:\n\t[red]%s\n", parseError)
+		return
+	}
+
+	arguments := flags.Arguments
+	if len(arguments) == 0 {
 		f.PrintHelp(writer)
 		return
 	}
 
-	switch parameters[0] {
+	switch arguments[0] {
 	case "list", "show", "which":
 		fmt.Fprintln(writer, "Friends:")
 		for _, rel := range f.session.State.Relationships {
@@ -56,12 +83,12 @@ func (f *Friends) Execute(writer io.Writer, parameters []string) {
 			}
 		}
 	case "delete", "unfriend", "remove", "decline":
-		if len(parameters) != 2 {
+		if len(arguments) != 2 {
 			fmt.Fprintln(writer, "Usage: friends remove <Username|Username#NNNN|UserID>")
 			return
 		}
 
-		input := parameters[1]
+		input := arguments[1]
 		var matches []*discordgo.Relationship
 		for _, rel := range f.session.State.Relationships {
 			if rel.Type == discordgo.RelationTypeFriend ||
@@ -101,26 +128,33 @@ func (f *Friends) Execute(writer io.Writer, parameters []string) {
 			}
 		}
 
-		fmt.Fprintln(writer, "Incomming requests:")
-		if incomming != "" {
-			fmt.Fprintln(writer, incomming)
-		} else {
-			fmt.Fprintln(writer, "No incomming requests.")
+		//Without a filter, both kinds of requests are shown.
+		showAll := !flags.Bool("incoming") && !flags.Bool("outgoing")
+
+		if showAll || flags.Bool("incoming") {
+			fmt.Fprintln(writer, "Incomming requests:")
+			if incomming != "" {
+				fmt.Fprintln(writer, incomming)
+			} else {
+				fmt.Fprintln(writer, "No incomming requests.")
+			}
 		}
 
-		fmt.Fprintln(writer, "Outgoing requests:")
-		if outgoing != "" {
-			fmt.Fprintln(writer, outgoing)
-		} else {
-			fmt.Fprintln(writer, "No outgoing requests.")
+		if showAll || flags.Bool("outgoing") {
+			fmt.Fprintln(writer, "Outgoing requests:")
+			if outgoing != "" {
+				fmt.Fprintln(writer, outgoing)
+			} else {
+				fmt.Fprintln(writer, "No outgoing requests.")
+			}
 		}
 	case "accept", "agree":
-		if len(parameters) != 2 {
+		if len(arguments) != 2 {
 			fmt.Fprintln(writer, "Usage: friends accept <Username|Username#NNNN|UserID")
 			return
 		}
 
-		input := parameters[1]
+		input := arguments[1]
 		var matches []*discordgo.Relationship
 		for _, rel := range f.session.State.Relationships {
 			if rel.Type == discordgo.RelationTypeIncommingRequest {
@@ -147,12 +181,12 @@ func (f *Friends) Execute(writer io.Writer, parameters []string) {
 			}
 		}
 	case "search", "find":
-		if len(parameters) != 2 {
+		if len(arguments) != 2 {
 			fmt.Fprintln(writer, "Usage: friends find <Username|Username#NNNN|UserID")
 			return
 		}
 
-		input := parameters[1]
+		input := arguments[1]
 
 		var matches []*discordgo.Relationship
 		for _, rel := range f.session.State.Relationships {
@@ -175,7 +209,7 @@ func (f *Friends) Execute(writer io.Writer, parameters []string) {
 		}
 
 	case "befriend", "add", "send", "ask", "invite", "request":
-		if len(parameters) != 2 {
+		if len(arguments) != 2 {
 			fmt.Fprintln(writer, "Usage: friends befriend <Username|Username#NNNN|UserID")
 			return
 		}
@@ -183,7 +217,7 @@ func (f *Friends) Execute(writer io.Writer, parameters []string) {
 		//Iterate over all available users and find one that fits, if we were
 		//successful, we send a friendsrequest. Otherwise we check if the input
 		//might have been a user idea, lookup the user and do a request.
-		input := parameters[1]
+		input := arguments[1]
 		users, err := f.session.State.Users()
 		var matches []*discordgo.User
 
@@ -233,6 +267,44 @@ func (f *Friends) Execute(writer io.Writer, parameters []string) {
 	}
 }
 
+// Complete offers the subcommands and the names of the users that the
+// subcommand can be applied to.
+func (f *Friends) Complete(parameters []string, index int) []string {
+	if index == 0 {
+		return []string{"accept", "befriend", "requests", "search", "list", "remove"}
+	}
+
+	if index != 1 {
+		return nil
+	}
+
+	var names []string
+	for _, rel := range f.session.State.Relationships {
+		switch parameters[0] {
+		case "delete", "unfriend", "remove", "decline":
+			if rel.Type != discordgo.RelationTypeFriend &&
+				rel.Type != discordgo.RelationTypeOutgoingRequest &&
+				rel.Type != discordgo.RelationTypeIncommingRequest {
+				continue
+			}
+		case "accept", "agree":
+			if rel.Type != discordgo.RelationTypeIncommingRequest {
+				continue
+			}
+		case "search", "find":
+			if rel.Type != discordgo.RelationTypeFriend {
+				continue
+			}
+		default:
+			return nil
+		}
+
+		names = append(names, rel.User.Username)
+	}
+
+	return names
+}
+
 // Name returns the name of the command.
 func (f *Friends) Name() string {
 	return "friends"
@@ -244,5 +316,5 @@ func (f *Friends) Aliases() []string {
 
 // PrintHelp prints the general help page for the friends commands.
 func (f *Friends) PrintHelp(writer io.Writer) {
-	fmt.Fprintln(writer, friendsDocumentation)
+	fmt.Fprintf(writer, friendsDocumentation, friendsFlags.OptionsHelp())
 }
@@ -0,0 +1,116 @@
+package commandimpls
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+
+	"github.com/Bios-Marcel/cordless/commands"
+	"github.com/Bios-Marcel/cordless/ui/tviewutil"
+)
+
+const grepHelpPage = `[::b]NAME
+	grep - filters the output of another command
+
+[::b]SYNPOSIS
+	[::b]COMMAND | grep[::-] [OPTION[]... <pattern>
+
+[::b]DESCRIPTION
+	This command prints all lines of its input that match the given
+	regular expression. The input is the output of the command in front
+	of the pipe. Colors are ignored when matching, but kept in the output.
+
+	Patterns containing spaces or backslashes have to be quoted. Inside
+	quotes, a backslash in front of another backslash or a quote escapes
+	it, so matching a literal backslash requires four of them.
+
+%s
+
+[::b]EXAMPLES
+	[gray]$ friends list | grep -i marcel
+	[gray]$ help | grep "\d+ \w+"
+	[gray]$ history | grep "C:\\\\Users"
+	[gray]$ scripts | grep -v active > ~/inactive-scripts.txt`
+
+var grepFlags = &commands.FlagSet{
+	Flags: []*commands.Flag{
+		{
+			Short:       "i",
+			Long:        "ignore-case",
+			Description: "ignores the case of letters when matching",
+		}, {
+			Short:       "v",
+			Long:        "invert-match",
+			Description: "prints the lines that don't match instead",
+		},
+	},
+}
+
+// GrepCmd filters the output of a previous command in a pipeline.
+type GrepCmd struct{}
+
+var _ commands.InputCommand = &GrepCmd{}
+
+// NewGrepCommand creates a ready-to-use grep command.
+func NewGrepCommand() *GrepCmd {
+	return &GrepCmd{}
+}
+
+// Execute prints an error, since grep can only be used in a pipeline.
+func (cmd *GrepCmd) Execute(writer io.Writer, parameters []string) {
+	fmt.Fprintln(writer, "[red]grep requires input, use it behind a '|', for example: friends list | grep online")
+}
+
+// ExecuteWithInput prints all lines of the input that match the pattern.
+func (cmd *GrepCmd) ExecuteWithInput(writer io.Writer, input io.Reader, parameters []string) {
+	flags, parseError := grepFlags.Parse(parameters)
+	if parseError == nil && len(flags.Arguments) != 1 {
+		parseError = errors.New("exactly one pattern is required")
+	}
+	if parseError != nil {
+		fmt.Fprintf(writer, "[red]Error parsing parameters:\n\t[red]%s\n", parseError)
+		return
+	}
+
+	pattern := flags.Arguments[0]
+	if flags.Bool("ignore-case") {
+		pattern = "(?i)" + pattern
+	}
+	invert := flags.Bool("invert-match")
+
+	regex, compileError := regexp.Compile(pattern)
+	if compileError != nil {
+		fmt.Fprintf(writer, "[red]Error parsing pattern:\n\t[red]%s\n", compileError)
+		return
+	}
+
+	scanner := bufio.NewScanner(input)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if regex.MatchString(tviewutil.StripTags(line)) != invert {
+			fmt.Fprintln(writer, line)
+		}
+	}
+
+	if scanError := scanner.Err(); scanError != nil {
+		fmt.Fprintf(writer, "[red]Error reading input:\n\t[red]%s\n", scanError)
+	}
+}
+
+// Name returns the primary name for this command. This name will also be
+// used for listing the command in the commandlist.
+func (cmd *GrepCmd) Name() string {
+	return "grep"
+}
+
+// Aliases are a list of aliases for this command. There might be none.
+func (cmd *GrepCmd) Aliases() []string {
+	return []string{}
+}
+
+// PrintHelp prints a static help page for this command
+func (cmd *GrepCmd) PrintHelp(writer io.Writer) {
+	fmt.Fprintf(writer, grepHelpPage+"\n", grepFlags.OptionsHelp())
+}
@@ -0,0 +1,108 @@
+package commandimpls
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/Bios-Marcel/cordless/commands"
+	"github.com/Bios-Marcel/tview"
+)
+
+const historyHelpPage = `[::b]NAME
+	history - list or clear the command history
+
+[::b]SYNPOSIS
+	[::b]history[::-] [list[] [N[]
+	[::b]history clear[::-]
+
+[::b]DESCRIPTION
+	This command prints the commands that you have executed, the most
+	recent one being the last. The history is saved between sessions, its
+	size can be changed via the CommandHistorySize setting.
+
+[::b]SUBCOMMANDS
+	[::b]list [N[] (default)
+		prints the last N commands or all of them if N isn't given
+	[::b]clear
+		removes all commands from the history
+
+[::b]EXAMPLES
+	[gray]$ history 10
+	[gray]$ history | grep friends`
+
+// HistoryCmd allows inspecting and clearing the command history.
+type HistoryCmd struct {
+	history *commands.History
+}
+
+// NewHistoryCommand creates a ready-to-use history command.
+func NewHistoryCommand(history *commands.History) *HistoryCmd {
+	return &HistoryCmd{history: history}
+}
+
+// Execute runs the command piping its output into the supplied writer.
+func (cmd *HistoryCmd) Execute(writer io.Writer, parameters []string) {
+	if len(parameters) > 0 && parameters[0] == "list" {
+		parameters = parameters[1:]
+	}
+
+	if len(parameters) == 0 {
+		cmd.printHistory(writer, cmd.history.Len())
+		return
+	}
+
+	if parameters[0] == "clear" {
+		clearError := cmd.history.Clear()
+		if clearError != nil {
+			fmt.Fprintf(writer, "[red]Error clearing command history:\n\t[red]%s\n", clearError)
+			return
+		}
+		fmt.Fprintln(writer, "The command history has been cleared.")
+		return
+	}
+
+	count, parseError := strconv.Atoi(parameters[0])
+	if parseError != nil || count < 0 || len(parameters) > 1 {
+		fmt.Fprintln(writer, "[red]Usage: history [list[] [N[] | history clear")
+		return
+	}
+	cmd.printHistory(writer, count)
+}
+
+func (cmd *HistoryCmd) printHistory(writer io.Writer, count int) {
+	history := cmd.history
+	start := history.Len() - count
+	if start < 0 {
+		start = 0
+	}
+
+	for index := start; index < history.Len(); index++ {
+		fmt.Fprintf(writer, "%5d  %s\n", index+1, tview.Escape(history.Get(index)))
+	}
+}
+
+// Complete offers the subcommands.
+func (cmd *HistoryCmd) Complete(parameters []string, index int) []string {
+	if index == 0 {
+		return []string{"list", "clear"}
+	}
+
+	return nil
+}
+
+// Name returns the primary name for this command. This name will also be
+// used for listing the command in the commandlist.
+func (cmd *HistoryCmd) Name() string {
+	return "history"
+}
+
+// Aliases are a list of aliases for this command. There might be none.
+func (cmd *HistoryCmd) Aliases() []string {
+	return nil
+}
+
+// PrintHelp prints a static help page for this command
+func (cmd *HistoryCmd) PrintHelp(writer io.Writer) {
+	fmt.Fprintln(writer, historyHelpPage)
+}
@@ -140,17 +140,45 @@ const commandsDocumentation = `[::b]TOPIC
 	front of it.
 
 	After typing a command, it will be added to your history. The history
-	doesn't persist between cordless sessions, it will be forgotten every
-	time you close the application. The history can be travelled through
-	by using the arrow up and down keys. An exception for historization
+	is saved between cordless sessions, its size can be configured via the
+	CommandHistorySize setting. Commands that are typed again are moved to
+	the end of the history instead of being added twice. The history can be
+	travelled through by using the arrow up and down keys. Ctrl+R starts a
+	reverse search through the history, each typed character narrows down
+	the search and pressing Ctrl+R again jumps to the next older match.
+	Escape cancels the search. The [::b]history[::-] command allows listing and
+	clearing the history. An exception for historization
 	are secret inputs like passwords, those aren't directly typed into the
 	command-input. Instead cordless shows an extra dialog as soon as it
 	requires you to input sensitive information like passwords.
 
+	Pressing Tab completes the last word of the input. Command names,
+	subcommands and many parameters, like account names, usernames or file
+	paths, can be completed. If there are multiple candidates, all of them
+	are printed into the command output.
+
 	Since the command-input component uses the same underlying component
 	as the message-input, you can use the same shortcuts for editing
 	your input.
 
+	Multiple commands can be entered at once by separating them with a
+	semicolon. They'll be executed one after another. The output of a
+	command can be passed on to another command by separating them with a
+	pipe symbol. Only commands that process input, like [::b]grep[::-], can be
+	used on the right side of a pipe. The output of the last command can be
+	written into a file instead of the command output by appending
+	"> FILE" or ">> FILE" to append to the file instead of overwriting it.
+	Colors are removed from output written into a file. In order to use
+	any of these symbols literally, quote them or put a backslash in front
+	of them.
+
+	Shorthands for commands and macros consisting of multiple commands
+	can be defined via the [::b]alias[::-] command.
+
+	Scripts inside of the script directory can register additional
+	commands. Those are listed together with the builtin commands, but
+	can't replace any of them.
+
 	Available commands:
 %s
 [::b]EXAMPLES
@@ -158,7 +186,10 @@ const commandsDocumentation = `[::b]TOPIC
 	[gray]$ user-set --name "Marcel Schramm" --avatar /home/pics/avatar.png
 	
 	[gray]$ status set online
-	[gray]$ status get`
+	[gray]$ status get
+
+	[gray]$ status set online; status get
+	[gray]$ friends list | grep online > ~/friends.txt`
 
 const configurationDocumentation = `[::b]TOPIC
 	configuration - allows you to change settings and persist them between
@@ -325,6 +356,55 @@ const configurationDocumentation = `[::b]TOPIC
 		Type:    boolean
 		Default: true
 
+	[::b]WatchScripts
+		Determines whether cordless watches the script directory for changes
+		and reloads all scripts as soon as a file has been changed.
+
+		Type:    boolean
+		Default: false
+
+	[::b]DisabledScripts
+		Contains the names of all scripts that won't be loaded. This setting
+		should be changed via the [::b]scripts[::-] command.
+
+	[::b]ScriptTimeout
+		Determines how many milliseconds a single script hook or command may
+		run before it is aborted. A value of 0 disables the limit.
+
+		Type:    integer
+		Default: 1000
+
+	[::b]ScriptMaxStackDepth
+		Limits the depth of nested function calls inside of scripts, which
+		prevents endless recursion. A value of 0 disables the limit.
+
+		Type:    integer
+		Default: 1000
+
+	[::b]ScriptMaxFailures
+		Determines after how many consecutive failures a script gets
+		disabled. Disabled scripts can be enabled again via the
+		[::b]scripts[::-] command. A value of 0 disables the limit.
+
+		Type:    integer
+		Default: 3
+
+	[::b]ScriptMaxStorageSize
+		Determines how many bytes the storage of a single script may take up
+		on disk. A value of 0 disables the limit.
+
+		Type:    integer
+		Default: 1048576
+
+	[::b]CommandHistorySize
+		Determines how many commands are kept in the command history. The
+		history is saved to the file "command_history.json" next to the
+		configuration file. A value of 0 keeps the history in memory only,
+		without limiting its size.
+
+		Type:    integer
+		Default: 500
+
 	[::b]Accounts
 		This settings holds an array of so called accounts, also referred to
 		as profiles. Those allow you to let cordless know of multiple discord
@@ -397,6 +477,20 @@ const navigationDocumentation = `[::b]TOPIC
 	Some shortcuts can be changed via the shortcut dialog. The dialog can be
 	opened via Alt+Shift+S.`
 
+// Complete offers all topics and the names of all commands.
+func (manual *Manual) Complete(parameters []string, index int) []string {
+	if index != 0 {
+		return nil
+	}
+
+	topics := []string{"chatview", "commands", "configuration", "message-editor", "navigation"}
+	for _, cmd := range manual.window.GetRegisteredCommands() {
+		topics = append(topics, cmd.Name())
+	}
+
+	return topics
+}
+
 func (manual *Manual) Name() string {
 	return "manual"
 }
@@ -407,5 +501,5 @@ func (manual *Manual) Aliases() []string {
 
 // PrintHelp prints a static help page for this command
 func (manual *Manual) PrintHelp(writer io.Writer) {
-	fmt.Fprintln(writer, manualDocumentation)
+	fmt.Fprint(writer, manualDocumentation)
 }
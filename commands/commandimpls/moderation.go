@@ -0,0 +1,787 @@
+package commandimpls
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Bios-Marcel/cordless/commands"
+	"github.com/Bios-Marcel/cordless/discordutil"
+	"github.com/Bios-Marcel/cordless/ui"
+	"github.com/Bios-Marcel/discordgo"
+	"github.com/Bios-Marcel/tview"
+)
+
+const kickHelpPage = `[::b]NAME
+	kick - removes a member from a server
+
+[::b]SYNPOSIS
+	[::b]kick[::-] [OPTION[]... <user>
+
+[::b]DESCRIPTION
+	This command removes the given member from the loaded server or the
+	server chosen via --server after asking for confirmation. The member
+	can join again if they have an invitation. Users can be passed as
+	'name#1234', by their username, their nickname or their ID. Kicking
+	requires the permission to kick members.
+
+%s
+
+[::b]EXAMPLES
+	[gray]$ kick Marcel#1234
+	[gray]$ kick --reason "Spamming links" 118456055842734083`
+
+const banHelpPage = `[::b]NAME
+	ban - bans a user from a server
+
+[::b]SYNPOSIS
+	[::b]ban[::-] [OPTION[]... <user>
+
+[::b]DESCRIPTION
+	This command bans the given user from the loaded server or the server
+	chosen via --server after asking for confirmation. Banned users can't
+	join the server again until they are unbanned. Users can be passed as
+	'name#1234', by their username, their nickname or their ID. Users that
+	aren't members of the server can be banned by their ID. Banning
+	requires the permission to ban members.
+
+%s
+
+[::b]EXAMPLES
+	[gray]$ ban Marcel#1234
+	[gray]$ ban --delete-days 7 --reason "Raiding" 118456055842734083`
+
+const unbanHelpPage = `[::b]NAME
+	unban - lifts the ban of a user
+
+[::b]SYNPOSIS
+	[::b]unban[::-] [OPTION[]... <user>
+
+[::b]DESCRIPTION
+	This command lifts the ban of the given user on the loaded server or
+	the server chosen via --server. Users can be passed as 'name#1234', by
+	their username or their ID. Unbanning requires the permission to ban
+	members.
+
+%s
+
+[::b]EXAMPLES
+	[gray]$ unban Marcel#1234
+	[gray]$ unban --reason "Appealed" 118456055842734083`
+
+const timeoutHelpPage = `[::b]NAME
+	timeout - prevents a member from communicating for a while
+
+[::b]SYNPOSIS
+	[::b]timeout[::-] [OPTION[]... <user> <duration|off>
+
+[::b]DESCRIPTION
+	This command prevents the given member of the loaded server or the
+	server chosen via --server from sending messages, reacting and joining
+	voice channels for the given duration, which can be at most 28 days.
+	Passing "off" instead of a duration ends the timeout early. Users can
+	be passed as 'name#1234', by their username, their nickname or their
+	ID. Timing out requires the permission to moderate members.
+
+%s
+
+[::b]EXAMPLES
+	[gray]$ timeout Marcel#1234 10m
+	[gray]$ timeout --reason "Calm down" Marcel 24h
+	[gray]$ timeout Marcel off`
+
+const purgeHelpPage = `[::b]NAME
+	purge - deletes the most recent messages of a channel
+
+[::b]SYNPOSIS
+	[::b]purge[::-] [OPTION[]... <N>
+
+[::b]DESCRIPTION
+	This command deletes the N most recent messages of the loaded channel
+	or the channel chosen via --channel after asking for confirmation.
+	Messages that are younger than 14 days are deleted in bulk, older
+	messages have to be deleted one by one, which takes a while. Purging
+	requires the permission to manage messages in the channel.
+
+%s
+
+[::b]EXAMPLES
+	[gray]$ purge 20
+	[gray]$ purge --channel #general --reason "Raid" 250`
+
+const roleHelpPage = `[::b]NAME
+	role - gives roles to or takes roles from a member
+
+[::b]SYNPOSIS
+	[::b]role add[::-] [OPTION[]... <user> <role>
+	[::b]role remove[::-] [OPTION[]... <user> <role>
+
+[::b]DESCRIPTION
+	This command gives a role to or takes a role from the given member of
+	the loaded server or the server chosen via --server. Users can be
+	passed as 'name#1234', by their username, their nickname or their ID.
+	Roles can be passed by their name or their ID. Changing roles requires
+	the permission to manage roles and only works for roles below your own
+	highest role.
+
+[::b]SUBCOMMANDS
+	[::b]add <user> <role>
+		gives the role to the member
+	[::b]remove <user> <role>
+		takes the role from the member
+
+%s
+
+[::b]EXAMPLES
+	[gray]$ role add Marcel#1234 Moderators
+	[gray]$ role remove --reason "Inactive" Marcel Moderators`
+
+// permissionModerateMembers allows timing out members. It is missing in
+// discordgo.
+const permissionModerateMembers = 1 << 40
+
+// maxTimeout is the longest timeout that Discord allows.
+const maxTimeout = 28 * 24 * time.Hour
+
+// bulkDeleteMaxAge is the maximum age of messages that can be bulk deleted.
+const bulkDeleteMaxAge = 14 * 24 * time.Hour
+
+var reasonFlag = &commands.Flag{
+	Short:       "r",
+	Long:        "reason",
+	Type:        commands.StringFlag,
+	ValueName:   "reason",
+	Description: "the reason that is shown in the audit log of the server",
+}
+
+var moderationFlags = &commands.FlagSet{
+	Flags: []*commands.Flag{serverFlag, reasonFlag},
+}
+
+var banFlags = &commands.FlagSet{
+	Flags: []*commands.Flag{
+		serverFlag,
+		reasonFlag,
+		{
+			Short:       "d",
+			Long:        "delete-days",
+			Type:        commands.IntFlag,
+			ValueName:   "days",
+			Description: "deletes the messages of the user from the last 0 to 7 days; defaults to 0",
+		},
+	},
+}
+
+var purgeFlags = &commands.FlagSet{
+	Flags: []*commands.Flag{
+		{
+			Short:       "c",
+			Long:        "channel",
+			Type:        commands.StringFlag,
+			ValueName:   "channel",
+			Description: "the channel of the loaded server to delete messages in; defaults to the loaded channel",
+		},
+		reasonFlag,
+	},
+}
+
+// moderationCmd contains what all moderation commands have in common.
+type moderationCmd struct {
+	window  *ui.Window
+	session *discordgo.Session
+}
+
+// KickCmd removes members from a server.
+type KickCmd struct {
+	*moderationCmd
+}
+
+// BanCmd bans users from a server.
+type BanCmd struct {
+	*moderationCmd
+}
+
+// UnbanCmd lifts bans.
+type UnbanCmd struct {
+	*moderationCmd
+}
+
+// TimeoutCmd temporarily prevents members from communicating.
+type TimeoutCmd struct {
+	*moderationCmd
+}
+
+// PurgeCmd deletes the most recent messages of a channel.
+type PurgeCmd struct {
+	*moderationCmd
+}
+
+// RoleCmd gives roles to and takes roles from members.
+type RoleCmd struct {
+	*moderationCmd
+}
+
+// NewKickCommand creates a ready-to-use kick command.
+func NewKickCommand(window *ui.Window, session *discordgo.Session) *KickCmd {
+	return &KickCmd{&moderationCmd{window, session}}
+}
+
+// NewBanCommand creates a ready-to-use ban command.
+func NewBanCommand(window *ui.Window, session *discordgo.Session) *BanCmd {
+	return &BanCmd{&moderationCmd{window, session}}
+}
+
+// NewUnbanCommand creates a ready-to-use unban command.
+func NewUnbanCommand(window *ui.Window, session *discordgo.Session) *UnbanCmd {
+	return &UnbanCmd{&moderationCmd{window, session}}
+}
+
+// NewTimeoutCommand creates a ready-to-use timeout command.
+func NewTimeoutCommand(window *ui.Window, session *discordgo.Session) *TimeoutCmd {
+	return &TimeoutCmd{&moderationCmd{window, session}}
+}
+
+// NewPurgeCommand creates a ready-to-use purge command.
+func NewPurgeCommand(window *ui.Window, session *discordgo.Session) *PurgeCmd {
+	return &PurgeCmd{&moderationCmd{window, session}}
+}
+
+// NewRoleCommand creates a ready-to-use role command.
+func NewRoleCommand(window *ui.Window, session *discordgo.Session) *RoleCmd {
+	return &RoleCmd{&moderationCmd{window, session}}
+}
+
+// prepare parses the parameters, makes sure that the expected amount of
+// arguments was passed, chooses the server and checks whether the user is
+// allowed to perform the action on it. If anything fails, an error is
+// printed and false is returned.
+func (cmd *moderationCmd) prepare(writer io.Writer, flagSet *commands.FlagSet, parameters []string,
+	usage string, argumentCount int, permission int64, action string) (*commands.ParsedFlags, *discordgo.Guild, bool) {
+	flags, parseError := flagSet.Parse(parameters)
+	if parseError != nil {
+		fmt.Fprintf(writer, "[red]Error parsing parameters:\n\t[red]%s\n", tview.Escape(parseError.Error()))
+		return nil, nil, false
+	}
+
+	if len(flags.Arguments) != argumentCount {
+		fmt.Fprintln(writer, "[red]Usage: "+usage)
+		return nil, nil, false
+	}
+
+	guild, guildError := findServer(cmd.window, cmd.session.State, flags.String("server"))
+	if guildError != nil {
+		fmt.Fprintf(writer, "[red]Error choosing server:\n\t[red]%s\n", tview.Escape(guildError.Error()))
+		return nil, nil, false
+	}
+
+	if !discordutil.HasGuildPermission(guild.ID, permission, cmd.session.State) {
+		fmt.Fprintf(writer, "[red]You aren't allowed to %s on the server '%s'.\n", action, tview.Escape(guild.Name))
+		return nil, nil, false
+	}
+
+	return flags, guild, true
+}
+
+// findMember looks up the member that matches the input. If there's no
+// match or more than one, an error is printed and nil is returned.
+func (cmd *moderationCmd) findMember(writer io.Writer, guild *discordgo.Guild, input string) *discordgo.Member {
+	matches := discordutil.FindMembers(cmd.session.State, guild.ID, input)
+	if len(matches) == 1 {
+		return matches[0]
+	}
+
+	if len(matches) == 0 {
+		fmt.Fprintf(writer, "[red]No member of the server '%s' matches '%s'.\n", tview.Escape(guild.Name), tview.Escape(input))
+		return nil
+	}
+
+	fmt.Fprintf(writer, "Multiple matches were found for '%s'. Please be more precise.\n", tview.Escape(input))
+	fmt.Fprintln(writer, "The following matches were found:")
+	for _, match := range matches {
+		fmt.Fprintf(writer, "\t%s\n", tview.Escape(match.User.String()))
+	}
+	return nil
+}
+
+// confirm asks the user whether the action should really be performed and
+// runs it in the background if the user agrees. Since Execute has returned
+// by then, the action receives a writer that prints into the command view.
+func (cmd *moderationCmd) confirm(text, buttonText string, action func(writer io.Writer)) {
+	cmd.window.ShowDialog(tview.Styles.PrimitiveBackgroundColor, text, func(button string) {
+		if button == buttonText {
+			go action(cmd.window.GetBackgroundOutput())
+		}
+	}, buttonText, "Abort")
+}
+
+// completeMembers offers the usernames of the members of the loaded server.
+func (cmd *moderationCmd) completeMembers() []string {
+	guild := cmd.window.GetSelectedGuild()
+	if guild == nil {
+		return nil
+	}
+
+	names := make([]string, 0, len(guild.Members))
+	for _, member := range guild.Members {
+		names = append(names, member.User.String())
+	}
+	return names
+}
+
+// Execute runs the command piping its output into the supplied writer.
+func (cmd *KickCmd) Execute(writer io.Writer, parameters []string) {
+	flags, guild, ok := cmd.prepare(writer, moderationFlags, parameters,
+		"kick [OPTION[]... <user>", 1, discordgo.PermissionKickMembers, "kick members")
+	if !ok {
+		return
+	}
+
+	member := cmd.findMember(writer, guild, flags.Arguments[0])
+	if member == nil {
+		return
+	}
+
+	cmd.confirm(fmt.Sprintf("Do you really want to kick '%s' from the server '%s'?",
+		tview.Escape(member.User.String()), tview.Escape(guild.Name)), "Kick", func(writer io.Writer) {
+		kickError := cmd.session.GuildMemberDeleteWithReason(guild.ID, member.User.ID, flags.String("reason"))
+		if kickError != nil {
+			fmt.Fprintf(writer, "[red]Error kicking member:\n\t[red]%s\n", kickError)
+			return
+		}
+		fmt.Fprintf(writer, "Kicked '%s'.\n", tview.Escape(member.User.String()))
+	})
+}
+
+// Execute runs the command piping its output into the supplied writer.
+func (cmd *BanCmd) Execute(writer io.Writer, parameters []string) {
+	flags, guild, ok := cmd.prepare(writer, banFlags, parameters,
+		"ban [OPTION[]... <user>", 1, discordgo.PermissionBanMembers, "ban members")
+	if !ok {
+		return
+	}
+
+	deleteDays := flags.Int("delete-days")
+	if deleteDays < 0 || deleteDays > 7 {
+		fmt.Fprintf(writer, "[red]The amount of days has to be between 0 and 7, but was %d.\n", deleteDays)
+		return
+	}
+
+	input := flags.Arguments[0]
+	var userID, userName string
+	if matches := discordutil.FindMembers(cmd.session.State, guild.ID, input); len(matches) == 0 && isSnowflake(input) {
+		// Users that aren't members can be banned by ID.
+		userID, userName = input, input
+	} else {
+		member := cmd.findMember(writer, guild, input)
+		if member == nil {
+			return
+		}
+		userID, userName = member.User.ID, member.User.String()
+	}
+
+	cmd.confirm(fmt.Sprintf("Do you really want to ban '%s' from the server '%s'?",
+		tview.Escape(userName), tview.Escape(guild.Name)), "Ban", func(writer io.Writer) {
+		banError := cmd.session.GuildBanCreateWithReason(guild.ID, userID, flags.String("reason"), deleteDays)
+		if banError != nil {
+			fmt.Fprintf(writer, "[red]Error banning user:\n\t[red]%s\n", banError)
+			return
+		}
+		fmt.Fprintf(writer, "Banned '%s'.\n", tview.Escape(userName))
+	})
+}
+
+// Execute runs the command piping its output into the supplied writer.
+func (cmd *UnbanCmd) Execute(writer io.Writer, parameters []string) {
+	flags, guild, ok := cmd.prepare(writer, moderationFlags, parameters,
+		"unban [OPTION[]... <user>", 1, discordgo.PermissionBanMembers, "ban members")
+	if !ok {
+		return
+	}
+
+	bans, bansError := cmd.session.GuildBans(guild.ID)
+	if bansError != nil {
+		fmt.Fprintf(writer, "[red]Error retrieving bans:\n\t[red]%s\n", bansError)
+		return
+	}
+
+	input := flags.Arguments[0]
+	var matches []*discordgo.User
+	for _, ban := range bans {
+		if ban.User.ID == input || ban.User.String() == input {
+			matches = []*discordgo.User{ban.User}
+			break
+		}
+		if ban.User.Username == input {
+			matches = append(matches, ban.User)
+		}
+	}
+
+	if len(matches) == 0 {
+		fmt.Fprintf(writer, "[red]No banned user of the server '%s' matches '%s'.\n", tview.Escape(guild.Name), tview.Escape(input))
+		return
+	}
+	if len(matches) > 1 {
+		fmt.Fprintf(writer, "Multiple matches were found for '%s'. Please be more precise.\n", tview.Escape(input))
+		fmt.Fprintln(writer, "The following matches were found:")
+		for _, match := range matches {
+			fmt.Fprintf(writer, "\t%s\n", tview.Escape(match.String()))
+		}
+		return
+	}
+
+	_, unbanError := discordutil.RequestWithReason(cmd.session, "DELETE", discordgo.EndpointGuildBan(guild.ID, matches[0].ID),
+		nil, discordgo.EndpointGuildBan(guild.ID, ""), flags.String("reason"))
+	if unbanError != nil {
+		fmt.Fprintf(writer, "[red]Error unbanning user:\n\t[red]%s\n", unbanError)
+		return
+	}
+	fmt.Fprintf(writer, "Unbanned '%s'.\n", tview.Escape(matches[0].String()))
+}
+
+// Execute runs the command piping its output into the supplied writer.
+func (cmd *TimeoutCmd) Execute(writer io.Writer, parameters []string) {
+	flags, guild, ok := cmd.prepare(writer, moderationFlags, parameters,
+		"timeout [OPTION[]... <user> <duration|off>", 2, permissionModerateMembers, "moderate members")
+	if !ok {
+		return
+	}
+
+	member := cmd.findMember(writer, guild, flags.Arguments[0])
+	if member == nil {
+		return
+	}
+
+	endpoint := discordgo.EndpointGuildMember(guild.ID, member.User.ID)
+	bucket := discordgo.EndpointGuildMember(guild.ID, "")
+	if flags.Arguments[1] == "off" {
+		_, timeoutError := discordutil.RequestWithReason(cmd.session, "PATCH", endpoint,
+			map[string]interface{}{"communication_disabled_until": nil}, bucket, flags.String("reason"))
+		if timeoutError != nil {
+			fmt.Fprintf(writer, "[red]Error ending timeout:\n\t[red]%s\n", timeoutError)
+			return
+		}
+		fmt.Fprintf(writer, "Ended the timeout of '%s'.\n", tview.Escape(member.User.String()))
+		return
+	}
+
+	duration, parseError := time.ParseDuration(flags.Arguments[1])
+	if parseError == nil && (duration <= 0 || duration > maxTimeout) {
+		parseError = errors.New("the duration has to be greater than 0 and at most 28 days")
+	}
+	if parseError != nil {
+		fmt.Fprintf(writer, "[red]Error parsing duration:\n\t[red]%s\n", tview.Escape(parseError.Error()))
+		return
+	}
+
+	cmd.confirm(fmt.Sprintf("Do you really want to time out '%s' for %s?",
+		tview.Escape(member.User.String()), duration), "Time out", func(writer io.Writer) {
+		until := time.Now().Add(duration).UTC().Format(time.RFC3339)
+		_, timeoutError := discordutil.RequestWithReason(cmd.session, "PATCH", endpoint,
+			map[string]interface{}{"communication_disabled_until": until}, bucket, flags.String("reason"))
+		if timeoutError != nil {
+			fmt.Fprintf(writer, "[red]Error timing out member:\n\t[red]%s\n", timeoutError)
+			return
+		}
+		fmt.Fprintf(writer, "Timed out '%s' for %s.\n", tview.Escape(member.User.String()), duration)
+	})
+}
+
+// Execute runs the command piping its output into the supplied writer.
+func (cmd *PurgeCmd) Execute(writer io.Writer, parameters []string) {
+	flags, parseError := purgeFlags.Parse(parameters)
+	if parseError == nil && len(flags.Arguments) != 1 {
+		fmt.Fprintln(writer, "[red]Usage: purge [OPTION[]... <N>")
+		return
+	}
+	if parseError != nil {
+		fmt.Fprintf(writer, "[red]Error parsing parameters:\n\t[red]%s\n", tview.Escape(parseError.Error()))
+		return
+	}
+
+	amount, amountError := strconv.Atoi(flags.Arguments[0])
+	if amountError != nil || amount < 1 {
+		fmt.Fprintf(writer, "[red]The amount of messages has to be a number greater than 0, but was '%s'.\n", tview.Escape(flags.Arguments[0]))
+		return
+	}
+
+	channel := cmd.window.GetSelectedChannel()
+	if flags.IsSet("channel") {
+		guild := cmd.window.GetSelectedGuild()
+		if guild == nil {
+			fmt.Fprintln(writer, "[red]No server is loaded.")
+			return
+		}
+
+		var findError error
+		channel, findError = findGuildChannel(guild, flags.String("channel"))
+		if findError != nil {
+			fmt.Fprintf(writer, "[red]Error choosing channel:\n\t[red]%s\n", tview.Escape(findError.Error()))
+			return
+		}
+	}
+	if channel == nil {
+		fmt.Fprintln(writer, "[red]No channel is loaded, use --channel in order to choose one.")
+		return
+	}
+
+	if !discordutil.HasChannelPermission(channel.ID, discordgo.PermissionManageMessages, cmd.session.State) {
+		fmt.Fprintf(writer, "[red]You aren't allowed to manage the messages in the channel '%s'.\n", tview.Escape(channel.Name))
+		return
+	}
+
+	cmd.confirm(fmt.Sprintf("Do you really want to delete the last %d messages in the channel '%s'?",
+		amount, tview.Escape(channel.Name)), "Delete", func(writer io.Writer) {
+		cmd.purge(writer, channel, amount, flags.String("reason"))
+	})
+}
+
+// purge deletes the given amount of messages, starting with the newest. As
+// soon as a deletion fails, the purge stops, since otherwise older messages
+// than the requested ones would be deleted.
+func (cmd *PurgeCmd) purge(writer io.Writer, channel *discordgo.Channel, amount int, reason string) {
+	var deleted int
+	var before string
+OUTER_LOOP:
+	for deleted < amount {
+		limit := amount - deleted
+		if limit > 100 {
+			limit = 100
+		}
+
+		messages, discordError := cmd.session.ChannelMessages(channel.ID, limit, before, "", "")
+		if discordError != nil {
+			fmt.Fprintf(writer, "[red]Error retrieving messages:\n\t[red]%s\n", discordError)
+			break
+		}
+		if len(messages) == 0 {
+			break
+		}
+		before = messages[len(messages)-1].ID
+
+		var bulkDeletable, old []string
+		for _, message := range messages {
+			timestamp, parseError := message.Timestamp.Parse()
+			// A bit of leeway, since the request takes some time.
+			if parseError == nil && time.Since(timestamp) < bulkDeleteMaxAge-time.Minute {
+				bulkDeletable = append(bulkDeletable, message.ID)
+			} else {
+				old = append(old, message.ID)
+			}
+		}
+
+		if len(bulkDeletable) == 1 {
+			// Bulk deletion requires at least two messages.
+			old = append(old, bulkDeletable...)
+		} else if len(bulkDeletable) > 1 {
+			_, deleteError := discordutil.RequestWithReason(cmd.session, "POST", discordgo.EndpointChannelMessagesBulkDelete(channel.ID),
+				map[string][]string{"messages": bulkDeletable}, discordgo.EndpointChannelMessagesBulkDelete(channel.ID), reason)
+			if deleteError != nil {
+				fmt.Fprintf(writer, "[red]Error deleting messages:\n\t[red]%s\n", deleteError)
+				break
+			}
+			deleted += len(bulkDeletable)
+		}
+
+		for _, messageID := range old {
+			_, deleteError := discordutil.RequestWithReason(cmd.session, "DELETE", discordgo.EndpointChannelMessage(channel.ID, messageID),
+				nil, discordgo.EndpointChannelMessage(channel.ID, ""), reason)
+			if deleteError != nil {
+				fmt.Fprintf(writer, "[red]Error deleting message:\n\t[red]%s\n", deleteError)
+				break OUTER_LOOP
+			}
+			deleted++
+		}
+
+		fmt.Fprintf(writer, "[gray]Deleted %d of %d messages.\n", deleted, amount)
+
+		if len(messages) < limit {
+			break
+		}
+	}
+
+	fmt.Fprintf(writer, "Deleted %d messages in the channel '%s'.\n", deleted, tview.Escape(channel.Name))
+}
+
+// Execute runs the command piping its output into the supplied writer.
+func (cmd *RoleCmd) Execute(writer io.Writer, parameters []string) {
+	if len(parameters) == 0 || (parameters[0] != "add" && parameters[0] != "remove") {
+		fmt.Fprintln(writer, "[red]Usage: role add|remove [OPTION[]... <user> <role>")
+		return
+	}
+
+	add := parameters[0] == "add"
+	flags, guild, ok := cmd.prepare(writer, moderationFlags, parameters[1:],
+		"role add|remove [OPTION[]... <user> <role>", 2, discordgo.PermissionManageRoles, "manage roles")
+	if !ok {
+		return
+	}
+
+	member := cmd.findMember(writer, guild, flags.Arguments[0])
+	if member == nil {
+		return
+	}
+
+	role, roleError := findRole(guild, flags.Arguments[1])
+	if roleError != nil {
+		fmt.Fprintf(writer, "[red]Error choosing role:\n\t[red]%s\n", tview.Escape(roleError.Error()))
+		return
+	}
+
+	method := "DELETE"
+	if add {
+		method = "PUT"
+	}
+	_, requestError := discordutil.RequestWithReason(cmd.session, method, discordgo.EndpointGuildMemberRole(guild.ID, member.User.ID, role.ID),
+		nil, discordgo.EndpointGuildMemberRole(guild.ID, "", ""), flags.String("reason"))
+	if requestError != nil {
+		fmt.Fprintf(writer, "[red]Error changing roles:\n\t[red]%s\n", requestError)
+		return
+	}
+
+	if add {
+		fmt.Fprintf(writer, "Gave the role '%s' to '%s'.\n", tview.Escape(role.Name), tview.Escape(member.User.String()))
+	} else {
+		fmt.Fprintf(writer, "Took the role '%s' from '%s'.\n", tview.Escape(role.Name), tview.Escape(member.User.String()))
+	}
+}
+
+// findRole looks up a role of the guild by its ID or its name, ignoring
+// case.
+func findRole(guild *discordgo.Guild, nameOrID string) (*discordgo.Role, error) {
+	var match *discordgo.Role
+	for _, role := range guild.Roles {
+		if role.ID == nameOrID {
+			return role, nil
+		}
+
+		if strings.EqualFold(role.Name, nameOrID) {
+			if match != nil {
+				return nil, fmt.Errorf("there are multiple roles called '%s', use the ID instead", nameOrID)
+			}
+			match = role
+		}
+	}
+
+	if match == nil {
+		return nil, fmt.Errorf("no role with the ID or name '%s' was found", nameOrID)
+	}
+	return match, nil
+}
+
+// isSnowflake checks whether the input could be a Discord ID.
+func isSnowflake(input string) bool {
+	_, parseError := strconv.ParseUint(input, 10, 64)
+	return parseError == nil
+}
+
+// Complete offers the members of the loaded server.
+func (cmd *KickCmd) Complete(parameters []string, index int) []string {
+	return cmd.completeMembers()
+}
+
+// Complete offers the members of the loaded server.
+func (cmd *BanCmd) Complete(parameters []string, index int) []string {
+	return cmd.completeMembers()
+}
+
+// Complete offers the members of the loaded server.
+func (cmd *TimeoutCmd) Complete(parameters []string, index int) []string {
+	return cmd.completeMembers()
+}
+
+// Complete offers the subcommands, the members and the roles of the loaded
+// server.
+func (cmd *RoleCmd) Complete(parameters []string, index int) []string {
+	if index == 0 {
+		return []string{"add", "remove"}
+	}
+
+	guild := cmd.window.GetSelectedGuild()
+	if index == 1 || guild == nil {
+		return cmd.completeMembers()
+	}
+
+	roles := make([]string, 0, len(guild.Roles))
+	for _, role := range guild.Roles {
+		if role.ID != guild.ID {
+			roles = append(roles, role.Name)
+		}
+	}
+	sort.Strings(roles)
+	return roles
+}
+
+// Name returns the primary name for this command. This name will also be
+// used for listing the command in the commandlist.
+func (cmd *KickCmd) Name() string {
+	return "kick"
+}
+
+// Name returns the primary name for this command. This name will also be
+// used for listing the command in the commandlist.
+func (cmd *BanCmd) Name() string {
+	return "ban"
+}
+
+// Name returns the primary name for this command. This name will also be
+// used for listing the command in the commandlist.
+func (cmd *UnbanCmd) Name() string {
+	return "unban"
+}
+
+// Name returns the primary name for this command. This name will also be
+// used for listing the command in the commandlist.
+func (cmd *TimeoutCmd) Name() string {
+	return "timeout"
+}
+
+// Name returns the primary name for this command. This name will also be
+// used for listing the command in the commandlist.
+func (cmd *PurgeCmd) Name() string {
+	return "purge"
+}
+
+// Name returns the primary name for this command. This name will also be
+// used for listing the command in the commandlist.
+func (cmd *RoleCmd) Name() string {
+	return "role"
+}
+
+// Aliases are a list of aliases for this command. There might be none.
+func (cmd *moderationCmd) Aliases() []string {
+	return nil
+}
+
+// PrintHelp prints a static help page for this command
+func (cmd *KickCmd) PrintHelp(writer io.Writer) {
+	fmt.Fprintf(writer, kickHelpPage+"\n", moderationFlags.OptionsHelp())
+}
+
+// PrintHelp prints a static help page for this command
+func (cmd *BanCmd) PrintHelp(writer io.Writer) {
+	fmt.Fprintf(writer, banHelpPage+"\n", banFlags.OptionsHelp())
+}
+
+// PrintHelp prints a static help page for this command
+func (cmd *UnbanCmd) PrintHelp(writer io.Writer) {
+	fmt.Fprintf(writer, unbanHelpPage+"\n", moderationFlags.OptionsHelp())
+}
+
+// PrintHelp prints a static help page for this command
+func (cmd *TimeoutCmd) PrintHelp(writer io.Writer) {
+	fmt.Fprintf(writer, timeoutHelpPage+"\n", moderationFlags.OptionsHelp())
+}
+
+// PrintHelp prints a static help page for this command
+func (cmd *PurgeCmd) PrintHelp(writer io.Writer) {
+	fmt.Fprintf(writer, purgeHelpPage+"\n", purgeFlags.OptionsHelp())
+}
+
+// PrintHelp prints a static help page for this command
+func (cmd *RoleCmd) PrintHelp(writer io.Writer) {
+	fmt.Fprintf(writer, roleHelpPage+"\n", moderationFlags.OptionsHelp())
+}
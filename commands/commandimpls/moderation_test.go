@@ -0,0 +1,59 @@
+package commandimpls
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Bios-Marcel/discordgo"
+)
+
+// handlerTransport passes all requests to a handler instead of sending them.
+type handlerTransport struct {
+	handler http.HandlerFunc
+}
+
+func (transport *handlerTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	recorder := httptest.NewRecorder()
+	transport.handler(recorder, request)
+	return recorder.Result(), nil
+}
+
+func TestPurgeStopsOnFailedDelete(t *testing.T) {
+	var requests []string
+	session, _ := discordgo.NewWithToken("cordless", "token")
+	session.Client = &http.Client{Transport: &handlerTransport{func(writer http.ResponseWriter, request *http.Request) {
+		requests = append(requests, request.Method+" "+request.URL.Path)
+		switch {
+		case request.Method == "GET":
+			// All messages are too old to be bulk deleted.
+			writer.Write([]byte(`[
+				{"id": "3", "timestamp": "2019-01-03T00:00:00+00:00"},
+				{"id": "2", "timestamp": "2019-01-02T00:00:00+00:00"},
+				{"id": "1", "timestamp": "2019-01-01T00:00:00+00:00"}
+			]`))
+		case strings.HasSuffix(request.URL.Path, "/2"):
+			writer.WriteHeader(http.StatusForbidden)
+			writer.Write([]byte(`{"code": 50013, "message": "Missing Permissions"}`))
+		default:
+			writer.WriteHeader(http.StatusNoContent)
+		}
+	}}}
+
+	output := &bytes.Buffer{}
+	NewPurgeCommand(nil, session).purge(output, &discordgo.Channel{ID: "10", Name: "general"}, 3, "")
+
+	want := []string{
+		"GET /api/v6/channels/10/messages",
+		"DELETE /api/v6/channels/10/messages/3",
+		"DELETE /api/v6/channels/10/messages/2",
+	}
+	if strings.Join(requests, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Requests were %q, want %q", requests, want)
+	}
+	if !strings.Contains(output.String(), "Deleted 1 messages") {
+		t.Errorf("Expected the summary to report one deleted message, got %q", output.String())
+	}
+}
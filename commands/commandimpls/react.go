@@ -0,0 +1,162 @@
+package commandimpls
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/Bios-Marcel/cordless/commands"
+	"github.com/Bios-Marcel/cordless/discordutil"
+	"github.com/Bios-Marcel/cordless/ui"
+	"github.com/Bios-Marcel/discordemojimap"
+	"github.com/Bios-Marcel/discordgo"
+	"github.com/Bios-Marcel/tview"
+)
+
+const reactHelpPage = `[::b]NAME
+	react - adds or removes reactions on a message
+
+[::b]SYNPOSIS
+	[::b]react[::-] [OPTION[]... <emoji>...
+
+[::b]DESCRIPTION
+	This command toggles your reaction with each of the given emojis on a
+	message of the loaded channel. If you haven't reacted with an emoji
+	yet, the reaction is added, otherwise it is removed. By default the
+	latest message is used, the message selected in the chat view can be
+	reacted to via its shortcut, which fills in the --message option.
+
+	Emojis can be passed as unicode emojis, as emoji codes like
+	':thumbsup:', with or without the colons, or as the names of custom
+	emojis of the loaded server.
+
+%s
+
+[::b]EXAMPLES
+	[gray]$ react :thumbsup:
+	[gray]$ react --message 607573813279178763 tada gopher`
+
+var reactFlags = &commands.FlagSet{
+	Flags: []*commands.Flag{
+		{
+			Short:       "m",
+			Long:        "message",
+			Type:        commands.StringFlag,
+			ValueName:   "ID",
+			Description: "the message of the loaded channel to react to; defaults to the latest message",
+		},
+	},
+}
+
+// ReactCmd adds and removes reactions on messages.
+type ReactCmd struct {
+	window  *ui.Window
+	session *discordgo.Session
+}
+
+// NewReactCommand creates a ready-to-use react command.
+func NewReactCommand(window *ui.Window, session *discordgo.Session) *ReactCmd {
+	return &ReactCmd{window, session}
+}
+
+// Execute runs the command piping its output into the supplied writer.
+func (cmd *ReactCmd) Execute(writer io.Writer, parameters []string) {
+	flags, parseError := reactFlags.Parse(parameters)
+	if parseError != nil {
+		fmt.Fprintf(writer, "[red]Error parsing parameters:\n\t[red]%s\n", tview.Escape(parseError.Error()))
+		return
+	}
+
+	if len(flags.Arguments) == 0 {
+		fmt.Fprintln(writer, "[red]Usage: react [OPTION[]... <emoji>...")
+		return
+	}
+
+	channel := cmd.window.GetSelectedChannel()
+	if channel == nil {
+		fmt.Fprintln(writer, "[red]No channel is loaded.")
+		return
+	}
+
+	message := cmd.window.GetLoadedMessage(flags.String("message"))
+	if message == nil {
+		if flags.IsSet("message") {
+			fmt.Fprintf(writer, "[red]The message '%s' isn't displayed in the loaded channel.\n", tview.Escape(flags.String("message")))
+		} else {
+			fmt.Fprintln(writer, "[red]There are no messages in the loaded channel.")
+		}
+		return
+	}
+
+	emojis := make([]*discordgo.Emoji, 0, len(flags.Arguments))
+	for _, argument := range flags.Arguments {
+		emoji, emojiError := discordutil.ParseEmoji(cmd.session.State, channel.GuildID, argument)
+		if emojiError != nil {
+			fmt.Fprintf(writer, "[red]Error parsing emoji:\n\t[red]%s\n", tview.Escape(emojiError.Error()))
+			return
+		}
+		emojis = append(emojis, emoji)
+	}
+
+	for _, emoji := range emojis {
+		// The reactions of the message are updated by the gateway events.
+		reaction := discordutil.FindReaction(message, emoji)
+		if reaction != nil && reaction.Me {
+			// Unlike MessageReactionAdd, this doesn't escape the emoji itself.
+			emojiID := strings.Replace(emoji.APIName(), "#", "%23", -1)
+			reactError := cmd.session.MessageReactionRemove(message.ChannelID, message.ID, emojiID, "@me")
+			if reactError != nil {
+				fmt.Fprintf(writer, "[red]Error removing reaction:\n\t[red]%s\n", reactError)
+				return
+			}
+		} else {
+			reactError := cmd.session.MessageReactionAdd(message.ChannelID, message.ID, emoji.APIName())
+			if reactError != nil {
+				fmt.Fprintf(writer, "[red]Error adding reaction:\n\t[red]%s\n", reactError)
+				return
+			}
+		}
+	}
+}
+
+// Complete offers emoji codes and the custom emojis of the loaded server
+// that start with the typed text.
+func (cmd *ReactCmd) Complete(parameters []string, index int) []string {
+	typed := strings.TrimPrefix(parameters[index], ":")
+	if typed == "" || strings.HasPrefix(parameters[index], "-") {
+		return nil
+	}
+
+	// The candidates keep the colons, if the user has typed them.
+	format := func(name string) string { return name }
+	if strings.HasPrefix(parameters[index], ":") {
+		format = func(name string) string { return ":" + name + ":" }
+	}
+
+	var candidates []string
+	if guild := cmd.window.GetSelectedGuild(); guild != nil {
+		for _, emoji := range guild.Emojis {
+			candidates = append(candidates, format(emoji.Name))
+		}
+	}
+	for code := range discordemojimap.GetEntriesStartingWith(typed) {
+		candidates = append(candidates, format(code))
+	}
+	return candidates
+}
+
+// Name returns the primary name for this command. This name will also be
+// used for listing the command in the commandlist.
+func (cmd *ReactCmd) Name() string {
+	return "react"
+}
+
+// Aliases are a list of aliases for this command. There might be none.
+func (cmd *ReactCmd) Aliases() []string {
+	return []string{"reaction"}
+}
+
+// PrintHelp prints a static help page for this command
+func (cmd *ReactCmd) PrintHelp(writer io.Writer) {
+	fmt.Fprintf(writer, reactHelpPage+"\n", reactFlags.OptionsHelp())
+}
@@ -0,0 +1,139 @@
+package commandimpls
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/Bios-Marcel/cordless/commands"
+	"github.com/Bios-Marcel/cordless/ui"
+	"github.com/Bios-Marcel/discordgo"
+	"github.com/Bios-Marcel/tview"
+)
+
+const readHelpPage = `[::b]NAME
+	read - marks servers or everything as read
+
+[::b]SYNPOSIS
+	[::b]read[::-] [OPTION[]... [server]...
+
+[::b]DESCRIPTION
+	This command acknowledges all unread channels of the given servers. If
+	no server is given, the loaded server is marked as read. Servers can be
+	passed either by ID or by name. Using --all, all servers and private
+	chats are marked as read instead.
+
+	Since every channel has to be acknowledged separately, the channels are
+	marked as read one after another with a short delay, in order not to
+	send too many requests at once. The same can be done via the shortcuts
+	for marking the selected server or everything as read.
+
+%s
+
+[::b]EXAMPLES
+	[gray]$ read
+	[gray]$ read "Cordless Server" 123456789012345678
+	[gray]$ read --all`
+
+var readFlags = &commands.FlagSet{
+	Flags: []*commands.Flag{
+		{
+			Short:       "a",
+			Long:        "all",
+			Type:        commands.BoolFlag,
+			Description: "marks all servers and private chats as read",
+		},
+	},
+}
+
+// ReadCmd marks servers or everything as read.
+type ReadCmd struct {
+	window  *ui.Window
+	session *discordgo.Session
+}
+
+// NewReadCommand creates a ready-to-use read command.
+func NewReadCommand(window *ui.Window, session *discordgo.Session) *ReadCmd {
+	return &ReadCmd{window, session}
+}
+
+// Execute runs the command piping its output into the supplied writer.
+func (cmd *ReadCmd) Execute(writer io.Writer, parameters []string) {
+	flags, parseError := readFlags.Parse(parameters)
+	if parseError != nil {
+		fmt.Fprintf(writer, "[red]Error parsing parameters:\n\t[red]%s\n", tview.Escape(parseError.Error()))
+		return
+	}
+
+	if flags.Bool("all") {
+		if len(flags.Arguments) > 0 {
+			fmt.Fprintln(writer, "[red]Usage: read [OPTION[]... [server]...")
+			return
+		}
+
+		go cmd.markAsRead(cmd.window.MarkEverythingAsRead)
+		return
+	}
+
+	nameOrIDs := flags.Arguments
+	if len(nameOrIDs) == 0 {
+		nameOrIDs = []string{""}
+	}
+
+	guildIDs := make([]string, 0, len(nameOrIDs))
+	for _, nameOrID := range nameOrIDs {
+		guild, findError := findServer(cmd.window, cmd.session.State, nameOrID)
+		if findError != nil {
+			fmt.Fprintf(writer, "[red]Error finding server:\n\t[red]%s\n", tview.Escape(findError.Error()))
+			return
+		}
+		guildIDs = append(guildIDs, guild.ID)
+	}
+
+	go cmd.markAsRead(func() (int, error) {
+		return cmd.window.MarkGuildsAsRead(guildIDs...)
+	})
+}
+
+// markAsRead runs the given function, which is expected to block until all
+// channels have been marked as read, and prints its outcome. As this runs in
+// the background, the output goes directly into the command view.
+func (cmd *ReadCmd) markAsRead(markAsRead func() (int, error)) {
+	writer := cmd.window.GetBackgroundOutput()
+	read, ackError := markAsRead()
+	if ackError != nil {
+		fmt.Fprintf(writer, "[red]Error marking channels as read:\n\t[red]%s\n", ackError)
+		if read == 0 {
+			return
+		}
+	}
+	if read == 1 {
+		fmt.Fprintln(writer, "Marked 1 channel as read.")
+	} else {
+		fmt.Fprintf(writer, "Marked %d channels as read.\n", read)
+	}
+}
+
+// Complete offers the names of all servers.
+func (cmd *ReadCmd) Complete(parameters []string, index int) []string {
+	var candidates []string
+	for _, guild := range cmd.session.State.Guilds {
+		candidates = append(candidates, guild.Name)
+	}
+	return candidates
+}
+
+// Name returns the primary name for this command. This name will also be
+// used for listing the command in the commandlist.
+func (cmd *ReadCmd) Name() string {
+	return "read"
+}
+
+// Aliases are a list of aliases for this command. There might be none.
+func (cmd *ReadCmd) Aliases() []string {
+	return []string{"mark-read", "ack"}
+}
+
+// PrintHelp prints a static help page for this command
+func (cmd *ReadCmd) PrintHelp(writer io.Writer) {
+	fmt.Fprintf(writer, readHelpPage+"\n", readFlags.OptionsHelp())
+}
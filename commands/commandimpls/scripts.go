@@ -0,0 +1,202 @@
+package commandimpls
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/Bios-Marcel/cordless/config"
+	"github.com/Bios-Marcel/cordless/scripting"
+)
+
+const scriptsHelpPage = `[::b]NAME
+	scripts - manage the scripts loaded from the script directory
+
+[::b]SYNPOSIS
+	[::b]scripts[::-] [list|reload|errors]
+	[::b]scripts[::-] <enable|disable> <name>
+
+[::b]DESCRIPTION
+	This command allows inspecting and managing the loaded scripts without
+	having to restart the application. The name of a script is its path
+	relative to the script directory.
+
+[::b]SUBCOMMANDS
+	[::b]list (default)
+		lists all scripts and whether they are active
+	[::b]reload
+		unloads all scripts and loads them from the script directory again
+	[::b]errors
+		prints the errors of all scripts that failed to load
+	[::b]enable <name>
+		enables a previously disabled script and loads it
+	[::b]disable <name>
+		unloads the script and prevents it from being loaded in the future
+
+[::b]EXAMPLES
+	[gray]$ scripts
+	[green]active[white]   greeter.js
+	[red]failed[white]   broken.js
+	[gray]disabled[white] spammer.js
+
+	[gray]$ scripts disable spammer.js`
+
+// ScriptsCmd allows inspecting and managing the loaded scripts.
+type ScriptsCmd struct {
+	engine scripting.Engine
+	// onChange is called whenever scripts have been reloaded, enabled or
+	// disabled.
+	onChange func()
+}
+
+// NewScriptsCommand creates a ready-to-use scripts command.
+func NewScriptsCommand(engine scripting.Engine, onChange func()) *ScriptsCmd {
+	return &ScriptsCmd{
+		engine:   engine,
+		onChange: onChange,
+	}
+}
+
+// Execute runs the command piping its output into the supplied writer.
+func (cmd *ScriptsCmd) Execute(writer io.Writer, parameters []string) {
+	if len(parameters) == 0 {
+		cmd.printScripts(writer)
+		return
+	}
+
+	switch parameters[0] {
+	case "list", "ls":
+		cmd.printScripts(writer)
+	case "reload":
+		reloadError := cmd.engine.Reload()
+		cmd.onChange()
+		if reloadError != nil {
+			fmt.Fprintf(writer, "[red]Error reloading scripts:\n\t[red]%s\n", reloadError)
+			return
+		}
+		cmd.printScripts(writer)
+	case "errors":
+		cmd.printErrors(writer)
+	case "enable", "disable":
+		if len(parameters) != 2 {
+			fmt.Fprintln(writer, "[red]Usage: scripts <enable|disable> <name>")
+			return
+		}
+		cmd.setEnabled(writer, parameters[1], parameters[0] == "enable")
+	default:
+		fmt.Fprintf(writer, "[red]The subcommand '%s' does not exist\n", parameters[0])
+		cmd.PrintHelp(writer)
+	}
+}
+
+func (cmd *ScriptsCmd) printScripts(writer io.Writer) {
+	scripts := cmd.engine.GetScripts()
+	if len(scripts) == 0 {
+		fmt.Fprintf(writer, "There are no scripts in '%s'.\n", config.GetScriptDirectory())
+		return
+	}
+
+	for _, script := range scripts {
+		if !script.Enabled {
+			fmt.Fprintf(writer, "[gray]disabled[white] %s\n", script.Name)
+		} else if script.Error != nil {
+			fmt.Fprintf(writer, "[red]failed[white]   %s\n", script.Name)
+		} else {
+			fmt.Fprintf(writer, "[green]active[white]   %s\n", script.Name)
+		}
+	}
+}
+
+func (cmd *ScriptsCmd) printErrors(writer io.Writer) {
+	var foundErrors bool
+	for _, script := range cmd.engine.GetScripts() {
+		if script.Error != nil {
+			foundErrors = true
+			fmt.Fprintf(writer, "[red]%s:\n\t[red]%s\n", script.Name, script.Error)
+		}
+	}
+
+	if !foundErrors {
+		fmt.Fprintln(writer, "All scripts have been loaded successfully.")
+	}
+}
+
+func (cmd *ScriptsCmd) setEnabled(writer io.Writer, name string, enabled bool) {
+	var script *scripting.ScriptStatus
+	for _, status := range cmd.engine.GetScripts() {
+		if status.Name == name {
+			script = status
+			break
+		}
+	}
+
+	if script == nil {
+		fmt.Fprintf(writer, "[red]The script '%s' couldn't be found.\n", name)
+		return
+	}
+
+	cmd.engine.SetScriptEnabled(name, enabled)
+	cmd.onChange()
+
+	disabledScripts := make([]string, 0, len(config.GetConfig().DisabledScripts))
+	for _, disabledScript := range config.GetConfig().DisabledScripts {
+		if disabledScript != name {
+			disabledScripts = append(disabledScripts, disabledScript)
+		}
+	}
+	if !enabled {
+		disabledScripts = append(disabledScripts, name)
+	}
+	config.GetConfig().DisabledScripts = disabledScripts
+
+	persistError := config.PersistConfig()
+	if persistError != nil {
+		fmt.Fprintf(writer, "[red]Error saving configuration:\n\t[red]%s\n", persistError)
+		return
+	}
+
+	if !enabled {
+		fmt.Fprintf(writer, "The script '%s' has been disabled.\n", name)
+		return
+	}
+
+	for _, status := range cmd.engine.GetScripts() {
+		if status.Name == name && status.Error != nil {
+			fmt.Fprintf(writer, "[red]The script '%s' has been enabled, but failed to load:\n\t[red]%s\n", name, status.Error)
+			return
+		}
+	}
+	fmt.Fprintf(writer, "The script '%s' has been enabled.\n", name)
+}
+
+// Complete offers the subcommands and the names of all scripts.
+func (cmd *ScriptsCmd) Complete(parameters []string, index int) []string {
+	if index == 0 {
+		return []string{"list", "reload", "errors", "enable", "disable"}
+	}
+
+	if index == 1 && (parameters[0] == "enable" || parameters[0] == "disable") {
+		var names []string
+		for _, script := range cmd.engine.GetScripts() {
+			names = append(names, script.Name)
+		}
+		return names
+	}
+
+	return nil
+}
+
+// Name returns the primary name for this command. This name will also be
+// used for listing the command in the commandlist.
+func (cmd *ScriptsCmd) Name() string {
+	return "scripts"
+}
+
+// Aliases are a list of aliases for this command. There might be none.
+func (cmd *ScriptsCmd) Aliases() []string {
+	return []string{"script"}
+}
+
+// PrintHelp prints a static help page for this command
+func (cmd *ScriptsCmd) PrintHelp(writer io.Writer) {
+	fmt.Fprintln(writer, scriptsHelpPage)
+}
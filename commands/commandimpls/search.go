@@ -0,0 +1,421 @@
+package commandimpls
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/Bios-Marcel/cordless/commands"
+	"github.com/Bios-Marcel/cordless/discordutil"
+	"github.com/Bios-Marcel/cordless/ui"
+	"github.com/Bios-Marcel/discordgo"
+	"github.com/Bios-Marcel/tview"
+)
+
+const searchHelpPage = `[::b]NAME
+	search - searches for messages and jumps to them
+
+[::b]SYNPOSIS
+	[::b]search[::-] [OPTION[]... <query>...
+	[::b]search jump[::-] <N>
+	[::b]search cancel[::-]
+
+[::b]DESCRIPTION
+	This command searches all messages that cordless has already loaded and
+	lists the results, the newest one being the first. Each word of the
+	query has to be part of the message, ignoring the case. Additionally
+	the following filters can be used:
+
+	[::b]from:<user>
+		only messages sent by the user, either 'name', 'name#1234' or an ID
+	[::b]in:<channel>
+		only messages in the channel, either '#name', a DM partner or an ID
+	[::b]has:attachment
+		only messages with at least one attachment, 'has:embed' works as well
+	[::b]before:<YYYY-MM-DD>
+		only messages sent before the given day
+	[::b]after:<YYYY-MM-DD>
+		only messages sent after the given day
+
+	Older messages can be requested from Discord by using the pages option.
+	This searches the channels matching the in: filter or the currently
+	loaded channel if there is no such filter. The messages are requested
+	in the background and the results are listed once all pages have been
+	searched. Only one such search can run at a time. In order to search
+	for the words "jump" or "cancel", write 'search -- jump'.
+
+[::b]SUBCOMMANDS
+	[::b]jump <N>
+		loads the channel of the N-th result of the last search and selects
+		the message
+	[::b]cancel
+		stops the search for older messages that is currently running
+
+%s
+
+[::b]EXAMPLES
+	[gray]$ search from:Marcel#1234 in:#general release
+	[gray]$ search --pages 5 has:attachment after:2019-09-01
+	[gray]$ search jump 3`
+
+var searchFlags = &commands.FlagSet{
+	Flags: []*commands.Flag{
+		{
+			Short:       "p",
+			Long:        "pages",
+			Type:        commands.IntFlag,
+			ValueName:   "N",
+			Description: "additionally requests up to N pages of 100 older messages per channel",
+		}, {
+			Short:       "l",
+			Long:        "limit",
+			Type:        commands.IntFlag,
+			ValueName:   "N",
+			Description: "lists at most N results, the default is 25",
+		},
+	},
+}
+
+const defaultSearchLimit = 25
+
+var errSearchCancelled = errors.New("search cancelled")
+
+// SearchCmd searches for messages and allows jumping to the results.
+type SearchCmd struct {
+	window  *ui.Window
+	session *discordgo.Session
+
+	mutex   *sync.Mutex
+	results []*discordgo.Message
+	cancel  chan struct{}
+}
+
+// NewSearchCommand creates a ready-to-use search command.
+func NewSearchCommand(window *ui.Window, session *discordgo.Session) *SearchCmd {
+	return &SearchCmd{
+		window:  window,
+		session: session,
+		mutex:   &sync.Mutex{},
+	}
+}
+
+// Execute runs the command piping its output into the supplied writer.
+func (cmd *SearchCmd) Execute(writer io.Writer, parameters []string) {
+	if len(parameters) == 0 {
+		fmt.Fprintln(writer, "[red]Usage: search [OPTION[]... <query>... | search jump <N> | search cancel")
+		return
+	}
+
+	if parameters[0] == "jump" {
+		cmd.jump(writer, parameters[1:])
+		return
+	}
+
+	if parameters[0] == "cancel" {
+		cmd.cancelSearch(writer)
+		return
+	}
+
+	flags, parseError := searchFlags.Parse(parameters)
+	var query *discordutil.SearchQuery
+	if parseError == nil {
+		query, parseError = discordutil.ParseSearchQuery(flags.Arguments)
+	}
+	if parseError == nil && (flags.Int("pages") < 0 || flags.Int("limit") < 0) {
+		parseError = errors.New("pages and limit can't be negative")
+	}
+	if parseError != nil {
+		fmt.Fprintf(writer, "[red]Error parsing parameters:\n\t[red]%s\n", parseError)
+		return
+	}
+
+	channels := cmd.findChannels(query)
+	results := make([]*discordgo.Message, 0)
+	for _, channel := range channels {
+		for _, message := range channel.Messages {
+			if query.Matches(message) {
+				results = append(results, message)
+			}
+		}
+	}
+
+	limit := defaultSearchLimit
+	if flags.IsSet("limit") {
+		limit = flags.Int("limit")
+	}
+
+	pages := flags.Int("pages")
+	if pages == 0 {
+		cmd.printResults(writer, results, limit)
+		return
+	}
+
+	remoteChannels := channels
+	if query.In == "" {
+		selectedChannel := cmd.window.GetSelectedChannel()
+		if selectedChannel == nil {
+			fmt.Fprintln(writer, "[red]No channel is loaded, use in:<channel> in order to request older messages.")
+			return
+		}
+		remoteChannels = []*discordgo.Channel{selectedChannel}
+	}
+
+	cmd.mutex.Lock()
+	defer cmd.mutex.Unlock()
+	if cmd.cancel != nil {
+		fmt.Fprintln(writer, "[red]A search for older messages is already running, use 'search cancel' in order to stop it.")
+		return
+	}
+	cancel := make(chan struct{})
+	cmd.cancel = cancel
+
+	fmt.Fprintln(writer, "Requesting older messages in the background, use 'search cancel' in order to stop.")
+	// Requesting the pages takes a while, therefore the results are printed
+	// via the background output once the search is done.
+	go func() {
+		output := cmd.window.GetBackgroundOutput()
+		defer cmd.finishSearch(cancel)
+
+		for _, channel := range remoteChannels {
+			remoteResults, discordError := cmd.searchOlderMessages(output, cancel, channel, query, pages)
+			if discordError == errSearchCancelled {
+				fmt.Fprintln(output, "The search has been cancelled.")
+				return
+			}
+			if discordError != nil {
+				fmt.Fprintf(output, "[red]Error requesting messages for channel '%s':\n\t[red]%s\n",
+					tview.Escape(channel.Name), discordError)
+			}
+			results = append(results, remoteResults...)
+		}
+
+		cmd.printResults(output, results, limit)
+	}()
+}
+
+// finishSearch allows starting a new search for older messages, unless the
+// given search has already been cancelled.
+func (cmd *SearchCmd) finishSearch(cancel chan struct{}) {
+	cmd.mutex.Lock()
+	if cmd.cancel == cancel {
+		cmd.cancel = nil
+	}
+	cmd.mutex.Unlock()
+}
+
+func (cmd *SearchCmd) cancelSearch(writer io.Writer) {
+	cmd.mutex.Lock()
+	defer cmd.mutex.Unlock()
+	if cmd.cancel == nil {
+		fmt.Fprintln(writer, "[red]There's no search for older messages running.")
+		return
+	}
+
+	close(cmd.cancel)
+	cmd.cancel = nil
+}
+
+// printResults lists the newest results up to the given limit and remembers
+// them for the jump subcommand.
+func (cmd *SearchCmd) printResults(writer io.Writer, results []*discordgo.Message, limit int) {
+	discordutil.SortMessagesByTimestamp(results)
+	// Newest results first
+	for left, right := 0, len(results)-1; left < right; left, right = left+1, right-1 {
+		results[left], results[right] = results[right], results[left]
+	}
+
+	if limit > 0 && len(results) > limit {
+		fmt.Fprintf(writer, "Found %d messages, showing the newest %d.\n", len(results), limit)
+		results = results[:limit]
+	}
+
+	cmd.mutex.Lock()
+	cmd.results = results
+	cmd.mutex.Unlock()
+
+	if len(results) == 0 {
+		fmt.Fprintln(writer, "No messages found.")
+		return
+	}
+
+	for index, message := range results {
+		fmt.Fprintf(writer, "%3d  %s\n", index+1, cmd.formatResult(message))
+	}
+	fmt.Fprintln(writer, "[gray]Use 'search jump <N>' in order to jump to a message.")
+}
+
+func (cmd *SearchCmd) jump(writer io.Writer, parameters []string) {
+	if len(parameters) != 1 {
+		fmt.Fprintln(writer, "[red]Usage: search jump <N>")
+		return
+	}
+
+	cmd.mutex.Lock()
+	results := cmd.results
+	cmd.mutex.Unlock()
+
+	number, parseError := strconv.Atoi(parameters[0])
+	if parseError != nil || number < 1 || number > len(results) {
+		fmt.Fprintf(writer, "[red]'%s' isn't the number of a result of the last search.\n", tview.Escape(parameters[0]))
+		return
+	}
+
+	message := results[number-1]
+	jumpError := cmd.window.JumpToMessage(message.ChannelID, message.ID)
+	if jumpError != nil {
+		fmt.Fprintf(writer, "[red]Error jumping to message:\n\t[red]%s\n", jumpError)
+	}
+}
+
+// findChannels returns all channels that the user is allowed to read and
+// that pass the in: filter of the query.
+func (cmd *SearchCmd) findChannels(query *discordutil.SearchQuery) []*discordgo.Channel {
+	state := cmd.session.State
+	channels := make([]*discordgo.Channel, 0)
+	for _, guild := range state.Guilds {
+		for _, channel := range guild.Channels {
+			if channel.Type == discordgo.ChannelTypeGuildText && query.MatchesChannel(channel) &&
+				discordutil.HasReadMessagesPermission(channel.ID, state) {
+				channels = append(channels, channel)
+			}
+		}
+	}
+
+	for _, channel := range state.PrivateChannels {
+		if query.MatchesChannel(channel) {
+			channels = append(channels, channel)
+		}
+	}
+
+	return channels
+}
+
+// searchOlderMessages requests up to the given amount of pages of messages
+// that are older than the oldest cached message of the channel and returns
+// the ones matching the query. The progress is printed after each page. If
+// the search gets cancelled, errSearchCancelled is returned.
+func (cmd *SearchCmd) searchOlderMessages(writer io.Writer, cancel chan struct{}, channel *discordgo.Channel, query *discordutil.SearchQuery, pages int) ([]*discordgo.Message, error) {
+	results := make([]*discordgo.Message, 0)
+	cmd.session.State.RLock()
+	before := oldestMessageID(channel.Messages)
+	cmd.session.State.RUnlock()
+	for page := 0; page < pages; page++ {
+		select {
+		case <-cancel:
+			return results, errSearchCancelled
+		default:
+		}
+
+		messages, discordError := cmd.session.ChannelMessages(channel.ID, 100, before, "", "")
+		if discordError != nil {
+			return results, discordError
+		}
+
+		for _, message := range messages {
+			message.GuildID = channel.GuildID
+			if query.Matches(message) {
+				results = append(results, message)
+			}
+		}
+
+		fmt.Fprintf(writer, "Searched page %d of %d in '%s'.\n", page+1, pages, tview.Escape(channel.Name))
+		if len(messages) < 100 {
+			break
+		}
+		// Discord returns the newest messages first.
+		before = messages[len(messages)-1].ID
+	}
+
+	return results, nil
+}
+
+// oldestMessageID returns the ID of the oldest message or an empty string
+// if there are no messages. Since IDs are snowflakes, the smallest ID
+// belongs to the oldest message.
+func oldestMessageID(messages []*discordgo.Message) string {
+	var oldestID string
+	var oldest uint64
+	for _, message := range messages {
+		id, parseError := strconv.ParseUint(message.ID, 10, 64)
+		if parseError == nil && (oldestID == "" || id < oldest) {
+			oldest = id
+			oldestID = message.ID
+		}
+	}
+
+	return oldestID
+}
+
+func (cmd *SearchCmd) formatResult(message *discordgo.Message) string {
+	var timeText string
+	timestamp, parseError := message.Timestamp.Parse()
+	if parseError == nil {
+		timeText = timestamp.Local().Format("2006-01-02 15:04")
+	}
+
+	var location string
+	channel, stateError := cmd.session.State.Channel(message.ChannelID)
+	if stateError != nil {
+		location = message.ChannelID
+	} else if channel.GuildID == "" {
+		location = discordutil.GetPrivateChannelName(channel)
+	} else {
+		location = "#" + tview.Escape(channel.Name)
+		guild, stateError := cmd.session.State.Guild(channel.GuildID)
+		if stateError == nil {
+			location += " (" + tview.Escape(guild.Name) + ")"
+		}
+	}
+
+	var author string
+	if message.Author != nil {
+		author = tview.Escape(message.Author.Username)
+	}
+
+	content := message.Content
+	if newLineIndex := strings.IndexRune(content, '\n'); newLineIndex != -1 {
+		content = content[:newLineIndex] + " ..."
+	}
+	if runes := []rune(content); len(runes) > 80 {
+		content = string(runes[:80]) + " ..."
+	}
+	content = tview.Escape(content)
+	for _, attachment := range message.Attachments {
+		content += " [gray]<" + tview.Escape(attachment.Filename) + ">[-]"
+	}
+
+	return fmt.Sprintf("[gray]%s %s[-] [::b]%s[::-]: %s", timeText, location, author, content)
+}
+
+// Complete offers the subcommands and the filters that don't require
+// a custom value.
+func (cmd *SearchCmd) Complete(parameters []string, index int) []string {
+	if index == 0 {
+		return []string{"jump", "cancel", "has:attachment", "has:embed"}
+	}
+
+	if parameters[0] == "jump" || parameters[0] == "cancel" {
+		return nil
+	}
+
+	return []string{"has:attachment", "has:embed"}
+}
+
+// Name returns the primary name for this command. This name will also be
+// used for listing the command in the commandlist.
+func (cmd *SearchCmd) Name() string {
+	return "search"
+}
+
+// Aliases are a list of aliases for this command. There might be none.
+func (cmd *SearchCmd) Aliases() []string {
+	return nil
+}
+
+// PrintHelp prints a static help page for this command
+func (cmd *SearchCmd) PrintHelp(writer io.Writer) {
+	fmt.Fprintf(writer, searchHelpPage+"\n", searchFlags.OptionsHelp())
+}
@@ -0,0 +1,370 @@
+package commandimpls
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Bios-Marcel/cordless/commands"
+	"github.com/Bios-Marcel/cordless/discordutil"
+	"github.com/Bios-Marcel/cordless/ui"
+	"github.com/Bios-Marcel/discordgo"
+	"github.com/Bios-Marcel/tview"
+)
+
+const serverChannelHelpPage = `[::b]NAME
+	server-channel - allows managing the channels of a server
+
+[::b]SYNPOSIS
+	[::b]server-channel create[::-] [OPTION[]... <name>
+	[::b]server-channel rename[::-] [OPTION[]... <channel> <name>
+	[::b]server-channel topic[::-] [OPTION[]... <channel> [topic[]
+	[::b]server-channel move[::-] [OPTION[]... <channel> <position>
+	[::b]server-channel delete[::-] [OPTION[]... <channel>
+
+[::b]DESCRIPTION
+	This command allows managing the channels of the loaded server or the
+	server chosen via --server. Channels can be passed by their name, with
+	or without a leading '#', or their ID. Each subcommand requires the
+	permission to manage the channel, reordering channels requires the
+	permission to manage all channels of the server.
+
+[::b]SUBCOMMANDS
+	[::b]create <name>
+		creates a new text channel, or voice channel if --voice is given
+	[::b]rename <channel> <name>
+		changes the name of the channel
+	[::b]topic <channel> [topic[]
+		changes the topic of the channel or removes it if none is given
+	[::b]move <channel> <position>
+		moves the channel to the given position, 1 being the top of its
+		category
+	[::b]delete <channel>
+		deletes the channel after asking for confirmation
+
+%s
+
+[::b]EXAMPLES
+	[gray]$ server-channel create --category Development --topic "Let's talk Go" golang
+	[gray]$ server-channel rename golang go
+	[gray]$ server-channel topic #go "Everything about Go"
+	[gray]$ server-channel move #go 1
+	[gray]$ server-channel delete --server "Discord Gophers" #go`
+
+var serverChannelFlags = &commands.FlagSet{
+	Flags: []*commands.Flag{
+		serverFlag,
+		{
+			Short:       "c",
+			Long:        "category",
+			Type:        commands.StringFlag,
+			ValueName:   "category",
+			Description: "the category to create the channel in, only used by create",
+		}, {
+			Short:       "t",
+			Long:        "topic",
+			Type:        commands.StringFlag,
+			ValueName:   "topic",
+			Description: "the topic of the new channel, only used by create",
+		}, {
+			Long:        "voice",
+			Description: "creates a voice channel instead of a text channel, only used by create",
+		},
+	},
+}
+
+var serverChannelSubcommands = []string{"create", "rename", "topic", "move", "delete"}
+
+// ServerChannelCmd allows creating, editing, moving and deleting channels.
+type ServerChannelCmd struct {
+	window  *ui.Window
+	session *discordgo.Session
+}
+
+// NewServerChannelCommand creates a ready-to-use server-channel command.
+func NewServerChannelCommand(window *ui.Window, session *discordgo.Session) *ServerChannelCmd {
+	return &ServerChannelCmd{window, session}
+}
+
+// Execute runs the command piping its output into the supplied writer.
+func (cmd *ServerChannelCmd) Execute(writer io.Writer, parameters []string) {
+	if len(parameters) == 0 {
+		cmd.PrintHelp(writer)
+		return
+	}
+
+	subcommand := parameters[0]
+	flags, parseError := serverChannelFlags.Parse(parameters[1:])
+	if parseError == nil && subcommand != "create" &&
+		(flags.IsSet("category") || flags.IsSet("topic") || flags.IsSet("voice")) {
+		parseError = errors.New("the options --category, --topic and --voice can only be used with create")
+	}
+	if parseError != nil {
+		fmt.Fprintf(writer, "[red]Error parsing parameters:\n\t[red]%s\n", parseError)
+		return
+	}
+
+	guild, guildError := findServer(cmd.window, cmd.session.State, flags.String("server"))
+	if guildError != nil {
+		fmt.Fprintf(writer, "[red]Error choosing server:\n\t[red]%s\n", guildError)
+		return
+	}
+
+	arguments := flags.Arguments
+	switch subcommand {
+	case "create", "add":
+		if len(arguments) != 1 {
+			fmt.Fprintln(writer, "[red]Usage: server-channel create [OPTION[]... <name>")
+			return
+		}
+		cmd.create(writer, guild, arguments[0], flags)
+	case "rename":
+		if len(arguments) != 2 {
+			fmt.Fprintln(writer, "[red]Usage: server-channel rename [OPTION[]... <channel> <name>")
+			return
+		}
+		cmd.edit(writer, guild, arguments[0], map[string]interface{}{"name": arguments[1]})
+	case "topic":
+		if len(arguments) < 1 {
+			fmt.Fprintln(writer, "[red]Usage: server-channel topic [OPTION[]... <channel> [topic[]")
+			return
+		}
+		cmd.edit(writer, guild, arguments[0], map[string]interface{}{"topic": strings.Join(arguments[1:], " ")})
+	case "move":
+		if len(arguments) != 2 {
+			fmt.Fprintln(writer, "[red]Usage: server-channel move [OPTION[]... <channel> <position>")
+			return
+		}
+		cmd.move(writer, guild, arguments[0], arguments[1])
+	case "delete", "remove", "rm":
+		if len(arguments) != 1 {
+			fmt.Fprintln(writer, "[red]Usage: server-channel delete [OPTION[]... <channel>")
+			return
+		}
+		cmd.delete(writer, guild, arguments[0])
+	default:
+		fmt.Fprintf(writer, "[red]The subcommand '%s' doesn't exist.\n", tview.Escape(subcommand))
+	}
+}
+
+func (cmd *ServerChannelCmd) create(writer io.Writer, guild *discordgo.Guild, name string, flags *commands.ParsedFlags) {
+	data := discordgo.GuildChannelCreateData{
+		Name:  name,
+		Type:  discordgo.ChannelTypeGuildText,
+		Topic: flags.String("topic"),
+	}
+	if flags.Bool("voice") {
+		data.Type = discordgo.ChannelTypeGuildVoice
+	}
+
+	if flags.IsSet("category") {
+		category, findError := findGuildChannel(guild, flags.String("category"))
+		if findError == nil && category.Type != discordgo.ChannelTypeGuildCategory {
+			findError = fmt.Errorf("'%s' isn't a category", category.Name)
+		}
+		if findError != nil {
+			fmt.Fprintf(writer, "[red]Error choosing category:\n\t[red]%s\n", tview.Escape(findError.Error()))
+			return
+		}
+
+		if !discordutil.HasChannelPermission(category.ID, discordgo.PermissionManageChannels, cmd.session.State) {
+			fmt.Fprintf(writer, "[red]You aren't allowed to manage the channels of the category '%s'.\n", tview.Escape(category.Name))
+			return
+		}
+		data.ParentID = category.ID
+	} else if !discordutil.HasGuildPermission(guild.ID, discordgo.PermissionManageChannels, cmd.session.State) {
+		fmt.Fprintf(writer, "[red]You aren't allowed to manage the channels of the server '%s'.\n", tview.Escape(guild.Name))
+		return
+	}
+
+	channel, createError := cmd.session.GuildChannelCreateComplex(guild.ID, data)
+	if createError != nil {
+		fmt.Fprintf(writer, "[red]Error creating channel:\n\t[red]%s\n", createError)
+		return
+	}
+	fmt.Fprintf(writer, "Created channel '%s'.\n", tview.Escape(channel.Name))
+}
+
+// edit changes the given fields of a channel. ChannelEditComplex can't be
+// used, since it always sends the position and therefore moves the channel.
+func (cmd *ServerChannelCmd) edit(writer io.Writer, guild *discordgo.Guild, nameOrID string, data map[string]interface{}) {
+	channel, findError := findGuildChannel(guild, nameOrID)
+	if findError != nil {
+		fmt.Fprintf(writer, "[red]Error choosing channel:\n\t[red]%s\n", tview.Escape(findError.Error()))
+		return
+	}
+
+	if _, isTopic := data["topic"]; isTopic && channel.Type != discordgo.ChannelTypeGuildText {
+		fmt.Fprintf(writer, "[red]Only text channels have a topic, but '%s' isn't one.\n", tview.Escape(channel.Name))
+		return
+	}
+
+	if !discordutil.HasChannelPermission(channel.ID, discordgo.PermissionManageChannels, cmd.session.State) {
+		fmt.Fprintf(writer, "[red]You aren't allowed to manage the channel '%s'.\n", tview.Escape(channel.Name))
+		return
+	}
+
+	endpoint := discordgo.EndpointChannel(channel.ID)
+	_, editError := cmd.session.RequestWithBucketID("PATCH", endpoint, data, endpoint)
+	if editError != nil {
+		fmt.Fprintf(writer, "[red]Error editing channel:\n\t[red]%s\n", editError)
+		return
+	}
+	fmt.Fprintf(writer, "Updated channel '%s'.\n", tview.Escape(channel.Name))
+}
+
+func (cmd *ServerChannelCmd) move(writer io.Writer, guild *discordgo.Guild, nameOrID, positionText string) {
+	channel, findError := findGuildChannel(guild, nameOrID)
+	if findError != nil {
+		fmt.Fprintf(writer, "[red]Error choosing channel:\n\t[red]%s\n", tview.Escape(findError.Error()))
+		return
+	}
+
+	position, parseError := strconv.Atoi(positionText)
+	if parseError != nil || position < 1 {
+		fmt.Fprintf(writer, "[red]The position has to be a number greater than 0, but was '%s'.\n", tview.Escape(positionText))
+		return
+	}
+
+	if !discordutil.HasGuildPermission(guild.ID, discordgo.PermissionManageChannels, cmd.session.State) {
+		fmt.Fprintf(writer, "[red]You aren't allowed to manage the channels of the server '%s'.\n", tview.Escape(guild.Name))
+		return
+	}
+
+	reorderError := cmd.session.GuildChannelsReorder(guild.ID, reorderChannels(guild.Channels, channel, position-1))
+	if reorderError != nil {
+		fmt.Fprintf(writer, "[red]Error moving channel:\n\t[red]%s\n", reorderError)
+		return
+	}
+	fmt.Fprintf(writer, "Moved channel '%s'.\n", tview.Escape(channel.Name))
+}
+
+// reorderChannels moves the channel to the given index among the channels
+// that share its category and kind. The result only contains these
+// channels with their new positions. The previously used positions are
+// kept where possible, so that the order relative to other channels stays
+// the same.
+func reorderChannels(channels []*discordgo.Channel, channel *discordgo.Channel, index int) []*discordgo.Channel {
+	isVoice := channel.Type == discordgo.ChannelTypeGuildVoice
+	isCategory := channel.Type == discordgo.ChannelTypeGuildCategory
+	siblings := make([]*discordgo.Channel, 0)
+	for _, sibling := range channels {
+		if sibling.ID != channel.ID && sibling.ParentID == channel.ParentID &&
+			(sibling.Type == discordgo.ChannelTypeGuildVoice) == isVoice &&
+			(sibling.Type == discordgo.ChannelTypeGuildCategory) == isCategory {
+			siblings = append(siblings, sibling)
+		}
+	}
+	sort.Slice(siblings, func(a, b int) bool {
+		return siblings[a].Position < siblings[b].Position
+	})
+
+	positions := make([]int, 0, len(siblings)+1)
+	for _, sibling := range siblings {
+		positions = append(positions, sibling.Position)
+	}
+	positions = append(positions, channel.Position)
+	sort.Ints(positions)
+
+	if index > len(siblings) {
+		index = len(siblings)
+	}
+	ordered := append(siblings[:index:index], channel)
+	ordered = append(ordered, siblings[index:]...)
+
+	reordered := make([]*discordgo.Channel, 0, len(ordered))
+	for orderIndex, orderedChannel := range ordered {
+		position := positions[orderIndex]
+		if orderIndex > 0 && position <= reordered[orderIndex-1].Position {
+			position = reordered[orderIndex-1].Position + 1
+		}
+		reordered = append(reordered, &discordgo.Channel{ID: orderedChannel.ID, Position: position})
+	}
+
+	return reordered
+}
+
+func (cmd *ServerChannelCmd) delete(writer io.Writer, guild *discordgo.Guild, nameOrID string) {
+	channel, findError := findGuildChannel(guild, nameOrID)
+	if findError != nil {
+		fmt.Fprintf(writer, "[red]Error choosing channel:\n\t[red]%s\n", tview.Escape(findError.Error()))
+		return
+	}
+
+	if !discordutil.HasChannelPermission(channel.ID, discordgo.PermissionManageChannels, cmd.session.State) {
+		fmt.Fprintf(writer, "[red]You aren't allowed to manage the channel '%s'.\n", tview.Escape(channel.Name))
+		return
+	}
+
+	deleteButtonText := "Delete"
+	cmd.window.ShowDialog(tview.Styles.PrimitiveBackgroundColor,
+		fmt.Sprintf("Do you really want to delete the channel '%s' of the server '%s'?",
+			tview.Escape(channel.Name), tview.Escape(guild.Name)),
+		func(button string) {
+			if button != deleteButtonText {
+				return
+			}
+
+			//Execute has returned by now, so the deletion happens in the
+			//background and prints into the command view.
+			go func(writer io.Writer) {
+				_, deleteError := cmd.session.ChannelDelete(channel.ID)
+				if deleteError != nil {
+					fmt.Fprintf(writer, "[red]Error deleting channel:\n\t[red]%s\n", deleteError)
+					return
+				}
+				fmt.Fprintf(writer, "Deleted channel '%s'.\n", tview.Escape(channel.Name))
+			}(cmd.window.GetBackgroundOutput())
+		}, deleteButtonText, "Abort")
+}
+
+// findGuildChannel looks up a channel of any type in the given guild by its
+// ID or name.
+func findGuildChannel(guild *discordgo.Guild, nameOrID string) (*discordgo.Channel, error) {
+	var match *discordgo.Channel
+	for _, channel := range guild.Channels {
+		if channel.ID == nameOrID {
+			return channel, nil
+		}
+
+		if discordutil.ChannelMatches(channel, nameOrID) {
+			if match != nil {
+				return nil, fmt.Errorf("there are multiple channels called '%s', use the ID instead", nameOrID)
+			}
+			match = channel
+		}
+	}
+
+	if match == nil {
+		return nil, fmt.Errorf("no channel with the ID or name '%s' was found", nameOrID)
+	}
+	return match, nil
+}
+
+// Complete offers the subcommands.
+func (cmd *ServerChannelCmd) Complete(parameters []string, index int) []string {
+	if index == 0 {
+		return serverChannelSubcommands
+	}
+
+	return nil
+}
+
+// Name returns the primary name for this command. This name will also be
+// used for listing the command in the commandlist.
+func (cmd *ServerChannelCmd) Name() string {
+	return "server-channel"
+}
+
+// Aliases are a list of aliases for this command. There might be none.
+func (cmd *ServerChannelCmd) Aliases() []string {
+	return []string{"guild-channel", "server-channels", "guild-channels"}
+}
+
+// PrintHelp prints a static help page for this command
+func (cmd *ServerChannelCmd) PrintHelp(writer io.Writer) {
+	fmt.Fprintf(writer, serverChannelHelpPage+"\n", serverChannelFlags.OptionsHelp())
+}
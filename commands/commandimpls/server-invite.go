@@ -0,0 +1,166 @@
+package commandimpls
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/Bios-Marcel/cordless/commands"
+	"github.com/Bios-Marcel/cordless/discordutil"
+	"github.com/Bios-Marcel/cordless/ui"
+	"github.com/Bios-Marcel/discordgo"
+	"github.com/Bios-Marcel/tview"
+)
+
+const serverInviteHelpPage = `[::b]NAME
+	server-invite - creates an invitation for a server
+
+[::b]SYNPOSIS
+	[::b]server-invite[::-] [OPTION[]...
+
+[::b]DESCRIPTION
+	This command creates an invitation for a channel of the loaded server
+	or the server chosen via --server and prints its link. By default the
+	invitation is for the loaded channel, expires after a day and can be
+	used any number of times. Creating invitations requires the permission
+	to do so in the channel.
+
+%s
+
+[::b]EXAMPLES
+	[gray]$ server-invite
+	[gray]$ server-invite --channel #welcome --max-age 1h --max-uses 5
+	[gray]$ server-invite --max-age never --temporary`
+
+var serverInviteFlags = &commands.FlagSet{
+	Flags: []*commands.Flag{
+		serverFlag,
+		{
+			Short:       "c",
+			Long:        "channel",
+			Type:        commands.StringFlag,
+			ValueName:   "channel",
+			Description: "the channel that the invitation leads to; defaults to the loaded channel",
+		}, {
+			Short:       "a",
+			Long:        "max-age",
+			Type:        commands.StringFlag,
+			ValueName:   "duration",
+			Description: "the time until the invitation expires, for example 30m, 12h or never; defaults to 24h",
+		}, {
+			Short:       "u",
+			Long:        "max-uses",
+			Type:        commands.IntFlag,
+			ValueName:   "N",
+			Description: "how often the invitation can be used; defaults to 0, meaning no limit",
+		}, {
+			Short:       "t",
+			Long:        "temporary",
+			Description: "kicks members that joined via the invitation once they disconnect, unless they got a role",
+		},
+	},
+}
+
+// ServerInviteCmd creates invitations for a server.
+type ServerInviteCmd struct {
+	window  *ui.Window
+	session *discordgo.Session
+}
+
+// NewServerInviteCommand creates a ready-to-use server-invite command.
+func NewServerInviteCommand(window *ui.Window, session *discordgo.Session) *ServerInviteCmd {
+	return &ServerInviteCmd{window, session}
+}
+
+// Execute runs the command piping its output into the supplied writer.
+func (cmd *ServerInviteCmd) Execute(writer io.Writer, parameters []string) {
+	flags, parseError := serverInviteFlags.Parse(parameters)
+	if parseError == nil && len(flags.Arguments) != 0 {
+		parseError = fmt.Errorf("unexpected parameter '%s'", flags.Arguments[0])
+	}
+	if parseError == nil && flags.Int("max-uses") < 0 {
+		parseError = errors.New("the maximum amount of uses can't be negative")
+	}
+
+	maxAge := 24 * time.Hour
+	if parseError == nil && flags.IsSet("max-age") {
+		if value := flags.String("max-age"); value == "never" {
+			maxAge = 0
+		} else {
+			maxAge, parseError = time.ParseDuration(value)
+			if parseError == nil && maxAge < 0 {
+				parseError = errors.New("the maximum age can't be negative")
+			}
+		}
+	}
+
+	if parseError != nil {
+		fmt.Fprintf(writer, "[red]Error parsing parameters:\n\t[red]%s\n", tview.Escape(parseError.Error()))
+		return
+	}
+
+	guild, guildError := findServer(cmd.window, cmd.session.State, flags.String("server"))
+	if guildError != nil {
+		fmt.Fprintf(writer, "[red]Error choosing server:\n\t[red]%s\n", guildError)
+		return
+	}
+
+	var channel *discordgo.Channel
+	if flags.IsSet("channel") {
+		var findError error
+		channel, findError = findGuildChannel(guild, flags.String("channel"))
+		if findError != nil {
+			fmt.Fprintf(writer, "[red]Error choosing channel:\n\t[red]%s\n", tview.Escape(findError.Error()))
+			return
+		}
+	} else {
+		channel = cmd.window.GetSelectedChannel()
+		if channel == nil || channel.GuildID != guild.ID {
+			fmt.Fprintln(writer, "[red]No channel of the server is loaded, use --channel in order to choose one.")
+			return
+		}
+	}
+
+	if !discordutil.HasChannelPermission(channel.ID, discordgo.PermissionCreateInstantInvite, cmd.session.State) {
+		fmt.Fprintf(writer, "[red]You aren't allowed to create invitations for the channel '%s'.\n", tview.Escape(channel.Name))
+		return
+	}
+
+	invite, inviteError := cmd.session.ChannelInviteCreate(channel.ID, discordgo.Invite{
+		MaxAge:    int(maxAge / time.Second),
+		MaxUses:   flags.Int("max-uses"),
+		Temporary: flags.Bool("temporary"),
+	})
+	if inviteError != nil {
+		fmt.Fprintf(writer, "[red]Error creating invitation:\n\t[red]%s\n", inviteError)
+		return
+	}
+
+	expiry := "never expires"
+	if invite.MaxAge > 0 {
+		expiry = fmt.Sprintf("expires in %s", time.Duration(invite.MaxAge)*time.Second)
+	}
+	uses := "can be used any number of times"
+	if invite.MaxUses > 0 {
+		uses = fmt.Sprintf("can be used %d times", invite.MaxUses)
+	}
+	fmt.Fprintf(writer, "https://discord.gg/%s\n[gray]The invitation to '%s' %s and %s.\n",
+		invite.Code, tview.Escape(channel.Name), expiry, uses)
+}
+
+// Name returns the primary name for this command. This name will also be
+// used for listing the command in the commandlist.
+func (cmd *ServerInviteCmd) Name() string {
+	return "server-invite"
+}
+
+// Aliases are a list of aliases for this command. There might be none.
+func (cmd *ServerInviteCmd) Aliases() []string {
+	return []string{"guild-invite"}
+}
+
+// PrintHelp prints a static help page for this command
+func (cmd *ServerInviteCmd) PrintHelp(writer io.Writer) {
+	fmt.Fprintf(writer, serverInviteHelpPage+"\n", serverInviteFlags.OptionsHelp())
+}
@@ -0,0 +1,205 @@
+package commandimpls
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/Bios-Marcel/cordless/commands"
+	"github.com/Bios-Marcel/cordless/discordutil"
+	"github.com/Bios-Marcel/cordless/ui"
+	"github.com/Bios-Marcel/discordgo"
+	"github.com/Bios-Marcel/tview"
+)
+
+const serverMembersHelpPage = `[::b]NAME
+	server-members - lists the members of a server
+
+[::b]SYNPOSIS
+	[::b]server-members[::-] [OPTION[]... [filter[]
+
+[::b]DESCRIPTION
+	This command lists the members of the loaded server or the server
+	chosen via --server, together with their nicknames and roles. If a
+	filter is given, only members whose name or nickname contains it are
+	listed. Only members that have already been loaded are listed, which
+	is the case for all members of the loaded server.
+
+%s
+
+[::b]EXAMPLES
+	[gray]$ server-members
+	[gray]$ server-members --server "Discord Gophers" marcel`
+
+const serverRolesHelpPage = `[::b]NAME
+	server-roles - lists the roles of a server
+
+[::b]SYNPOSIS
+	[::b]server-roles[::-] [OPTION[]...
+
+[::b]DESCRIPTION
+	This command lists the roles of the loaded server or the server chosen
+	via --server, the highest role being the first. Each role is listed
+	with its ID and the amount of loaded members that have the role.
+
+%s
+
+[::b]EXAMPLES
+	[gray]$ server-roles
+	[gray]$ server-roles --server "Discord Gophers"`
+
+var serverListFlags = &commands.FlagSet{
+	Flags: []*commands.Flag{serverFlag},
+}
+
+// ServerMembersCmd lists the members of a server.
+type ServerMembersCmd struct {
+	window  *ui.Window
+	session *discordgo.Session
+}
+
+// ServerRolesCmd lists the roles of a server.
+type ServerRolesCmd struct {
+	window  *ui.Window
+	session *discordgo.Session
+}
+
+// NewServerMembersCommand creates a ready-to-use server-members command.
+func NewServerMembersCommand(window *ui.Window, session *discordgo.Session) *ServerMembersCmd {
+	return &ServerMembersCmd{window, session}
+}
+
+// NewServerRolesCommand creates a ready-to-use server-roles command.
+func NewServerRolesCommand(window *ui.Window, session *discordgo.Session) *ServerRolesCmd {
+	return &ServerRolesCmd{window, session}
+}
+
+// Execute runs the command piping its output into the supplied writer.
+func (cmd *ServerMembersCmd) Execute(writer io.Writer, parameters []string) {
+	flags, parseError := serverListFlags.Parse(parameters)
+	if parseError == nil && len(flags.Arguments) > 1 {
+		parseError = fmt.Errorf("unexpected parameter '%s'", flags.Arguments[1])
+	}
+	if parseError != nil {
+		fmt.Fprintf(writer, "[red]Error parsing parameters:\n\t[red]%s\n", tview.Escape(parseError.Error()))
+		return
+	}
+
+	guild, guildError := findServer(cmd.window, cmd.session.State, flags.String("server"))
+	if guildError != nil {
+		fmt.Fprintf(writer, "[red]Error choosing server:\n\t[red]%s\n", guildError)
+		return
+	}
+
+	var filter string
+	if len(flags.Arguments) == 1 {
+		filter = strings.ToLower(flags.Arguments[0])
+	}
+
+	members := make([]*discordgo.Member, 0, len(guild.Members))
+	for _, member := range guild.Members {
+		if filter == "" || strings.Contains(strings.ToLower(member.User.Username), filter) ||
+			strings.Contains(strings.ToLower(member.Nick), filter) {
+			members = append(members, member)
+		}
+	}
+	sort.Slice(members, func(a, b int) bool {
+		return strings.ToLower(members[a].User.Username) < strings.ToLower(members[b].User.Username)
+	})
+
+	for _, member := range members {
+		fmt.Fprintf(writer, "%s#%s", tview.Escape(member.User.Username), member.User.Discriminator)
+		if member.Nick != "" {
+			fmt.Fprintf(writer, " (%s)", tview.Escape(member.Nick))
+		}
+
+		roles := make([]string, len(member.Roles))
+		copy(roles, member.Roles)
+		discordutil.SortUserRoles(roles, guild.Roles)
+		roleNames := make([]string, 0, len(roles))
+		for _, roleID := range roles {
+			for _, role := range guild.Roles {
+				if role.ID == roleID {
+					roleNames = append(roleNames, tview.Escape(role.Name))
+					break
+				}
+			}
+		}
+		if len(roleNames) > 0 {
+			fmt.Fprintf(writer, " [gray]%s[-]", strings.Join(roleNames, ", "))
+		}
+		fmt.Fprintln(writer)
+	}
+	fmt.Fprintf(writer, "[gray]%d members listed.\n", len(members))
+}
+
+// Execute runs the command piping its output into the supplied writer.
+func (cmd *ServerRolesCmd) Execute(writer io.Writer, parameters []string) {
+	flags, parseError := serverListFlags.Parse(parameters)
+	if parseError == nil && len(flags.Arguments) != 0 {
+		parseError = fmt.Errorf("unexpected parameter '%s'", flags.Arguments[0])
+	}
+	if parseError != nil {
+		fmt.Fprintf(writer, "[red]Error parsing parameters:\n\t[red]%s\n", tview.Escape(parseError.Error()))
+		return
+	}
+
+	guild, guildError := findServer(cmd.window, cmd.session.State, flags.String("server"))
+	if guildError != nil {
+		fmt.Fprintf(writer, "[red]Error choosing server:\n\t[red]%s\n", guildError)
+		return
+	}
+
+	roles := make([]*discordgo.Role, len(guild.Roles))
+	copy(roles, guild.Roles)
+	sort.Slice(roles, func(a, b int) bool {
+		return roles[a].Position > roles[b].Position
+	})
+
+	for _, role := range roles {
+		var memberCount int
+		for _, member := range guild.Members {
+			for _, roleID := range member.Roles {
+				if roleID == role.ID {
+					memberCount++
+					break
+				}
+			}
+		}
+
+		fmt.Fprintf(writer, "%s [gray]%s, %d members[-]\n", tview.Escape(role.Name), role.ID, memberCount)
+	}
+}
+
+// Name returns the primary name for this command. This name will also be
+// used for listing the command in the commandlist.
+func (cmd *ServerMembersCmd) Name() string {
+	return "server-members"
+}
+
+// Name returns the primary name for this command. This name will also be
+// used for listing the command in the commandlist.
+func (cmd *ServerRolesCmd) Name() string {
+	return "server-roles"
+}
+
+// Aliases are a list of aliases for this command. There might be none.
+func (cmd *ServerMembersCmd) Aliases() []string {
+	return []string{"guild-members", "server-users", "guild-users"}
+}
+
+// Aliases are a list of aliases for this command. There might be none.
+func (cmd *ServerRolesCmd) Aliases() []string {
+	return []string{"guild-roles"}
+}
+
+// PrintHelp prints a static help page for this command
+func (cmd *ServerMembersCmd) PrintHelp(writer io.Writer) {
+	fmt.Fprintf(writer, serverMembersHelpPage+"\n", serverListFlags.OptionsHelp())
+}
+
+// PrintHelp prints a static help page for this command
+func (cmd *ServerRolesCmd) PrintHelp(writer io.Writer) {
+	fmt.Fprintf(writer, serverRolesHelpPage+"\n", serverListFlags.OptionsHelp())
+}
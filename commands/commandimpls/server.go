@@ -1,30 +1,41 @@
 package commandimpls
 
 import (
+	"errors"
 	"fmt"
 	"io"
 	"strings"
 
+	"github.com/Bios-Marcel/cordless/commands"
 	"github.com/Bios-Marcel/cordless/ui"
 	"github.com/Bios-Marcel/discordgo"
 )
 
 const serverHelpPage = `[::b]NAME
-	server - allows you to join or leave a server
+	server - allows you to join, leave and administrate servers
 
 [::b]SYNPOSIS
 	[::b]server[::-] <subcommand <args>>
 
 [::b]DESCRIPTION
 	The server command allows you to join a new server or leave one that you
-	are already a part of. What this command can't do is administrating a
-	server in any way.
+	are already a part of. Additionally it allows managing the channels and
+	invites of a server and listing its members and roles. Administrative
+	subcommands require the respective permissions on the server.
 
 [::]SUBCOMMANDS
 	[::b]server-join
 		joins the server using the given invitation
 	[::b]server-leave
-		leaves the given server`
+		leaves the given server
+	[::b]server-channel
+		creates, renames, deletes or reorders channels and edits topics
+	[::b]server-invite
+		creates an invitation for the server
+	[::b]server-members
+		lists the members of the server
+	[::b]server-roles
+		lists the roles of the server`
 
 const serverJoinHelpPage = `[::b]NAME
 	server-join - allows you to join a server
@@ -56,8 +67,12 @@ const serverLeaveHelpPage = `[::b]NAME
 	[gray]$ server-leave Nirvana`
 
 type ServerCmd struct {
-	serverJoinCmd  *ServerJoinCmd
-	serverLeaveCmd *ServerLeaveCmd
+	serverJoinCmd    *ServerJoinCmd
+	serverLeaveCmd   *ServerLeaveCmd
+	serverChannelCmd *ServerChannelCmd
+	serverInviteCmd  *ServerInviteCmd
+	serverMembersCmd *ServerMembersCmd
+	serverRolesCmd   *ServerRolesCmd
 }
 
 type ServerJoinCmd struct {
@@ -70,8 +85,11 @@ type ServerLeaveCmd struct {
 	session *discordgo.Session
 }
 
-func NewServerCommand(serverJoinCmd *ServerJoinCmd, serverLeaveCmd *ServerLeaveCmd) *ServerCmd {
-	return &ServerCmd{serverJoinCmd, serverLeaveCmd}
+func NewServerCommand(serverJoinCmd *ServerJoinCmd, serverLeaveCmd *ServerLeaveCmd,
+	serverChannelCmd *ServerChannelCmd, serverInviteCmd *ServerInviteCmd,
+	serverMembersCmd *ServerMembersCmd, serverRolesCmd *ServerRolesCmd) *ServerCmd {
+	return &ServerCmd{serverJoinCmd, serverLeaveCmd, serverChannelCmd,
+		serverInviteCmd, serverMembersCmd, serverRolesCmd}
 }
 
 func NewServerJoinCommand(window *ui.Window, session *discordgo.Session) *ServerJoinCmd {
@@ -97,6 +115,14 @@ func (cmd *ServerCmd) Execute(writer io.Writer, parameters []string) {
 		cmd.serverJoinCmd.Execute(writer, parameters[1:])
 	case "leave", "exit", "quit":
 		cmd.serverLeaveCmd.Execute(writer, parameters[1:])
+	case "channel", "channels":
+		cmd.serverChannelCmd.Execute(writer, parameters[1:])
+	case "invite":
+		cmd.serverInviteCmd.Execute(writer, parameters[1:])
+	case "members", "users":
+		cmd.serverMembersCmd.Execute(writer, parameters[1:])
+	case "roles":
+		cmd.serverRolesCmd.Execute(writer, parameters[1:])
 	default:
 		cmd.PrintHelp(writer)
 	}
@@ -188,3 +214,43 @@ func (cmd *ServerJoinCmd) Aliases() []string {
 func (cmd *ServerLeaveCmd) Aliases() []string {
 	return []string{"guild-leave", "guild-exit", "guild-quit", "server-exit", "server-quit"}
 }
+
+// serverFlag allows choosing a different server than the loaded one.
+var serverFlag = &commands.Flag{
+	Short:       "s",
+	Long:        "server",
+	Type:        commands.StringFlag,
+	ValueName:   "server",
+	Description: "the ID or name of the server; defaults to the loaded server",
+}
+
+// findServer returns the server with the given ID or name. If nameOrID is
+// empty, the currently loaded server is returned.
+func findServer(window *ui.Window, state *discordgo.State, nameOrID string) (*discordgo.Guild, error) {
+	if nameOrID == "" {
+		guild := window.GetSelectedGuild()
+		if guild == nil {
+			return nil, errors.New("no server is loaded, use --server in order to choose one")
+		}
+		return guild, nil
+	}
+
+	var match *discordgo.Guild
+	for _, guild := range state.Guilds {
+		if guild.ID == nameOrID {
+			return guild, nil
+		}
+
+		if guild.Name == nameOrID {
+			if match != nil {
+				return nil, fmt.Errorf("there are multiple servers called '%s', use the ID instead", nameOrID)
+			}
+			match = guild
+		}
+	}
+
+	if match == nil {
+		return nil, fmt.Errorf("no server with the ID or name '%s' was found", nameOrID)
+	}
+	return match, nil
+}
@@ -1,10 +1,12 @@
 package commandimpls
 
 import (
+	"errors"
 	"fmt"
 	"io"
 	"strings"
 
+	"github.com/Bios-Marcel/cordless/commands"
 	"github.com/Bios-Marcel/discordgo"
 )
 
@@ -59,6 +61,10 @@ const (
 	[red]Do not disturb`
 )
 
+// statusFlags is used by the status subcommands, which don't have any options,
+// in order to reject unknown options and to support "--".
+var statusFlags = &commands.FlagSet{}
+
 type StatusCmd struct {
 	statusGetCmd *StatusGetCmd
 	statusSetCmd *StatusSetCmd
@@ -105,18 +111,21 @@ func statusToString(status discordgo.Status) string {
 }
 
 func (cmd *StatusGetCmd) Execute(writer io.Writer, parameters []string) {
-	if len(parameters) > 1 {
-		fmt.Fprintln(writer, "[red]Invalid parameters")
-		cmd.PrintHelp(writer)
+	flags, parseError := statusFlags.Parse(parameters)
+	if parseError == nil && len(flags.Arguments) > 1 {
+		parseError = errors.New("at most one user can be given")
+	}
+	if parseError != nil {
+		fmt.Fprintf(writer, "[red]Error parsing parameters:\n\t[red]%s\n", parseError)
 		return
 	}
 
-	if len(parameters) == 0 {
+	if len(flags.Arguments) == 0 {
 		fmt.Fprintln(writer, statusToString(cmd.session.State.Settings.Status))
 		return
 	}
 
-	input := parameters[0]
+	input := flags.Arguments[0]
 	var matches []*discordgo.Presence
 	for _, presence := range cmd.session.State.Presences {
 		user := presence.User
@@ -139,16 +148,20 @@ func (cmd *StatusGetCmd) Execute(writer io.Writer, parameters []string) {
 }
 
 func (cmd *StatusSetCmd) Execute(writer io.Writer, parameters []string) {
-	if len(parameters) == 0 || len(parameters) > 1 {
-		fmt.Fprintln(writer, "[red]Invalid parameters")
-		cmd.PrintHelp(writer)
+	flags, parseError := statusFlags.Parse(parameters)
+	if parseError == nil && len(flags.Arguments) != 1 {
+		parseError = errors.New("exactly one status is required")
+	}
+	if parseError != nil {
+		fmt.Fprintf(writer, "[red]Error parsing parameters:\n\t[red]%s\n", parseError)
 		return
 	}
 
 	var settingStatusError error
 	var updatedSettings *discordgo.Settings
 
-	switch strings.ToLower(parameters[0]) {
+	status := strings.ToLower(flags.Arguments[0])
+	switch status {
 	case "online", "available":
 		updatedSettings, settingStatusError = cmd.session.UserUpdateStatus(discordgo.StatusOnline)
 	case "dnd", "donotdisturb", "busy":
@@ -158,7 +171,7 @@ func (cmd *StatusSetCmd) Execute(writer io.Writer, parameters []string) {
 	case "invisible":
 		updatedSettings, settingStatusError = cmd.session.UserUpdateStatus(discordgo.StatusInvisible)
 	default:
-		fmt.Fprintf(writer, "[red]Invalid status: '%s'\n", strings.ToLower(parameters[0]))
+		fmt.Fprintf(writer, "[red]Invalid status: '%s'\n", status)
 		cmd.PrintHelp(writer)
 	}
 
@@ -195,6 +208,30 @@ func (cmd *StatusGetCmd) PrintHelp(writer io.Writer) {
 	fmt.Fprintln(writer, statusGetHelpPage)
 }
 
+var statusNames = []string{"online", "dnd", "idle", "invisible"}
+
+// Complete offers all statuses that can be set.
+func (cmd *StatusSetCmd) Complete(parameters []string, index int) []string {
+	if index == 0 {
+		return statusNames
+	}
+
+	return nil
+}
+
+// Complete offers the subcommands and all statuses that can be set.
+func (cmd *StatusCmd) Complete(parameters []string, index int) []string {
+	if index == 0 {
+		return []string{"get", "set"}
+	}
+
+	if index == 1 && (parameters[0] == "set" || parameters[0] == "update") {
+		return statusNames
+	}
+
+	return nil
+}
+
 func (cmd *StatusSetCmd) Name() string {
 	return "status-set"
 }
@@ -10,6 +10,7 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/Bios-Marcel/cordless/commands"
 	"github.com/Bios-Marcel/cordless/ui"
 	"github.com/Bios-Marcel/discordgo"
 )
@@ -44,16 +45,7 @@ const (
 	information. Every value has a specific parameter and you'l always
 	be asked for your password when trying to change any data.
 
-[::b]OPTIONS
-	TODO
-	[::b]-n, --name
-		change your nickname
-	[::b]-e, --email
-		change the e-mail address asociated with your account
-	[::b]-a, --avatar
-		change your avatar to a new local file of yours
-	[::b]-p, --np
-		changes the password you use to log in to your account
+%s
 
 [::b]EXAMPLES
 	[gray]$ user-set -n "My new nickname"
@@ -72,15 +64,7 @@ const (
 	supplied, then "-n", "-e" and "-a" are chosen as the default
 	options.
 
-[::b]OPTIONS
-	[::b]-n, --name
-		Prints nickname and discriminator
-	[::b]-e, --email
-		Prints your e-mail address
-	[::b]-a, --avatar
-		Prints the URL of your avatar
-	[::b]-t, --tfa
-		Prints whether you have two-factor authentication enabled
+%s
 
 [::b]EXAMPLES
 	[gray]$ user
@@ -92,6 +76,68 @@ const (
 	Avatar: https://discordapp.com/XXX/YYY.png`
 )
 
+var (
+	userSetFlags = &commands.FlagSet{
+		Flags: []*commands.Flag{
+			{
+				Short:       "n",
+				Long:        "name",
+				Aliases:     []string{"-u", "--nick", "--username"},
+				Type:        commands.StringFlag,
+				ValueName:   "name",
+				Description: "change your nickname",
+			}, {
+				Short:       "e",
+				Long:        "email",
+				Aliases:     []string{"--e-mail", "--mail"},
+				Type:        commands.StringFlag,
+				ValueName:   "address",
+				Description: "change the e-mail address asociated with your account",
+			}, {
+				Short:         "a",
+				Long:          "avatar",
+				Aliases:       []string{"--profile-picture"},
+				Type:          commands.StringFlag,
+				ValueName:     "path",
+				OptionalValue: true,
+				Description:   "change your avatar to a new local file of yours or remove it if no file is given",
+			}, {
+				Short:       "p",
+				Long:        "new-password",
+				Aliases:     []string{"-np", "--np"},
+				Type:        commands.BoolFlag,
+				Description: "changes the password you use to log in to your account",
+			},
+		},
+	}
+
+	userGetFlags = &commands.FlagSet{
+		Flags: []*commands.Flag{
+			{
+				Short:       "n",
+				Long:        "name",
+				Aliases:     []string{"-u", "--nick", "--username"},
+				Description: "Prints nickname and discriminator",
+			}, {
+				Short:       "e",
+				Long:        "email",
+				Aliases:     []string{"--e-mail", "--mail"},
+				Description: "Prints your e-mail address",
+			}, {
+				Short:       "a",
+				Long:        "avatar",
+				Aliases:     []string{"--profile-picture"},
+				Description: "Prints the URL of your avatar",
+			}, {
+				Short:       "t",
+				Long:        "tfa",
+				Aliases:     []string{"-m", "--mfa", "--2fa"},
+				Description: "Prints whether you have two-factor authentication enabled",
+			},
+		},
+	}
+)
+
 type UserCmd struct {
 	userSetCmd *UserSetCmd
 	userGetCmd *UserGetCmd
@@ -134,62 +180,55 @@ func (cmd *UserCmd) Execute(writer io.Writer, parameters []string) {
 }
 
 func (cmd *UserGetCmd) Execute(writer io.Writer, parameters []string) {
+	flags, parseError := userGetFlags.Parse(parameters)
+	if parseError == nil && len(flags.Arguments) > 0 {
+		parseError = fmt.Errorf("unexpected parameter '%s'", flags.Arguments[0])
+	}
+	if parseError != nil {
+		fmt.Fprintf(writer, "[red]Error parsing parameters:\n\t[red]%s\n", parseError)
+		cmd.PrintHelp(writer)
+		return
+	}
+
 	if len(parameters) == 0 {
 		//Calling get with defaults
 		cmd.Execute(writer, []string{"-n", "-e", "-a"})
-	} else {
-		userInformation := ""
-		for _, param := range parameters {
-			switch param {
-			case "-n", "--name", "--nick", "-u", "--username":
-				userInformation += fmt.Sprintf("Nick: %s#%s\n", cmd.session.State.User.Username, cmd.session.State.User.Discriminator)
-			case "-e", "--email", "--e-mail", "--mail":
-				userInformation += fmt.Sprintf("E-Mail: %s\n", cmd.session.State.User.Email)
-			case "-a", "--avatar", "--profile-picture":
-				// FIXME Potential bug if jpeg is uploaded?
-				userInformation += fmt.Sprintf("Avatar: https://cdn.discordapp.com/avatars/%s/%s.png\n", cmd.session.State.User.ID, cmd.session.State.User.Avatar)
-			case "-m", "--mfa", "--tfa", "--2fa":
-				userInformation += fmt.Sprintf("Two-Factor Authentication : %v\n", cmd.session.State.User.MFAEnabled)
-			default:
-				fmt.Fprintf(writer, "[red]Invalid parameter '%s'\n", param)
-				cmd.PrintHelp(writer)
-				return
-			}
-		}
-
-		fmt.Fprint(writer, userInformation)
+		return
+	}
 
+	if flags.Bool("name") {
+		fmt.Fprintf(writer, "Nick: %s#%s\n", cmd.session.State.User.Username, cmd.session.State.User.Discriminator)
+	}
+	if flags.Bool("email") {
+		fmt.Fprintf(writer, "E-Mail: %s\n", cmd.session.State.User.Email)
+	}
+	if flags.Bool("avatar") {
+		// FIXME Potential bug if jpeg is uploaded?
+		fmt.Fprintf(writer, "Avatar: https://cdn.discordapp.com/avatars/%s/%s.png\n", cmd.session.State.User.ID, cmd.session.State.User.Avatar)
+	}
+	if flags.Bool("tfa") {
+		fmt.Fprintf(writer, "Two-Factor Authentication : %v\n", cmd.session.State.User.MFAEnabled)
 	}
 }
 
 func (cmd *UserSetCmd) Execute(writer io.Writer, parameters []string) {
-	var newName, newEmail string
+	flags, parseError := userSetFlags.Parse(parameters)
+	if parseError == nil && len(flags.Arguments) > 0 {
+		parseError = fmt.Errorf("unexpected parameter '%s'", flags.Arguments[0])
+	}
+	if parseError != nil {
+		fmt.Fprintf(writer, "[red]Error parsing parameters:\n\t[red]%s\n", parseError)
+		cmd.PrintHelp(writer)
+		return
+	}
+
+	newName := flags.String("name")
+	newEmail := flags.String("email")
 	newAvatar := cmd.session.State.User.Avatar
-	var askForNewPassword bool
-	for index, param := range parameters {
-		switch param {
-		case "-n", "--name", "--nick", "-u", "--username":
-			if index != len(parameters)-1 {
-				newName = parameters[index+1]
-			} else {
-				fmt.Fprintln(writer, "[red]Error, you didn't supply a new name.")
-			}
-		case "-e", "--email", "--e-mail", "--mail":
-			if index != len(parameters)-1 {
-				newEmail = parameters[index+1]
-			} else {
-				fmt.Fprintln(writer, "[red]Error, you didn't supply a new e-mail address.")
-			}
-		case "-a", "--avatar", "--profile-picture":
-			if index != len(parameters)-1 && !strings.HasPrefix(parameters[index+1], "-") {
-				newAvatar = parameters[index+1]
-			} else {
-				newAvatar = ""
-			}
-		case "--new-password", "-np":
-			askForNewPassword = true
-		}
+	if flags.IsSet("avatar") {
+		newAvatar = flags.String("avatar")
 	}
+	askForNewPassword := flags.Bool("new-password")
 
 	if newName == "" && !askForNewPassword && newEmail == "" && newAvatar == cmd.session.State.User.Avatar {
 		fmt.Fprintln(writer, "[red]No valid parameters were supplied.")
@@ -282,11 +321,11 @@ func (cmd *UserCmd) PrintHelp(writer io.Writer) {
 }
 
 func (cmd *UserSetCmd) PrintHelp(writer io.Writer) {
-	fmt.Fprintln(writer, userSetHelpPage)
+	fmt.Fprintf(writer, userSetHelpPage+"\n", userSetFlags.OptionsHelp())
 }
 
 func (cmd *UserGetCmd) PrintHelp(writer io.Writer) {
-	fmt.Fprintln(writer, userGetHelpPage)
+	fmt.Fprintf(writer, userGetHelpPage+"\n", userGetFlags.OptionsHelp())
 }
 
 func (cmd *UserCmd) Name() string {
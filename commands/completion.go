@@ -0,0 +1,183 @@
+package commands
+
+import (
+	"io/ioutil"
+	"os"
+	"os/user"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Completer can optionally be implemented by a Command in order to offer
+// completion candidates for its parameters.
+type Completer interface {
+	// Complete returns the candidates for the parameter at the given index.
+	// The parameters contain everything that has been typed so far, with
+	// the possibly incomplete parameter being at index. Candidates that
+	// don't start with the already typed text are ignored, so there is no
+	// need to filter them.
+	Complete(parameters []string, index int) []string
+}
+
+// Complete completes the last word of the given command line. The first word
+// of a command is completed using commandNames, all following words are
+// completed by the command itself, if it implements Completer. If there's
+// exactly one candidate, the word is replaced with it. If there are more,
+// the word is extended as far as all candidates agree. The returned
+// candidates are meant to be shown to the user.
+func Complete(input string, commandNames []string, findCommand func(name string) Command) (string, []string) {
+	commandStart, redirected := findLastCommandStart(input)
+	commandLine := input[commandStart:]
+	parameters := ParseCommand(commandLine)
+	if len(parameters) == 0 || strings.HasSuffix(commandLine, " ") {
+		parameters = append(parameters, "")
+	}
+
+	typed := parameters[len(parameters)-1]
+	var candidates []string
+	if redirected {
+		candidates = CompleteFilePath(typed)
+	} else if len(parameters) == 1 {
+		candidates = commandNames
+	} else if completer, isCompleter := findCommand(parameters[0]).(Completer); isCompleter {
+		candidates = completer.Complete(parameters[1:], len(parameters)-2)
+	}
+
+	candidates = filterCandidates(candidates, typed)
+	if len(candidates) == 0 {
+		return input, nil
+	}
+
+	var wordStart int
+	if typed != "" {
+		wordStart = findLastWordStart(commandLine)
+	} else {
+		wordStart = len(commandLine)
+	}
+	prefix := input[:commandStart+wordStart]
+
+	if len(candidates) == 1 {
+		completed := prefix + QuoteParameter(candidates[0])
+		//Directories are usually followed by more path segments.
+		if !strings.HasSuffix(candidates[0], string(os.PathSeparator)) {
+			completed += " "
+		}
+		return completed, candidates
+	}
+
+	commonPrefix := findCommonPrefix(candidates)
+	if len(commonPrefix) > len(typed) && QuoteParameter(commonPrefix) == commonPrefix {
+		return prefix + commonPrefix, candidates
+	}
+
+	return input, candidates
+}
+
+// CompleteFilePath returns the paths of all files and directories that
+// could complete the given, possibly incomplete, path. Directories end with
+// a path separator. A leading "~" is kept, but treated as the home
+// directory.
+func CompleteFilePath(typed string) []string {
+	directory, _ := filepath.Split(typed)
+	resolvedDirectory := directory
+	if strings.HasPrefix(directory, "~") {
+		currentUser, userResolveError := user.Current()
+		if userResolveError != nil {
+			return nil
+		}
+		resolvedDirectory = filepath.Join(currentUser.HomeDir, strings.TrimPrefix(directory, "~"))
+	}
+	if resolvedDirectory == "" {
+		resolvedDirectory = "."
+	}
+
+	files, readError := ioutil.ReadDir(resolvedDirectory)
+	if readError != nil {
+		return nil
+	}
+
+	candidates := make([]string, 0, len(files))
+	for _, file := range files {
+		candidate := directory + file.Name()
+		if file.IsDir() {
+			candidate += string(os.PathSeparator)
+		}
+		candidates = append(candidates, candidate)
+	}
+
+	return candidates
+}
+
+// findLastCommandStart returns the index at which the last command of the
+// command line starts, ignoring quoted and escaped separators. If the last
+// separator is a redirection, the text following it is a file path instead
+// of a command.
+func findLastCommandStart(input string) (int, bool) {
+	var start int
+	var quoted, redirected bool
+	for index := 0; index < len(input); index++ {
+		switch input[index] {
+		case '\\':
+			index++
+		case '"':
+			quoted = !quoted
+		case ';', '|', '>':
+			if !quoted {
+				start = index + 1
+				redirected = input[index] == '>'
+			}
+		}
+	}
+
+	return start, redirected
+}
+
+// findLastWordStart returns the index at which the last word of the command
+// starts. Quoted words start at their opening quote.
+func findLastWordStart(commandLine string) int {
+	var start int
+	var quoted bool
+	for index := 0; index < len(commandLine); index++ {
+		switch commandLine[index] {
+		case '\\':
+			index++
+		case '"':
+			if !quoted {
+				start = index
+			}
+			quoted = !quoted
+		case ' ':
+			if !quoted {
+				start = index + 1
+			}
+		}
+	}
+
+	return start
+}
+
+func filterCandidates(candidates []string, typed string) []string {
+	filtered := make([]string, 0, len(candidates))
+	seen := make(map[string]bool, len(candidates))
+	for _, candidate := range candidates {
+		if strings.HasPrefix(candidate, typed) && !seen[candidate] {
+			seen[candidate] = true
+			filtered = append(filtered, candidate)
+		}
+	}
+	sort.Strings(filtered)
+
+	return filtered
+}
+
+func findCommonPrefix(candidates []string) string {
+	commonPrefix := []rune(candidates[0])
+	for _, candidate := range candidates[1:] {
+		for !strings.HasPrefix(candidate, string(commonPrefix)) {
+			commonPrefix = commonPrefix[:len(commonPrefix)-1]
+		}
+	}
+
+	return string(commonPrefix)
+}
@@ -0,0 +1,90 @@
+package commands
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+type testCompleter struct {
+	testCommand
+}
+
+func (cmd *testCompleter) Complete(parameters []string, index int) []string {
+	if index == 0 {
+		return []string{"switch", "list", "add", "add-current"}
+	}
+
+	if index == 1 && parameters[0] == "switch" {
+		return []string{"main", "second account"}
+	}
+
+	return nil
+}
+
+func TestComplete(t *testing.T) {
+	account := &testCompleter{testCommand{name: "account"}}
+	findCommand := func(name string) Command {
+		if name == account.name {
+			return account
+		}
+		return nil
+	}
+	commandNames := []string{"account", "alias", "friends"}
+
+	tests := []struct {
+		name           string
+		input          string
+		want           string
+		wantCandidates []string
+	}{
+		{"command name", "fr", "friends ", []string{"friends"}},
+		{"ambiguous command name", "a", "a", []string{"account", "alias"}},
+		{"command after pipe", "account list | acc", "account list | account ", []string{"account"}},
+		{"subcommand", "account sw", "account switch ", []string{"switch"}},
+		{"common prefix", "account a", "account add", []string{"add", "add-current"}},
+		{"all subcommands", "account ", "account ", []string{"add", "add-current", "list", "switch"}},
+		{"quoted candidate", "account switch s", `account switch "second account" `, []string{"second account"}},
+		{"no candidates", "account switch x", "account switch x", nil},
+		{"command without completer", "friends l", "friends l", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, candidates := Complete(tt.input, commandNames, findCommand)
+			if got != tt.want {
+				t.Errorf("Complete() = %q, want %q", got, tt.want)
+			}
+			if !reflect.DeepEqual(candidates, tt.wantCandidates) {
+				t.Errorf("Complete() candidates = %q, want %q", candidates, tt.wantCandidates)
+			}
+		})
+	}
+}
+
+func TestCompleteFilePath(t *testing.T) {
+	directory, tempError := ioutil.TempDir("", "cordless-completion")
+	if tempError != nil {
+		t.Fatal(tempError)
+	}
+	defer os.RemoveAll(directory)
+
+	if err := os.Mkdir(filepath.Join(directory, "pictures"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(directory, "notes.txt"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	prefix := directory + string(os.PathSeparator)
+	want := []string{prefix + "notes.txt", prefix + "pictures" + string(os.PathSeparator)}
+	if got := CompleteFilePath(prefix + "p"); !reflect.DeepEqual(got, want) {
+		t.Errorf("CompleteFilePath() = %q, want %q", got, want)
+	}
+
+	got, _ := Complete("friends list > "+prefix+"pi", nil, func(string) Command { return nil })
+	if got != "friends list > "+prefix+"pictures"+string(os.PathSeparator) {
+		t.Errorf("Completing a redirection resulted in %q", got)
+	}
+}
@@ -0,0 +1,212 @@
+package commands
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// FlagType decides whether a Flag takes a value and how it is converted.
+type FlagType int
+
+const (
+	// BoolFlag doesn't take a value, it is true if present.
+	BoolFlag FlagType = iota
+	// StringFlag takes a value that is used as is.
+	StringFlag
+	// IntFlag takes a value that has to be a whole number.
+	IntFlag
+)
+
+// Flag describes a single option that a command accepts.
+type Flag struct {
+	// Short is the name used with a single dash, for example "n" for "-n".
+	// It may be empty.
+	Short string
+	// Long is the name used with two dashes, for example "name" for
+	// "--name". It also identifies the flag in ParsedFlags.
+	Long string
+	// Aliases are additional names including their dashes, for example
+	// "--nick". They are accepted, but not shown in the help.
+	Aliases []string
+	// Type decides whether the flag takes a value and how it is converted.
+	Type FlagType
+	// ValueName describes the value in the help, for example "path".
+	ValueName string
+	// Required causes parsing to fail if the flag is missing.
+	Required bool
+	// OptionalValue allows the flag to be used without a value. A value is
+	// only consumed if it doesn't start with a dash.
+	OptionalValue bool
+	// Description explains the flag in the help.
+	Description string
+}
+
+// FlagSet is the declarative specification of all flags a command accepts.
+type FlagSet struct {
+	Flags []*Flag
+}
+
+// ParsedFlags contains the result of parsing parameters with a FlagSet.
+type ParsedFlags struct {
+	values map[string]interface{}
+	// Arguments are all parameters that don't belong to a flag, in the
+	// order they were given.
+	Arguments []string
+}
+
+// IsSet returns whether the flag with the given long name was present.
+func (parsed *ParsedFlags) IsSet(long string) bool {
+	_, isSet := parsed.values[long]
+	return isSet
+}
+
+// Bool returns whether the flag with the given long name was present.
+func (parsed *ParsedFlags) Bool(long string) bool {
+	return parsed.IsSet(long)
+}
+
+// String returns the value of the flag with the given long name or an empty
+// string if it wasn't present or used without a value.
+func (parsed *ParsedFlags) String(long string) string {
+	value, _ := parsed.values[long].(string)
+	return value
+}
+
+// Int returns the value of the flag with the given long name or 0 if it
+// wasn't present or used without a value.
+func (parsed *ParsedFlags) Int(long string) int {
+	value, _ := parsed.values[long].(int)
+	return value
+}
+
+// Parse splits the parameters into flags and arguments and converts the
+// values of the flags. Everything following "--" is treated as an argument.
+// Values can either be passed as the next parameter or with an equals sign,
+// for example "--name=value".
+func (set *FlagSet) Parse(parameters []string) (*ParsedFlags, error) {
+	parsed := &ParsedFlags{
+		values:    make(map[string]interface{}),
+		Arguments: make([]string, 0),
+	}
+
+	for index := 0; index < len(parameters); index++ {
+		parameter := parameters[index]
+		if parameter == "--" {
+			parsed.Arguments = append(parsed.Arguments, parameters[index+1:]...)
+			break
+		}
+
+		if !strings.HasPrefix(parameter, "-") || parameter == "-" {
+			parsed.Arguments = append(parsed.Arguments, parameter)
+			continue
+		}
+
+		name := parameter
+		var value string
+		var hasValue bool
+		if equalsIndex := strings.IndexRune(parameter, '='); equalsIndex != -1 {
+			name = parameter[:equalsIndex]
+			value = parameter[equalsIndex+1:]
+			hasValue = true
+		}
+
+		flag := set.find(name)
+		if flag == nil {
+			return nil, errors.Errorf("unknown option '%s'", name)
+		}
+
+		if flag.Type == BoolFlag {
+			if hasValue {
+				return nil, errors.Errorf("the option '%s' doesn't take a value", name)
+			}
+			parsed.values[flag.Long] = true
+			continue
+		}
+
+		if !hasValue && index < len(parameters)-1 &&
+			(!flag.OptionalValue || !strings.HasPrefix(parameters[index+1], "-")) {
+			index++
+			value = parameters[index]
+			hasValue = true
+		}
+
+		if !hasValue {
+			if !flag.OptionalValue {
+				return nil, errors.Errorf("the option '%s' requires a value", name)
+			}
+			parsed.values[flag.Long] = nil
+			continue
+		}
+
+		if flag.Type == IntFlag {
+			number, parseError := strconv.Atoi(value)
+			if parseError != nil {
+				return nil, errors.Errorf("the value of the option '%s' has to be a whole number, but was '%s'", name, value)
+			}
+			parsed.values[flag.Long] = number
+		} else {
+			parsed.values[flag.Long] = value
+		}
+	}
+
+	for _, flag := range set.Flags {
+		if flag.Required && !parsed.IsSet(flag.Long) {
+			return nil, errors.Errorf("the option '--%s' is required", flag.Long)
+		}
+	}
+
+	return parsed, nil
+}
+
+func (set *FlagSet) find(name string) *Flag {
+	for _, flag := range set.Flags {
+		if (flag.Short != "" && name == "-"+flag.Short) || name == "--"+flag.Long {
+			return flag
+		}
+
+		for _, alias := range flag.Aliases {
+			if name == alias {
+				return flag
+			}
+		}
+	}
+
+	return nil
+}
+
+// OptionsHelp generates the OPTIONS section of a help page, listing all
+// flags with their descriptions.
+func (set *FlagSet) OptionsHelp() string {
+	var help strings.Builder
+	help.WriteString("[::b]OPTIONS")
+	for _, flag := range set.Flags {
+		help.WriteString("\n\t[::b]")
+		if flag.Short != "" {
+			fmt.Fprintf(&help, "-%s, ", flag.Short)
+		}
+		fmt.Fprintf(&help, "--%s[::-]", flag.Long)
+
+		if flag.Type != BoolFlag {
+			valueName := flag.ValueName
+			if valueName == "" {
+				valueName = "value"
+			}
+			if flag.OptionalValue {
+				fmt.Fprintf(&help, " [<%s>[]", valueName)
+			} else {
+				fmt.Fprintf(&help, " <%s>", valueName)
+			}
+		}
+
+		if flag.Required {
+			help.WriteString(" (required)")
+		}
+
+		fmt.Fprintf(&help, "\n\t\t%s", flag.Description)
+	}
+
+	return help.String()
+}
@@ -0,0 +1,90 @@
+package commands
+
+import (
+	"reflect"
+	"testing"
+)
+
+var testFlags = &FlagSet{
+	Flags: []*Flag{
+		{Short: "n", Long: "name", Aliases: []string{"--nick"}, Type: StringFlag, ValueName: "name", Description: "sets the name"},
+		{Short: "a", Long: "avatar", Type: StringFlag, OptionalValue: true, ValueName: "path", Description: "sets the avatar"},
+		{Long: "count", Type: IntFlag, Required: true, Description: "sets the count"},
+		{Short: "v", Long: "verbose", Description: "prints more"},
+	},
+}
+
+func TestFlagSetParse(t *testing.T) {
+	tests := []struct {
+		name       string
+		parameters []string
+		wantValues map[string]interface{}
+		wantArgs   []string
+		wantErr    bool
+	}{
+		{
+			name:       "all flags",
+			parameters: []string{"-n", "Marcel", "--avatar", "pic.png", "--count", "3", "-v"},
+			wantValues: map[string]interface{}{"name": "Marcel", "avatar": "pic.png", "count": 3, "verbose": true},
+			wantArgs:   []string{},
+		}, {
+			name:       "equals sign, alias and arguments",
+			parameters: []string{"first", "--nick=Bios Marcel", "--count=-1", "--", "-v"},
+			wantValues: map[string]interface{}{"name": "Bios Marcel", "count": -1},
+			wantArgs:   []string{"first", "-v"},
+		}, {
+			name:       "optional value missing",
+			parameters: []string{"-a", "-v", "--count", "1"},
+			wantValues: map[string]interface{}{"avatar": nil, "verbose": true, "count": 1},
+			wantArgs:   []string{},
+		}, {
+			name:       "required flag missing",
+			parameters: []string{"-v"},
+			wantErr:    true,
+		}, {
+			name:       "value missing",
+			parameters: []string{"--count", "1", "-n"},
+			wantErr:    true,
+		}, {
+			name:       "unknown flag",
+			parameters: []string{"--count", "1", "-x"},
+			wantErr:    true,
+		}, {
+			name:       "invalid number",
+			parameters: []string{"--count", "one"},
+			wantErr:    true,
+		}, {
+			name:       "value for bool flag",
+			parameters: []string{"--count", "1", "--verbose=yes"},
+			wantErr:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := testFlags.Parse(tt.parameters)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Parse() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if !reflect.DeepEqual(got.values, tt.wantValues) {
+				t.Errorf("Parse() values = %v, want %v", got.values, tt.wantValues)
+			}
+			if !reflect.DeepEqual(got.Arguments, tt.wantArgs) {
+				t.Errorf("Parse() arguments = %q, want %q", got.Arguments, tt.wantArgs)
+			}
+		})
+	}
+}
+
+func TestFlagSetOptionsHelp(t *testing.T) {
+	want := "[::b]OPTIONS" +
+		"\n\t[::b]-n, --name[::-] <name>\n\t\tsets the name" +
+		"\n\t[::b]-a, --avatar[::-] [<path>[]\n\t\tsets the avatar" +
+		"\n\t[::b]--count[::-] <value> (required)\n\t\tsets the count" +
+		"\n\t[::b]-v, --verbose[::-]\n\t\tprints more"
+	if got := testFlags.OptionsHelp(); got != want {
+		t.Errorf("OptionsHelp() = %q, want %q", got, want)
+	}
+}
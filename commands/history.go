@@ -0,0 +1,146 @@
+package commands
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"strings"
+)
+
+// History is a list of previously executed command lines, the most recent
+// one being the last entry. Each command line is contained only once, adding
+// it again moves it to the end of the list.
+type History struct {
+	// path is the file the history is saved to. If empty, the history is
+	// only kept in memory.
+	path string
+	// maxSize is the maximum amount of entries kept. If the limit is
+	// exceeded, the oldest entries are dropped. Zero or less means that
+	// there is no limit.
+	maxSize int
+	entries []string
+}
+
+// NewHistory creates an empty history that is only kept in memory.
+func NewHistory(maxSize int) *History {
+	return &History{maxSize: maxSize}
+}
+
+// LoadHistory loads the history from the given file. If the file doesn't
+// exist yet, the history is empty. All changes to the history are saved to
+// that file.
+func LoadHistory(path string, maxSize int) (*History, error) {
+	history := &History{path: path, maxSize: maxSize}
+
+	data, readError := ioutil.ReadFile(path)
+	if os.IsNotExist(readError) {
+		return history, nil
+	}
+	if readError != nil {
+		return nil, readError
+	}
+
+	if len(data) > 0 {
+		unmarshalError := json.Unmarshal(data, &history.entries)
+		if unmarshalError != nil {
+			return nil, unmarshalError
+		}
+	}
+	history.trim()
+
+	return history, nil
+}
+
+// Add appends the given command line to the history. If the history already
+// contains the command line, the old entry is removed.
+func (history *History) Add(entry string) error {
+	for index, existing := range history.entries {
+		if existing == entry {
+			history.entries = append(history.entries[:index], history.entries[index+1:]...)
+			break
+		}
+	}
+
+	history.entries = append(history.entries, entry)
+	history.trim()
+
+	return history.persist()
+}
+
+// Clear removes all entries from the history.
+func (history *History) Clear() error {
+	history.entries = nil
+	return history.persist()
+}
+
+// Len returns the amount of entries in the history.
+func (history *History) Len() int {
+	return len(history.entries)
+}
+
+// Get returns the entry at the given index, where zero is the oldest entry.
+func (history *History) Get(index int) string {
+	return history.entries[index]
+}
+
+// Search looks for the most recent entry that contains the query, starting
+// at the given index and going back in time. The index of the entry is
+// returned or -1 if there's no such entry.
+func (history *History) Search(query string, startIndex int) int {
+	if query == "" {
+		return -1
+	}
+
+	if startIndex > len(history.entries)-1 {
+		startIndex = len(history.entries) - 1
+	}
+
+	for index := startIndex; index >= 0; index-- {
+		if strings.Contains(history.entries[index], query) {
+			return index
+		}
+	}
+
+	return -1
+}
+
+// IsSensitive checks whether the command line passes secrets to any
+// SensitiveCommand and therefore mustn't be added to the history. Command
+// lines that can't be parsed are treated as sensitive, since it can't be
+// told what they contain.
+func IsSensitive(commandLine string, findCommand func(name string) Command) bool {
+	pipelines, parseError := ParseCommandLine(commandLine)
+	if parseError != nil {
+		return true
+	}
+
+	for _, pipeline := range pipelines {
+		for _, parameters := range pipeline.Commands {
+			command, isSensitiveCommand := findCommand(parameters[0]).(SensitiveCommand)
+			if isSensitiveCommand && command.IsSensitive(parameters[1:]) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func (history *History) trim() {
+	if history.maxSize > 0 && len(history.entries) > history.maxSize {
+		history.entries = history.entries[len(history.entries)-history.maxSize:]
+	}
+}
+
+func (history *History) persist() error {
+	if history.path == "" {
+		return nil
+	}
+
+	data, marshalError := json.MarshalIndent(history.entries, "", "    ")
+	if marshalError != nil {
+		return marshalError
+	}
+
+	return ioutil.WriteFile(history.path, data, 0600)
+}
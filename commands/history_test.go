@@ -0,0 +1,120 @@
+package commands
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func historyEntries(history *History) []string {
+	entries := make([]string, 0, history.Len())
+	for index := 0; index < history.Len(); index++ {
+		entries = append(entries, history.Get(index))
+	}
+	return entries
+}
+
+func TestHistory(t *testing.T) {
+	directory, tempError := ioutil.TempDir("", "cordless-history")
+	if tempError != nil {
+		t.Fatal(tempError)
+	}
+	defer os.RemoveAll(directory)
+	path := filepath.Join(directory, "command_history.json")
+
+	history, loadError := LoadHistory(path, 3)
+	if loadError != nil {
+		t.Fatal(loadError)
+	}
+	for _, entry := range []string{"a", "b", "a", "c", "d"} {
+		if err := history.Add(entry); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	want := []string{"a", "c", "d"}
+	if got := historyEntries(history); !reflect.DeepEqual(got, want) {
+		t.Errorf("Entries were %q, want %q", got, want)
+	}
+
+	reloaded, loadError := LoadHistory(path, 2)
+	if loadError != nil {
+		t.Fatal(loadError)
+	}
+	if got := historyEntries(reloaded); !reflect.DeepEqual(got, want[1:]) {
+		t.Errorf("Reloaded entries were %q, want %q", got, want[1:])
+	}
+
+	if err := reloaded.Clear(); err != nil {
+		t.Fatal(err)
+	}
+	reloaded, loadError = LoadHistory(path, 2)
+	if loadError != nil || reloaded.Len() != 0 {
+		t.Errorf("Expected an empty history after clearing, got %q (%v)", historyEntries(reloaded), loadError)
+	}
+}
+
+func TestHistorySearch(t *testing.T) {
+	history := NewHistory(0)
+	for _, entry := range []string{"friends list", "status get", "friends requests", "scripts"} {
+		history.Add(entry)
+	}
+
+	tests := []struct {
+		query      string
+		startIndex int
+		want       int
+	}{
+		{"friends", 3, 2},
+		{"friends", 1, 0},
+		{"friends", 100, 2},
+		{"status", 0, -1},
+		{"nothing", 3, -1},
+		{"", 3, -1},
+	}
+	for _, tt := range tests {
+		if got := history.Search(tt.query, tt.startIndex); got != tt.want {
+			t.Errorf("Search(%q, %d) = %d, want %d", tt.query, tt.startIndex, got, tt.want)
+		}
+	}
+}
+
+type testSensitiveCommand struct {
+	testCommand
+}
+
+func (cmd *testSensitiveCommand) IsSensitive(parameters []string) bool {
+	return len(parameters) > 0 && parameters[0] == "add"
+}
+
+func TestIsSensitive(t *testing.T) {
+	findCommand := func(name string) Command {
+		switch name {
+		case "account":
+			return &testSensitiveCommand{testCommand{name: "account"}}
+		case "echo":
+			return &testCommand{name: "echo"}
+		}
+		return nil
+	}
+
+	tests := []struct {
+		commandLine string
+		want        bool
+	}{
+		{"echo add", false},
+		{"account list", false},
+		{"account add name token", true},
+		{"echo hello; account add name token", true},
+		{"echo hello | account add name token > file", true},
+		{"unknown add", false},
+		{"echo hello |", true},
+	}
+	for _, tt := range tests {
+		if got := IsSensitive(tt.commandLine, findCommand); got != tt.want {
+			t.Errorf("IsSensitive(%q) = %v, want %v", tt.commandLine, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,188 @@
+package commands
+
+import (
+	"bytes"
+	"io"
+
+	"github.com/pkg/errors"
+)
+
+// InputCommand is a Command that is able to process the output of the
+// previous command in a pipeline. Only commands implementing this interface
+// can be used on the right side of a pipe.
+type InputCommand interface {
+	Command
+
+	// ExecuteWithInput runs the command, reading the output of the previous
+	// command from input and piping its own output into the supplied writer.
+	ExecuteWithInput(writer io.Writer, input io.Reader, parameters []string)
+}
+
+// Pipeline is a chain of commands, where each command receives the output
+// of the previous one as its input.
+type Pipeline struct {
+	// Commands contains the parameters of each command in the pipeline. The
+	// first parameter (index 0) will always be the command itself.
+	Commands [][]string
+	// OutputFile is the path of the file that the output of the last command
+	// is redirected to. If empty, the output isn't redirected.
+	OutputFile string
+	// Append decides whether the output is appended to the OutputFile
+	// instead of overwriting it.
+	Append bool
+}
+
+// ParseCommandLine takes an arbitrary input string and splits it into
+// pipelines. Pipelines are separated by ';', the commands of a pipeline are
+// separated by '|' and the output of a pipeline can be redirected into a
+// file via '>' or '>>'. None of these characters are treated specially if
+// they are quoted or escaped with a backslash.
+func ParseCommandLine(input string) ([]*Pipeline, error) {
+	pipelines := make([]*Pipeline, 0)
+	pipeline := &Pipeline{}
+
+	var current []rune
+	var redirecting, quoted bool
+
+	finishCommand := func() error {
+		parameters := ParseCommand(string(current))
+		current = current[:0]
+		if redirecting {
+			if len(parameters) != 1 {
+				return errors.New("a redirection requires exactly one file")
+			}
+			pipeline.OutputFile = parameters[0]
+			return nil
+		}
+
+		if len(parameters) == 0 {
+			return errors.New("a command is missing in front of or after a '|' or '>'")
+		}
+		pipeline.Commands = append(pipeline.Commands, parameters)
+		return nil
+	}
+
+	finishPipeline := func() error {
+		//Allows empty pipelines, for example a trailing ';'.
+		if len(pipeline.Commands) == 0 && !redirecting && len(ParseCommand(string(current))) == 0 {
+			current = current[:0]
+			return nil
+		}
+
+		commandError := finishCommand()
+		if commandError != nil {
+			return commandError
+		}
+		pipelines = append(pipelines, pipeline)
+		pipeline = &Pipeline{}
+		redirecting = false
+		return nil
+	}
+
+	runes := []rune(input)
+	for index := 0; index < len(runes); index++ {
+		char := runes[index]
+		if char == '\\' && index < len(runes)-1 {
+			current = append(current, char, runes[index+1])
+			index++
+			continue
+		}
+
+		if char == '"' {
+			quoted = !quoted
+		}
+
+		if quoted {
+			current = append(current, char)
+			continue
+		}
+
+		switch char {
+		case ';':
+			pipelineError := finishPipeline()
+			if pipelineError != nil {
+				return nil, pipelineError
+			}
+		case '|':
+			if redirecting {
+				return nil, errors.New("the output of a redirection can't be piped")
+			}
+			commandError := finishCommand()
+			if commandError != nil {
+				return nil, commandError
+			}
+		case '>':
+			if redirecting {
+				return nil, errors.New("the output can only be redirected once")
+			}
+			commandError := finishCommand()
+			if commandError != nil {
+				return nil, commandError
+			}
+			redirecting = true
+			if index < len(runes)-1 && runes[index+1] == '>' {
+				pipeline.Append = true
+				index++
+			}
+		default:
+			current = append(current, char)
+		}
+	}
+
+	pipelineError := finishPipeline()
+	if pipelineError != nil {
+		return nil, pipelineError
+	}
+
+	return pipelines, nil
+}
+
+// Execute runs the commands of the pipeline one after another, passing the
+// output of each command to the next one. The output of the last command is
+// written into the supplied writer. Before anything is executed, all
+// commands are looked up via findCommand, so that no command runs if the
+// pipeline is invalid.
+func (pipeline *Pipeline) Execute(writer io.Writer, findCommand func(name string) Command) error {
+	return pipeline.ExecuteWithInput(writer, nil, findCommand)
+}
+
+// ExecuteWithInput works like Execute, but passes the given input to the
+// first command of the pipeline. If input is nil, the first command doesn't
+// receive any input.
+func (pipeline *Pipeline) ExecuteWithInput(writer io.Writer, input io.Reader, findCommand func(name string) Command) error {
+	commands := make([]Command, 0, len(pipeline.Commands))
+	for index, parameters := range pipeline.Commands {
+		command := findCommand(parameters[0])
+		if command == nil {
+			return errors.Errorf("the command '%s' doesn't exist", parameters[0])
+		}
+
+		if _, isInputCommand := command.(InputCommand); (index > 0 || input != nil) && !isInputCommand {
+			return errors.Errorf("the command '%s' doesn't accept input", parameters[0])
+		}
+
+		commands = append(commands, command)
+	}
+
+	for index, command := range commands {
+		output := writer
+		var buffer *bytes.Buffer
+		if index < len(commands)-1 {
+			buffer = &bytes.Buffer{}
+			output = buffer
+		}
+
+		parameters := pipeline.Commands[index][1:]
+		if input != nil {
+			command.(InputCommand).ExecuteWithInput(output, input, parameters)
+		} else {
+			command.Execute(output, parameters)
+		}
+
+		if buffer != nil {
+			input = buffer
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,152 @@
+package commands
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseCommandLine(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    []*Pipeline
+		wantErr bool
+	}{
+		{
+			name:  "no command",
+			input: "   ",
+			want:  []*Pipeline{},
+		}, {
+			name:  "single command",
+			input: "friends list",
+			want:  []*Pipeline{{Commands: [][]string{{"friends", "list"}}}},
+		}, {
+			name:  "chained commands",
+			input: "status get; friends list;",
+			want: []*Pipeline{
+				{Commands: [][]string{{"status", "get"}}},
+				{Commands: [][]string{{"friends", "list"}}},
+			},
+		}, {
+			name:  "pipe with redirection",
+			input: "friends list | grep online > ~/friends.txt",
+			want: []*Pipeline{{
+				Commands:   [][]string{{"friends", "list"}, {"grep", "online"}},
+				OutputFile: "~/friends.txt",
+			}},
+		}, {
+			name:  "appending redirection without spaces",
+			input: "friends list>>\"my friends.txt\"",
+			want: []*Pipeline{{
+				Commands:   [][]string{{"friends", "list"}},
+				OutputFile: "my friends.txt",
+				Append:     true,
+			}},
+		}, {
+			name:  "quoted and escaped special characters",
+			input: `grep "a|b;c>d" \| \;`,
+			want:  []*Pipeline{{Commands: [][]string{{"grep", "a|b;c>d", "|", ";"}}}},
+		}, {
+			name:    "pipe without command",
+			input:   "friends list | | grep online",
+			wantErr: true,
+		}, {
+			name:    "redirection without file",
+			input:   "friends list >",
+			wantErr: true,
+		}, {
+			name:    "pipe after redirection",
+			input:   "friends list > out.txt | grep online",
+			wantErr: true,
+		}, {
+			name:    "multiple redirections",
+			input:   "friends list > a.txt > b.txt",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseCommandLine(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseCommandLine() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseCommandLine() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+type testCommand struct {
+	name    string
+	execute func(writer io.Writer, input io.Reader, parameters []string)
+}
+
+func (cmd *testCommand) Execute(writer io.Writer, parameters []string) {
+	cmd.execute(writer, nil, parameters)
+}
+
+func (cmd *testCommand) PrintHelp(writer io.Writer) {}
+
+func (cmd *testCommand) Name() string {
+	return cmd.name
+}
+
+func (cmd *testCommand) Aliases() []string {
+	return nil
+}
+
+type testInputCommand struct {
+	testCommand
+}
+
+func (cmd *testInputCommand) ExecuteWithInput(writer io.Writer, input io.Reader, parameters []string) {
+	cmd.execute(writer, input, parameters)
+}
+
+func TestPipelineExecute(t *testing.T) {
+	echo := &testCommand{name: "echo", execute: func(writer io.Writer, input io.Reader, parameters []string) {
+		fmt.Fprintln(writer, strings.Join(parameters, " "))
+	}}
+	upper := &testInputCommand{testCommand{name: "upper", execute: func(writer io.Writer, input io.Reader, parameters []string) {
+		data, _ := ioutil.ReadAll(input)
+		fmt.Fprint(writer, strings.ToUpper(string(data)))
+	}}}
+	findCommand := func(name string) Command {
+		switch name {
+		case echo.name:
+			return echo
+		case upper.name:
+			return upper
+		}
+		return nil
+	}
+
+	tests := []struct {
+		name     string
+		commands [][]string
+		want     string
+		wantErr  bool
+	}{
+		{"single command", [][]string{{"echo", "hello", "world"}}, "hello world\n", false},
+		{"piped command", [][]string{{"echo", "hello"}, {"upper"}, {"upper"}}, "HELLO\n", false},
+		{"unknown command", [][]string{{"echo", "hello"}, {"unknown"}}, "", true},
+		{"command without input support", [][]string{{"echo", "hello"}, {"echo"}}, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output := &strings.Builder{}
+			err := (&Pipeline{Commands: tt.commands}).Execute(output, findCommand)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Execute() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if output.String() != tt.want {
+				t.Errorf("Execute() output = %q, want %q", output.String(), tt.want)
+			}
+		})
+	}
+}
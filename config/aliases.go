@@ -0,0 +1,82 @@
+package config
+
+import (
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// aliases maps the name of each user-defined alias to the command lines it
+// expands to. An alias with more than one command line is a macro, which
+// runs all of its command lines in sequence.
+var aliases = make(map[string][]string)
+
+// GetAliasesFile returns the path to the file containing the user-defined
+// aliases.
+func GetAliasesFile() (string, error) {
+	configDir, configError := GetConfigDirectory()
+
+	if configError != nil {
+		return "", configError
+	}
+
+	return filepath.Join(configDir, "aliases.json"), nil
+}
+
+// GetAliases returns the currently loaded aliases. Changes to the returned
+// map can be saved via PersistAliases.
+func GetAliases() map[string][]string {
+	return aliases
+}
+
+// LoadAliases reads the aliases from the users configuration folder and
+// stores them in the local state. They can be retrieved via GetAliases.
+func LoadAliases() error {
+	aliasesFilePath, aliasesError := GetAliasesFile()
+	if aliasesError != nil {
+		return aliasesError
+	}
+
+	aliasesFile, openError := os.Open(aliasesFilePath)
+
+	if os.IsNotExist(openError) {
+		return nil
+	}
+
+	if openError != nil {
+		return openError
+	}
+
+	defer aliasesFile.Close()
+	decoder := json.NewDecoder(aliasesFile)
+	aliasesLoadError := decoder.Decode(&aliases)
+
+	//io.EOF would mean empty, therefore we use defaults.
+	if aliasesLoadError != nil && aliasesLoadError != io.EOF {
+		return aliasesLoadError
+	}
+
+	//A file containing "null" would leave us without a map.
+	if aliases == nil {
+		aliases = make(map[string][]string)
+	}
+
+	return nil
+}
+
+// PersistAliases saves the current aliases onto the filesystem.
+func PersistAliases() error {
+	aliasesFilePath, aliasesError := GetAliasesFile()
+	if aliasesError != nil {
+		return aliasesError
+	}
+
+	aliasesAsJSON, jsonError := json.MarshalIndent(&aliases, "", "    ")
+	if jsonError != nil {
+		return jsonError
+	}
+
+	return ioutil.WriteFile(aliasesFilePath, aliasesAsJSON, 0666)
+}
@@ -50,6 +50,11 @@ var (
 		ShortenerPort:                          63212,
 		DesktopNotifications:                   true,
 		ShowPlaceholderForBlockedMessages:      true,
+		ScriptTimeout:                          1000,
+		ScriptMaxStackDepth:                    1000,
+		ScriptMaxFailures:                      3,
+		ScriptMaxStorageSize:                   1024 * 1024,
+		CommandHistorySize:                     500,
 	}
 )
 
@@ -106,6 +111,31 @@ type Config struct {
 	// the timeline of messages.
 	ShowPlaceholderForBlockedMessages bool
 
+	// WatchScripts decides whether the script directory is watched for
+	// changes. If it is, all scripts are reloaded on every change.
+	WatchScripts bool
+	// DisabledScripts contains the names of all scripts that shouldn't be
+	// loaded. The name of a script is its path relative to the script
+	// directory.
+	DisabledScripts []string
+	// ScriptTimeout is the time in milliseconds a single script hook or
+	// command may run before it is aborted. 0 means no limit.
+	ScriptTimeout int
+	// ScriptMaxStackDepth limits the depth of nested function calls in
+	// scripts. 0 means no limit.
+	ScriptMaxStackDepth int
+	// ScriptMaxFailures is the amount of consecutive failures after which a
+	// script is disabled until it is reloaded. 0 means no limit.
+	ScriptMaxFailures int
+	// ScriptMaxStorageSize is the maximum size in bytes that the storage of
+	// a single script may take up on disk. 0 means no limit.
+	ScriptMaxStorageSize int
+
+	// CommandHistorySize is the maximum amount of commands kept in the
+	// command history. The history is saved between sessions, unless the
+	// size is 0.
+	CommandHistorySize int
+
 	// Accounts contains all saved accounts, allowing the user to dynamicly
 	// switch between the accounts.
 	Accounts []*Account
@@ -120,6 +150,7 @@ type Account struct {
 
 var cachedConfigDir string
 var cachedScriptDir string
+var cachedScriptStorageDir string
 
 //GetConfigFile returns the absolute path to the configuration file or an error
 //in case of failure.
@@ -144,6 +175,27 @@ func GetScriptDirectory() string {
 	return cachedScriptDir
 }
 
+//GetScriptStorageDirectory returns the path at which scripts persist the
+//data they have put into their storage.
+func GetScriptStorageDirectory() string {
+	if cachedScriptStorageDir == "" {
+		cachedScriptStorageDir = filepath.Join(cachedConfigDir, "script-storage")
+	}
+	return cachedScriptStorageDir
+}
+
+//GetCommandHistoryFile returns the path to the file containing the command
+//history.
+func GetCommandHistoryFile() (string, error) {
+	configDir, configError := GetConfigDirectory()
+
+	if configError != nil {
+		return "", configError
+	}
+
+	return filepath.Join(configDir, "command_history.json"), nil
+}
+
 //GetConfigDirectory is the parent directory in the os, that contains the
 //settings for the application.
 func GetConfigDirectory() (string, error) {
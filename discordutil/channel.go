@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"sort"
 	"strconv"
+	"strings"
 
 	"github.com/Bios-Marcel/discordgo"
 	"github.com/Bios-Marcel/tview"
@@ -89,10 +90,105 @@ func SortPrivateChannels(channels []*discordgo.Channel) {
 // HasReadMessagesPermission checks if the user has permission to view a
 // specific channel.
 func HasReadMessagesPermission(channelID string, state *discordgo.State) bool {
+	return HasChannelPermission(channelID, discordgo.PermissionReadMessages, state)
+}
+
+// HasChannelPermission checks if the user has the given permission in a
+// specific channel, taking the permission overwrites of the channel into
+// account.
+func HasChannelPermission(channelID string, permission int, state *discordgo.State) bool {
 	userPermissions, err := state.UserChannelPermissions(state.User.ID, channelID)
 	if err != nil {
 		// Unable to access channel permissions.
 		return false
 	}
-	return (userPermissions & discordgo.PermissionReadMessages) > 0
+	return (userPermissions & permission) == permission
+}
+
+// HasGuildPermission checks if the user has the given permission in a guild.
+// Permission overwrites of single channels aren't taken into account. The
+// permission is an int64, since newer permissions don't fit into 32 bits.
+func HasGuildPermission(guildID string, permission int64, state *discordgo.State) bool {
+	guild, err := state.Guild(guildID)
+	if err != nil {
+		return false
+	}
+
+	if guild.OwnerID == state.User.ID {
+		return true
+	}
+
+	member, err := state.Member(guildID, state.User.ID)
+	if err != nil {
+		return false
+	}
+
+	var userPermissions int64
+	for _, role := range guild.Roles {
+		if role.ID == guild.ID {
+			// The @everyone role has the same ID as the guild.
+			userPermissions |= int64(role.Permissions)
+			continue
+		}
+
+		for _, roleID := range member.Roles {
+			if role.ID == roleID {
+				userPermissions |= int64(role.Permissions)
+				break
+			}
+		}
+	}
+
+	if userPermissions&discordgo.PermissionAdministrator != 0 {
+		return true
+	}
+	return (userPermissions & permission) == permission
+}
+
+// ChannelMatches checks whether the channel is identified by the given ID or
+// name. Names are compared ignoring case and may be prefixed with '#'. For
+// private channels, the usernames of the recipients are accepted as well.
+func ChannelMatches(channel *discordgo.Channel, nameOrID string) bool {
+	if channel.ID == nameOrID {
+		return true
+	}
+
+	name := strings.TrimPrefix(nameOrID, "#")
+	if channel.Type == discordgo.ChannelTypeDM || channel.Type == discordgo.ChannelTypeGroupDM {
+		for _, recipient := range channel.Recipients {
+			if strings.EqualFold(recipient.Username, name) {
+				return true
+			}
+		}
+	}
+
+	return strings.EqualFold(channel.Name, name)
+}
+
+// FindChannel looks up a channel by its ID or its name. Names are searched
+// in the text channels of the given guild or in the private channels if the
+// guildID is empty. If no channel is found, nil is returned.
+func FindChannel(state *discordgo.State, guildID, nameOrID string) *discordgo.Channel {
+	channel, cacheError := state.Channel(nameOrID)
+	if cacheError == nil {
+		return channel
+	}
+
+	channels := state.PrivateChannels
+	if guildID != "" {
+		guild, cacheError := state.Guild(guildID)
+		if cacheError != nil {
+			return nil
+		}
+		channels = guild.Channels
+	}
+
+	for _, channel := range channels {
+		if channel.Type != discordgo.ChannelTypeGuildCategory && channel.Type != discordgo.ChannelTypeGuildVoice &&
+			ChannelMatches(channel, nameOrID) {
+			return channel
+		}
+	}
+
+	return nil
 }
@@ -113,3 +113,56 @@ func TestGetPrivateChannelName(t *testing.T) {
 		})
 	}
 }
+
+func TestPermissions(t *testing.T) {
+	self := &discordgo.User{ID: "1"}
+	state := discordgo.NewState()
+	state.User = self
+	stateError := state.GuildAdd(&discordgo.Guild{
+		ID:      "10",
+		OwnerID: "2",
+		Roles: []*discordgo.Role{
+			{ID: "10", Permissions: discordgo.PermissionReadMessages},
+			{ID: "20", Permissions: discordgo.PermissionManageChannels},
+			{ID: "21", Permissions: discordgo.PermissionKickMembers},
+		},
+		Members: []*discordgo.Member{{GuildID: "10", User: self, Roles: []string{"20"}}},
+		Channels: []*discordgo.Channel{
+			{ID: "30", GuildID: "10", Type: discordgo.ChannelTypeGuildText},
+			{
+				ID:      "31",
+				GuildID: "10",
+				Type:    discordgo.ChannelTypeGuildText,
+				PermissionOverwrites: []*discordgo.PermissionOverwrite{
+					{ID: "20", Type: "role", Deny: discordgo.PermissionManageChannels},
+				},
+			},
+		},
+	})
+	if stateError != nil {
+		t.Fatal(stateError)
+	}
+
+	if !HasGuildPermission("10", discordgo.PermissionManageChannels, state) {
+		t.Error("The permission of the members role should have been granted")
+	}
+	if !HasGuildPermission("10", discordgo.PermissionReadMessages, state) {
+		t.Error("The permission of the @everyone role should have been granted")
+	}
+	if HasGuildPermission("10", discordgo.PermissionKickMembers, state) {
+		t.Error("The permission of a role the member doesn't have shouldn't have been granted")
+	}
+	if HasGuildPermission("11", discordgo.PermissionReadMessages, state) {
+		t.Error("No permissions should have been granted for an unknown guild")
+	}
+
+	if !HasChannelPermission("30", discordgo.PermissionManageChannels, state) {
+		t.Error("The permission should have been granted in a channel without overwrites")
+	}
+	if HasChannelPermission("31", discordgo.PermissionManageChannels, state) {
+		t.Error("The permission shouldn't have been granted in a channel that denies it")
+	}
+	if !HasReadMessagesPermission("31", state) {
+		t.Error("Reading messages should have been allowed")
+	}
+}
@@ -0,0 +1,106 @@
+package discordutil
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/Bios-Marcel/discordgo"
+)
+
+var (
+	channelMentionRegex = regexp.MustCompile(`<#\d*>`)
+	roleMentionRegex    = regexp.MustCompile(`<@&\d*>`)
+)
+
+// GetDisplayName returns the nickname of the user in the given guild or the
+// username if there's no nickname. In case the user is a bot, "[BOT]" is
+// prepended. Unlike GetMemberName, the result isn't escaped.
+func GetDisplayName(state *discordgo.State, guildID string, user *discordgo.User) string {
+	name := user.Username
+	if guildID != "" {
+		member, cacheError := state.Member(guildID, user.ID)
+		if cacheError == nil && member.Nick != "" {
+			name = member.Nick
+		}
+	}
+
+	if user.Bot {
+		return "[BOT]" + name
+	}
+
+	return name
+}
+
+// MentionsCurrentUser checks whether the message mentions the current user,
+// either directly, via @everyone or @here or via one of the user's roles.
+func MentionsCurrentUser(state *discordgo.State, message *discordgo.Message) bool {
+	if message.MentionEveryone {
+		return true
+	}
+
+	for _, user := range message.Mentions {
+		if user.ID == state.User.ID {
+			return true
+		}
+	}
+
+	if message.GuildID != "" && len(message.MentionRoles) > 0 {
+		member, cacheError := state.Member(message.GuildID, state.User.ID)
+		if cacheError == nil {
+			for _, roleID := range member.Roles {
+				for _, mentionedRoleID := range message.MentionRoles {
+					if roleID == mentionedRoleID {
+						return true
+					}
+				}
+			}
+		}
+	}
+
+	return false
+}
+
+// ResolveMentions replaces the role, user and channel mentions, as well as
+// @everyone and @here, in the given text, which usually is the content of
+// the message. The replacement is decided by format, which receives the
+// unescaped name including its prefix, for example "@Marcel" or "#general",
+// and whether the mention refers to the current user. Mentions that can't
+// be resolved are left untouched.
+func ResolveMentions(state *discordgo.State, message *discordgo.Message, text string, format func(name string, mentionsSelf bool) string) string {
+	//Message.MentionRoles only contains the mentions for mentionable.
+	//Therefore we do it like this, in order to render every mention.
+	text = roleMentionRegex.
+		ReplaceAllStringFunc(text, func(data string) string {
+			roleID := strings.TrimSuffix(strings.TrimPrefix(data, "<@&"), ">")
+			role, cacheError := state.Role(message.GuildID, roleID)
+			if cacheError != nil {
+				return data
+			}
+
+			return format("@"+role.Name, false)
+		})
+
+	text = strings.NewReplacer(
+		"@everyone", format("@everyone", false),
+		"@here", format("@here", false),
+	).Replace(text)
+
+	for _, user := range message.Mentions {
+		replacement := format("@"+GetDisplayName(state, message.GuildID, user), state.User.ID == user.ID)
+		text = strings.NewReplacer(
+			"<@"+user.ID+">", replacement,
+			"<@!"+user.ID+">", replacement,
+		).Replace(text)
+	}
+
+	return channelMentionRegex.
+		ReplaceAllStringFunc(text, func(data string) string {
+			channelID := strings.TrimSuffix(strings.TrimPrefix(data, "<#"), ">")
+			channel, cacheError := state.Channel(channelID)
+			if cacheError != nil {
+				return data
+			}
+
+			return format("#"+channel.Name, false)
+		})
+}
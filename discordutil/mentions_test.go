@@ -0,0 +1,125 @@
+package discordutil
+
+import (
+	"testing"
+
+	"github.com/Bios-Marcel/discordgo"
+)
+
+func TestResolveMentions(t *testing.T) {
+	self := &discordgo.User{ID: "1", Username: "Self"}
+	other := &discordgo.User{ID: "2", Username: "Other"}
+	bot := &discordgo.User{ID: "3", Username: "Robot", Bot: true}
+
+	state := discordgo.NewState()
+	state.User = self
+	stateError := state.GuildAdd(&discordgo.Guild{
+		ID:       "10",
+		Roles:    []*discordgo.Role{{ID: "20", Name: "Admins"}},
+		Channels: []*discordgo.Channel{{ID: "30", GuildID: "10", Name: "general", Type: discordgo.ChannelTypeGuildText}},
+		Members: []*discordgo.Member{
+			{GuildID: "10", User: self},
+			{GuildID: "10", User: other, Nick: "Nickname"},
+		},
+	})
+	if stateError != nil {
+		t.Fatal(stateError)
+	}
+
+	message := &discordgo.Message{
+		GuildID:  "10",
+		Content:  "<@1> <@!2> <@3> <@&20> <@&21> <#30> <#31> @everyone",
+		Mentions: []*discordgo.User{self, other, bot},
+	}
+
+	got := ResolveMentions(state, message, message.Content, func(name string, mentionsSelf bool) string {
+		if mentionsSelf {
+			return "{" + name + "}"
+		}
+		return "(" + name + ")"
+	})
+	want := "{@Self} (@Nickname) (@[BOT]Robot) (@Admins) <@&21> (#general) <#31> (@everyone)"
+	if got != want {
+		t.Errorf("ResolveMentions() = %q, want %q", got, want)
+	}
+}
+
+func TestFindChannel(t *testing.T) {
+	state := discordgo.NewState()
+	stateError := state.GuildAdd(&discordgo.Guild{
+		ID: "10",
+		Channels: []*discordgo.Channel{
+			{ID: "30", GuildID: "10", Name: "general", Type: discordgo.ChannelTypeGuildText},
+			{ID: "31", GuildID: "10", Name: "voice", Type: discordgo.ChannelTypeGuildVoice},
+		},
+	})
+	if stateError != nil {
+		t.Fatal(stateError)
+	}
+	stateError = state.ChannelAdd(&discordgo.Channel{
+		ID:         "40",
+		Type:       discordgo.ChannelTypeDM,
+		Recipients: []*discordgo.User{{Username: "Marcel"}},
+	})
+	if stateError != nil {
+		t.Fatal(stateError)
+	}
+
+	tests := []struct {
+		guildID  string
+		nameOrID string
+		want     string
+	}{
+		{"", "30", "30"},
+		{"10", "#General", "30"},
+		{"10", "voice", ""},
+		{"10", "marcel", ""},
+		{"", "marcel", "40"},
+		{"", "general", ""},
+	}
+	for _, tt := range tests {
+		var gotID string
+		if channel := FindChannel(state, tt.guildID, tt.nameOrID); channel != nil {
+			gotID = channel.ID
+		}
+		if gotID != tt.want {
+			t.Errorf("FindChannel(%q, %q) = %q, want %q", tt.guildID, tt.nameOrID, gotID, tt.want)
+		}
+	}
+}
+
+func TestMentionsCurrentUser(t *testing.T) {
+	self := &discordgo.User{ID: "1", Username: "Self"}
+	other := &discordgo.User{ID: "2", Username: "Other"}
+
+	state := discordgo.NewState()
+	state.User = self
+	stateError := state.GuildAdd(&discordgo.Guild{
+		ID: "10",
+		Members: []*discordgo.Member{
+			{GuildID: "10", User: self, Roles: []string{"20"}},
+		},
+	})
+	if stateError != nil {
+		t.Fatal(stateError)
+	}
+
+	tests := []struct {
+		name    string
+		message *discordgo.Message
+		want    bool
+	}{
+		{"no mention", &discordgo.Message{GuildID: "10"}, false},
+		{"other user", &discordgo.Message{GuildID: "10", Mentions: []*discordgo.User{other}}, false},
+		{"self", &discordgo.Message{GuildID: "10", Mentions: []*discordgo.User{other, self}}, true},
+		{"everyone", &discordgo.Message{GuildID: "10", MentionEveryone: true}, true},
+		{"own role", &discordgo.Message{GuildID: "10", MentionRoles: []string{"21", "20"}}, true},
+		{"other role", &discordgo.Message{GuildID: "10", MentionRoles: []string{"21"}}, false},
+		{"private", &discordgo.Message{MentionRoles: []string{"20"}}, false},
+	}
+	for _, tt := range tests {
+		if got := MentionsCurrentUser(state, tt.message); got != tt.want {
+			t.Errorf("MentionsCurrentUser() for %s = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
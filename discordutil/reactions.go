@@ -0,0 +1,114 @@
+package discordutil
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/Bios-Marcel/discordemojimap"
+	"github.com/Bios-Marcel/discordgo"
+)
+
+var customEmojiRegex = regexp.MustCompile(`^<(a?):(\w+):(\d+)>$`)
+
+// ParseEmoji turns the input into an emoji that can be used for reactions.
+// The input can be a unicode emoji, an emoji code like ":thumbsup:", with or
+// without the colons, or a custom emoji of the given guild, either by its
+// name or in its message format. Custom emojis are preferred over emoji
+// codes with the same name, like the message input does it.
+func ParseEmoji(state *discordgo.State, guildID, input string) (*discordgo.Emoji, error) {
+	if match := customEmojiRegex.FindStringSubmatch(input); match != nil {
+		return &discordgo.Emoji{Animated: match[1] == "a", Name: match[2], ID: match[3]}, nil
+	}
+
+	name := strings.TrimSuffix(strings.TrimPrefix(input, ":"), ":")
+	if name == "" {
+		return nil, fmt.Errorf("'%s' is not a valid emoji", input)
+	}
+
+	if guildID != "" {
+		guild, cacheError := state.Guild(guildID)
+		if cacheError == nil {
+			for _, emoji := range guild.Emojis {
+				if emoji.Name == name {
+					return emoji, nil
+				}
+			}
+		}
+	}
+
+	if discordemojimap.ContainsCode(strings.ToLower(name)) {
+		return &discordgo.Emoji{Name: discordemojimap.Replace(":" + name + ":")}, nil
+	}
+
+	if discordemojimap.ContainsEmoji(input) {
+		return &discordgo.Emoji{Name: input}, nil
+	}
+
+	return nil, fmt.Errorf("'%s' is neither a known emoji nor a custom emoji of this server", input)
+}
+
+// EmojiEquals checks whether both emojis are the same. Custom emojis are
+// compared by ID and unicode emojis by name.
+func EmojiEquals(one, two *discordgo.Emoji) bool {
+	if one.ID != "" || two.ID != "" {
+		return one.ID == two.ID
+	}
+
+	return one.Name == two.Name
+}
+
+// FindReaction returns the reaction with the given emoji or nil if the
+// message has no such reaction.
+func FindReaction(message *discordgo.Message, emoji *discordgo.Emoji) *discordgo.MessageReactions {
+	for _, reaction := range message.Reactions {
+		if EmojiEquals(reaction.Emoji, emoji) {
+			return reaction
+		}
+	}
+
+	return nil
+}
+
+// AddReaction increases the count of the reaction with the given emoji on
+// the message, adding the reaction if the message didn't have it yet. Own
+// reactions are marked as such.
+func AddReaction(message *discordgo.Message, emoji *discordgo.Emoji, own bool) {
+	reaction := FindReaction(message, emoji)
+	if reaction == nil {
+		reaction = &discordgo.MessageReactions{Emoji: emoji}
+		message.Reactions = append(message.Reactions, reaction)
+	} else if own && reaction.Me {
+		// Own reactions can only exist once, therefore this is a duplicate.
+		return
+	}
+
+	reaction.Count++
+	if own {
+		reaction.Me = true
+	}
+}
+
+// RemoveReaction decreases the count of the reaction with the given emoji
+// on the message, removing the reaction once nobody has reacted with that
+// emoji anymore.
+func RemoveReaction(message *discordgo.Message, emoji *discordgo.Emoji, own bool) {
+	for index, reaction := range message.Reactions {
+		if !EmojiEquals(reaction.Emoji, emoji) {
+			continue
+		}
+
+		if own {
+			if !reaction.Me {
+				return
+			}
+			reaction.Me = false
+		}
+
+		reaction.Count--
+		if reaction.Count <= 0 {
+			message.Reactions = append(message.Reactions[:index], message.Reactions[index+1:]...)
+		}
+		return
+	}
+}
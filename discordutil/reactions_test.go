@@ -0,0 +1,81 @@
+package discordutil
+
+import (
+	"testing"
+
+	"github.com/Bios-Marcel/discordgo"
+)
+
+func TestParseEmoji(t *testing.T) {
+	state := discordgo.NewState()
+	stateError := state.GuildAdd(&discordgo.Guild{
+		ID:     "10",
+		Emojis: []*discordgo.Emoji{{ID: "50", Name: "gopher"}, {ID: "51", Name: "smile"}},
+	})
+	if stateError != nil {
+		t.Fatal(stateError)
+	}
+
+	tests := []struct {
+		guildID string
+		input   string
+		want    *discordgo.Emoji
+	}{
+		{"", "thumbsup", &discordgo.Emoji{Name: "👍"}},
+		{"", ":thumbsup:", &discordgo.Emoji{Name: "👍"}},
+		{"", "👍", &discordgo.Emoji{Name: "👍"}},
+		{"", ":smile:", &discordgo.Emoji{Name: "😄"}},
+		{"10", ":smile:", &discordgo.Emoji{ID: "51", Name: "smile"}},
+		{"10", "gopher", &discordgo.Emoji{ID: "50", Name: "gopher"}},
+		{"", "<a:party:60>", &discordgo.Emoji{ID: "60", Name: "party", Animated: true}},
+		{"", "gopher", nil},
+		{"", "::", nil},
+	}
+	for _, tt := range tests {
+		got, parseError := ParseEmoji(state, tt.guildID, tt.input)
+		if tt.want == nil {
+			if parseError == nil {
+				t.Errorf("ParseEmoji(%q) = %v, want an error", tt.input, got)
+			}
+			continue
+		}
+
+		if parseError != nil {
+			t.Errorf("ParseEmoji(%q) returned error %s", tt.input, parseError)
+		} else if got.ID != tt.want.ID || got.Name != tt.want.Name || got.Animated != tt.want.Animated {
+			t.Errorf("ParseEmoji(%q) = %v, want %v", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestAddAndRemoveReaction(t *testing.T) {
+	thumbsUp := &discordgo.Emoji{Name: "👍"}
+	gopher := &discordgo.Emoji{ID: "50", Name: "gopher"}
+	message := &discordgo.Message{}
+
+	AddReaction(message, thumbsUp, false)
+	AddReaction(message, thumbsUp, true)
+	AddReaction(message, thumbsUp, true)
+	AddReaction(message, &discordgo.Emoji{ID: "50"}, false)
+
+	if len(message.Reactions) != 2 {
+		t.Fatalf("expected 2 reactions, but got %d", len(message.Reactions))
+	}
+	if reaction := FindReaction(message, thumbsUp); reaction.Count != 2 || !reaction.Me {
+		t.Errorf("expected 2 reactions including our own, but got %d, %v", reaction.Count, reaction.Me)
+	}
+	if reaction := FindReaction(message, gopher); reaction.Count != 1 || reaction.Me {
+		t.Errorf("expected 1 foreign reaction, but got %d, %v", reaction.Count, reaction.Me)
+	}
+
+	RemoveReaction(message, thumbsUp, true)
+	RemoveReaction(message, thumbsUp, true)
+	if reaction := FindReaction(message, thumbsUp); reaction.Count != 1 || reaction.Me {
+		t.Errorf("expected 1 foreign reaction, but got %d, %v", reaction.Count, reaction.Me)
+	}
+
+	RemoveReaction(message, gopher, false)
+	if len(message.Reactions) != 1 || FindReaction(message, gopher) != nil {
+		t.Errorf("expected the reaction to be removed, but got %v", message.Reactions)
+	}
+}
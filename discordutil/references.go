@@ -0,0 +1,229 @@
+package discordutil
+
+import (
+	"encoding/json"
+	"net/url"
+	"strconv"
+	"sync"
+
+	"github.com/Bios-Marcel/discordgo"
+)
+
+// MessageReference points to the message that another message replies to.
+// It is missing in discordgo, therefore the references are parsed from the
+// raw data of events and requests and cached separately.
+type MessageReference struct {
+	MessageID string `json:"message_id"`
+	ChannelID string `json:"channel_id"`
+	GuildID   string `json:"guild_id,omitempty"`
+}
+
+// Reply identifies a message that replies to another message.
+type Reply struct {
+	MessageID string
+	ChannelID string
+}
+
+// rawMessage contains the fields of a message that discordgo doesn't parse.
+type rawMessage struct {
+	ID                string             `json:"id"`
+	ChannelID         string             `json:"channel_id"`
+	MessageReference  *MessageReference  `json:"message_reference"`
+	ReferencedMessage *discordgo.Message `json:"referenced_message"`
+}
+
+// maxReferencesPerChannel is the amount of references that are cached per
+// channel. If the limit is exceeded, the oldest references are dropped.
+const maxReferencesPerChannel = 500
+
+// channelReferences contains the cached references of the replies in a
+// single channel and the messages of that channel that are referenced.
+type channelReferences struct {
+	// replyIDs contains the IDs of all cached replies in the order they
+	// were added, so that the oldest ones can be dropped.
+	replyIDs           []string
+	references         map[string]*MessageReference
+	referencedMessages map[string]*discordgo.Message
+}
+
+var (
+	referencesMutex = &sync.Mutex{}
+	references      = make(map[string]*channelReferences)
+)
+
+func getChannelReferences(channelID string) *channelReferences {
+	cache := references[channelID]
+	if cache == nil {
+		cache = &channelReferences{
+			references:         make(map[string]*MessageReference),
+			referencedMessages: make(map[string]*discordgo.Message),
+		}
+		references[channelID] = cache
+	}
+	return cache
+}
+
+func (cache *channelReferences) add(replyID string, reference *MessageReference) {
+	if _, isPresent := cache.references[replyID]; !isPresent {
+		cache.replyIDs = append(cache.replyIDs, replyID)
+	}
+	cache.references[replyID] = reference
+
+	for len(cache.replyIDs) > maxReferencesPerChannel {
+		dropped := cache.references[cache.replyIDs[0]]
+		delete(cache.references, cache.replyIDs[0])
+		// The referenced message is cached for the channel it was sent in.
+		if referencedCache := references[dropped.ChannelID]; referencedCache != nil {
+			delete(referencedCache.referencedMessages, dropped.MessageID)
+		}
+		cache.replyIDs = cache.replyIDs[1:]
+	}
+}
+
+// GetMessageReference returns the reference of the message with the given
+// ID or nil if it isn't a reply or the reference hasn't been parsed yet.
+func GetMessageReference(channelID, messageID string) *MessageReference {
+	referencesMutex.Lock()
+	defer referencesMutex.Unlock()
+
+	if cache := references[channelID]; cache != nil {
+		return cache.references[messageID]
+	}
+	return nil
+}
+
+// GetReferencedMessage returns the cached message that the reference points
+// to. If it isn't cached, nil is returned.
+func GetReferencedMessage(reference *MessageReference) *discordgo.Message {
+	referencesMutex.Lock()
+	defer referencesMutex.Unlock()
+
+	if cache := references[reference.ChannelID]; cache != nil {
+		return cache.referencedMessages[reference.MessageID]
+	}
+	return nil
+}
+
+// StoreReferencedMessage caches a message that is referenced by a reply,
+// allowing it to be shown even if the message itself isn't loaded.
+func StoreReferencedMessage(message *discordgo.Message) {
+	referencesMutex.Lock()
+	defer referencesMutex.Unlock()
+
+	getChannelReferences(message.ChannelID).referencedMessages[message.ID] = message
+}
+
+// ClearMessageReferences drops all cached references of the given channel.
+func ClearMessageReferences(channelID string) {
+	referencesMutex.Lock()
+	defer referencesMutex.Unlock()
+
+	delete(references, channelID)
+}
+
+// ClearAllMessageReferences drops all cached references, for example when
+// switching to a different account.
+func ClearAllMessageReferences() {
+	referencesMutex.Lock()
+	defer referencesMutex.Unlock()
+
+	references = make(map[string]*channelReferences)
+}
+
+// ParseMessageReferences caches the references of the raw JSON of either a
+// single message or an array of messages. All messages that are replies are
+// returned.
+func ParseMessageReferences(data []byte) ([]*Reply, error) {
+	var messages []*rawMessage
+	if len(data) > 0 && data[0] == '[' {
+		if parseError := json.Unmarshal(data, &messages); parseError != nil {
+			return nil, parseError
+		}
+	} else {
+		message := &rawMessage{}
+		if parseError := json.Unmarshal(data, message); parseError != nil {
+			return nil, parseError
+		}
+		messages = []*rawMessage{message}
+	}
+
+	referencesMutex.Lock()
+	defer referencesMutex.Unlock()
+
+	var replies []*Reply
+	for _, message := range messages {
+		if message.MessageReference == nil || message.MessageReference.MessageID == "" {
+			continue
+		}
+
+		getChannelReferences(message.ChannelID).add(message.ID, message.MessageReference)
+		if message.ReferencedMessage != nil {
+			getChannelReferences(message.MessageReference.ChannelID).
+				referencedMessages[message.ReferencedMessage.ID] = message.ReferencedMessage
+		}
+		replies = append(replies, &Reply{MessageID: message.ID, ChannelID: message.ChannelID})
+	}
+
+	return replies, nil
+}
+
+// ChannelMessages does the same as Session.ChannelMessages, but additionally
+// caches the references of all retrieved replies.
+func ChannelMessages(session *discordgo.Session, channelID string, limit int, beforeID, afterID, aroundID string) ([]*discordgo.Message, error) {
+	uri := discordgo.EndpointChannelMessages(channelID)
+
+	values := url.Values{}
+	if limit > 0 {
+		values.Set("limit", strconv.Itoa(limit))
+	}
+	if afterID != "" {
+		values.Set("after", afterID)
+	}
+	if beforeID != "" {
+		values.Set("before", beforeID)
+	}
+	if aroundID != "" {
+		values.Set("around", aroundID)
+	}
+	if len(values) > 0 {
+		uri += "?" + values.Encode()
+	}
+
+	body, requestError := session.RequestWithBucketID("GET", uri, nil, discordgo.EndpointChannelMessages(channelID))
+	if requestError != nil {
+		return nil, requestError
+	}
+
+	var messages []*discordgo.Message
+	if parseError := json.Unmarshal(body, &messages); parseError != nil {
+		return nil, parseError
+	}
+	if _, parseError := ParseMessageReferences(body); parseError != nil {
+		return nil, parseError
+	}
+
+	return messages, nil
+}
+
+// SendReply sends a message that replies to the referenced message.
+func SendReply(session *discordgo.Session, channelID, content string, reference *MessageReference) (*discordgo.Message, error) {
+	data := map[string]interface{}{
+		"content":           content,
+		"message_reference": reference,
+	}
+	body, requestError := session.RequestWithBucketID("POST", discordgo.EndpointChannelMessages(channelID),
+		data, discordgo.EndpointChannelMessages(channelID))
+	if requestError != nil {
+		return nil, requestError
+	}
+
+	message := &discordgo.Message{}
+	if parseError := json.Unmarshal(body, message); parseError != nil {
+		return nil, parseError
+	}
+	if _, parseError := ParseMessageReferences(body); parseError != nil {
+		return nil, parseError
+	}
+
+	return message, nil
+}
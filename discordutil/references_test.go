@@ -0,0 +1,84 @@
+package discordutil
+
+import (
+	"reflect"
+	"strconv"
+	"testing"
+
+	"github.com/Bios-Marcel/discordgo"
+)
+
+func TestParseMessageReferences(t *testing.T) {
+	defer ClearAllMessageReferences()
+
+	replies, parseError := ParseMessageReferences([]byte(`[
+		{"id": "100", "channel_id": "1", "content": "plain"},
+		{"id": "101", "channel_id": "1", "message_reference": {"message_id": "100", "channel_id": "1"}},
+		{"id": "102", "channel_id": "1", "message_reference": {"message_id": "90", "channel_id": "1", "guild_id": "2"},
+			"referenced_message": {"id": "90", "content": "original"}}
+	]`))
+	if parseError != nil {
+		t.Fatal(parseError)
+	}
+	if want := []*Reply{{"101", "1"}, {"102", "1"}}; !reflect.DeepEqual(replies, want) {
+		t.Errorf("ParseMessageReferences() = %v, want %v", replies, want)
+	}
+
+	if reference := GetMessageReference("1", "100"); reference != nil {
+		t.Errorf("Message 100 isn't a reply, but got reference %v", reference)
+	}
+	if reference := GetMessageReference("1", "101"); reference == nil || reference.MessageID != "100" || reference.ChannelID != "1" {
+		t.Errorf("Unexpected reference for message 101: %v", reference)
+	}
+	if reference := GetMessageReference("2", "101"); reference != nil {
+		t.Errorf("Message 101 isn't part of channel 2, but got reference %v", reference)
+	}
+
+	reference := GetMessageReference("1", "102")
+	if reference == nil || reference.GuildID != "2" {
+		t.Fatalf("Unexpected reference for message 102: %v", reference)
+	}
+	if original := GetReferencedMessage(reference); original == nil || original.Content != "original" {
+		t.Errorf("Expected the referenced message to be cached, but got %v", original)
+	}
+
+	replies, parseError = ParseMessageReferences([]byte(`{"id": "103", "channel_id": "1", "message_reference": {"message_id": "101", "channel_id": "1"}}`))
+	if parseError != nil {
+		t.Fatal(parseError)
+	}
+	if want := []*Reply{{"103", "1"}}; !reflect.DeepEqual(replies, want) {
+		t.Errorf("ParseMessageReferences() = %v, want %v", replies, want)
+	}
+	if GetReferencedMessage(GetMessageReference("1", "103")) != nil {
+		t.Errorf("The referenced message shouldn't be cached")
+	}
+
+	ClearMessageReferences("1")
+	if reference := GetMessageReference("1", "101"); reference != nil {
+		t.Errorf("Expected the references of channel 1 to be cleared, but got %v", reference)
+	}
+}
+
+func TestMessageReferencesAreBounded(t *testing.T) {
+	defer ClearAllMessageReferences()
+
+	StoreReferencedMessage(&discordgo.Message{ID: "0", ChannelID: "1"})
+	for index := 1; index <= maxReferencesPerChannel+1; index++ {
+		_, parseError := ParseMessageReferences([]byte(`{"id": "` + strconv.Itoa(index) +
+			`", "channel_id": "1", "message_reference": {"message_id": "0", "channel_id": "1"}}`))
+		if parseError != nil {
+			t.Fatal(parseError)
+		}
+	}
+
+	if reference := GetMessageReference("1", "1"); reference != nil {
+		t.Errorf("Expected the oldest reference to be dropped, but got %v", reference)
+	}
+	reference := GetMessageReference("1", strconv.Itoa(maxReferencesPerChannel+1))
+	if reference == nil {
+		t.Fatal("Expected the newest reference to be cached")
+	}
+	if original := GetReferencedMessage(reference); original != nil {
+		t.Errorf("Expected the referenced message to be dropped together with the oldest reference, but got %v", original)
+	}
+}
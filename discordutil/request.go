@@ -0,0 +1,77 @@
+package discordutil
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/Bios-Marcel/discordgo"
+)
+
+// RequestWithReason sends a request to the Discord REST API the same way as
+// Session.RequestWithBucketID, but additionally passes a reason that shows
+// up in the audit log of the guild. An empty reason is omitted. This is
+// required, since discordgo has no way of setting additional headers.
+func RequestWithReason(session *discordgo.Session, method, urlStr string, data interface{}, bucketID, reason string) ([]byte, error) {
+	var body []byte
+	if data != nil {
+		var marshalError error
+		body, marshalError = json.Marshal(data)
+		if marshalError != nil {
+			return nil, marshalError
+		}
+	}
+
+	request, requestError := http.NewRequest(method, urlStr, bytes.NewReader(body))
+	if requestError != nil {
+		return nil, requestError
+	}
+
+	request.Header.Set("authorization", session.Token)
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("User-Agent", session.UserAgent)
+	if reason != "" {
+		request.Header.Set("X-Audit-Log-Reason", url.PathEscape(reason))
+	}
+
+	bucket := session.Ratelimiter.LockBucket(bucketID)
+	response, responseError := session.Client.Do(request)
+	if responseError != nil {
+		bucket.Release(nil)
+		return nil, responseError
+	}
+	defer response.Body.Close()
+
+	if releaseError := bucket.Release(response.Header); releaseError != nil {
+		return nil, releaseError
+	}
+
+	responseBody, readError := ioutil.ReadAll(response.Body)
+	if readError != nil {
+		return nil, readError
+	}
+
+	switch response.StatusCode {
+	case http.StatusOK, http.StatusCreated, http.StatusNoContent:
+		return responseBody, nil
+	case http.StatusTooManyRequests:
+		rateLimit := discordgo.TooManyRequests{}
+		if unmarshalError := json.Unmarshal(responseBody, &rateLimit); unmarshalError != nil {
+			return nil, unmarshalError
+		}
+		time.Sleep(rateLimit.RetryAfter * time.Millisecond)
+		return RequestWithReason(session, method, urlStr, data, bucketID, reason)
+	default:
+		restError := &discordgo.RESTError{
+			Request:      request,
+			Response:     response,
+			ResponseBody: responseBody,
+		}
+		// The message is optional, therefore the error is ignored.
+		json.Unmarshal(responseBody, &restError.Message)
+		return nil, restError
+	}
+}
@@ -0,0 +1,53 @@
+package discordutil
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Bios-Marcel/discordgo"
+)
+
+func TestRequestWithReason(t *testing.T) {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		requests++
+		if requests == 1 {
+			writer.WriteHeader(http.StatusTooManyRequests)
+			writer.Write([]byte(`{"retry_after": 1}`))
+			return
+		}
+
+		if reason := request.Header.Get("X-Audit-Log-Reason"); reason != "Spamming%20links" {
+			t.Errorf("The reason header was '%s'", reason)
+		}
+		if request.URL.Path == "/fail" {
+			writer.WriteHeader(http.StatusForbidden)
+			writer.Write([]byte(`{"code": 50013, "message": "Missing Permissions"}`))
+			return
+		}
+		writer.Write([]byte(`{"id": "1"}`))
+	}))
+	defer server.Close()
+
+	session, _ := discordgo.NewWithToken("cordless", "token")
+	response, requestError := RequestWithReason(session, "PATCH", server.URL+"/succeed", map[string]string{"a": "b"}, "bucket", "Spamming links")
+	if requestError != nil {
+		t.Fatalf("Unexpected error: %s", requestError)
+	}
+	if string(response) != `{"id": "1"}` {
+		t.Errorf("The response was '%s'", response)
+	}
+	if requests != 2 {
+		t.Errorf("The request should have been retried once after being rate limited, but %d requests were made", requests)
+	}
+
+	_, requestError = RequestWithReason(session, "DELETE", server.URL+"/fail", nil, "bucket", "Spamming links")
+	restError, isRESTError := requestError.(*discordgo.RESTError)
+	if !isRESTError {
+		t.Fatalf("Expected a RESTError, but got %v", requestError)
+	}
+	if restError.Message == nil || restError.Message.Code != 50013 {
+		t.Errorf("The error message wasn't parsed: %v", restError.Message)
+	}
+}
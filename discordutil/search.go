@@ -0,0 +1,152 @@
+package discordutil
+
+import (
+	"strings"
+	"time"
+
+	"github.com/Bios-Marcel/discordgo"
+	"github.com/pkg/errors"
+)
+
+// searchDateFormat is the format used by the before: and after: filters.
+const searchDateFormat = "2006-01-02"
+
+// SearchQuery describes which messages a search is looking for. All set
+// criteria have to be met by a message in order to match.
+type SearchQuery struct {
+	// Terms have to be contained in the message content, ignoring case.
+	Terms []string
+	// From is a username, a username with discriminator ("name#1234") or a
+	// user ID.
+	From string
+	// In is a channel name, optionally prefixed with '#', or a channel ID.
+	In string
+	// HasAttachment only matches messages with at least one attachment.
+	HasAttachment bool
+	// HasEmbed only matches messages with at least one embed.
+	HasEmbed bool
+	// Before only matches messages sent before this point in time.
+	Before time.Time
+	// After only matches messages sent after this point in time.
+	After time.Time
+}
+
+// ParseSearchQuery creates a SearchQuery from the given terms. Terms with
+// the prefixes "from:", "in:", "has:", "before:" and "after:" are filters,
+// all other terms have to be part of the message content. Dates have the
+// format YYYY-MM-DD and are interpreted in local time. Both date filters
+// exclude the given day itself.
+func ParseSearchQuery(terms []string) (*SearchQuery, error) {
+	query := &SearchQuery{}
+	for _, term := range terms {
+		colonIndex := strings.IndexRune(term, ':')
+		if colonIndex == -1 {
+			query.Terms = append(query.Terms, strings.ToLower(term))
+			continue
+		}
+
+		value := term[colonIndex+1:]
+		switch strings.ToLower(term[:colonIndex]) {
+		case "from":
+			query.From = value
+		case "in":
+			query.In = value
+		case "has":
+			switch strings.ToLower(value) {
+			case "attachment", "file":
+				query.HasAttachment = true
+			case "embed":
+				query.HasEmbed = true
+			default:
+				return nil, errors.Errorf("unknown filter 'has:%s', use 'has:attachment' or 'has:embed'", value)
+			}
+		case "before":
+			date, parseError := time.ParseInLocation(searchDateFormat, value, time.Local)
+			if parseError != nil {
+				return nil, errors.Errorf("the date '%s' has to be in the format YYYY-MM-DD", value)
+			}
+			query.Before = date
+		case "after":
+			date, parseError := time.ParseInLocation(searchDateFormat, value, time.Local)
+			if parseError != nil {
+				return nil, errors.Errorf("the date '%s' has to be in the format YYYY-MM-DD", value)
+			}
+			query.After = date.AddDate(0, 0, 1)
+		default:
+			// Not a known filter, for example a time like "12:00".
+			query.Terms = append(query.Terms, strings.ToLower(term))
+		}
+	}
+
+	if query.IsEmpty() {
+		return nil, errors.New("the query doesn't contain any terms or filters")
+	}
+
+	return query, nil
+}
+
+// IsEmpty checks whether the query would match every message.
+func (query *SearchQuery) IsEmpty() bool {
+	return len(query.Terms) == 0 && query.From == "" && query.In == "" &&
+		!query.HasAttachment && !query.HasEmbed && query.Before.IsZero() && query.After.IsZero()
+}
+
+// MatchesChannel checks whether the channel passes the "in:" filter. If the
+// filter isn't set, all channels match.
+func (query *SearchQuery) MatchesChannel(channel *discordgo.Channel) bool {
+	if query.In == "" {
+		return true
+	}
+
+	return ChannelMatches(channel, query.In)
+}
+
+// Matches checks whether the message meets all criteria of the query,
+// except for the "in:" filter, which is checked by MatchesChannel.
+func (query *SearchQuery) Matches(message *discordgo.Message) bool {
+	if query.From != "" && !matchesUser(message.Author, query.From) {
+		return false
+	}
+
+	if query.HasAttachment && len(message.Attachments) == 0 {
+		return false
+	}
+
+	if query.HasEmbed && len(message.Embeds) == 0 {
+		return false
+	}
+
+	if !query.Before.IsZero() || !query.After.IsZero() {
+		timestamp, parseError := message.Timestamp.Parse()
+		if parseError != nil {
+			return false
+		}
+		if !query.Before.IsZero() && !timestamp.Before(query.Before) {
+			return false
+		}
+		if !query.After.IsZero() && timestamp.Before(query.After) {
+			return false
+		}
+	}
+
+	content := strings.ToLower(message.Content)
+	for _, term := range query.Terms {
+		if !strings.Contains(content, term) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func matchesUser(user *discordgo.User, filter string) bool {
+	if user == nil {
+		return false
+	}
+
+	if user.ID == filter || strings.EqualFold(user.Username, filter) {
+		return true
+	}
+
+	return strings.EqualFold(user.Username+"#"+user.Discriminator, filter)
+}
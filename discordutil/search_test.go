@@ -0,0 +1,110 @@
+package discordutil
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Bios-Marcel/discordgo"
+)
+
+func TestParseSearchQuery(t *testing.T) {
+	query, parseError := ParseSearchQuery([]string{"Hello", "from:Marcel#1234", "in:#general", "has:attachment", "after:2019-10-01", "before:2019-10-03", "at", "12:00"})
+	if parseError != nil {
+		t.Fatalf("Unexpected error: %s", parseError)
+	}
+
+	if len(query.Terms) != 3 || query.Terms[0] != "hello" || query.Terms[1] != "at" || query.Terms[2] != "12:00" {
+		t.Errorf("Terms were %q", query.Terms)
+	}
+	if query.From != "Marcel#1234" {
+		t.Errorf("From was %s", query.From)
+	}
+	if query.In != "#general" {
+		t.Errorf("In was %s", query.In)
+	}
+	if !query.HasAttachment || query.HasEmbed {
+		t.Errorf("HasAttachment was %v and HasEmbed was %v", query.HasAttachment, query.HasEmbed)
+	}
+	if !query.After.Equal(time.Date(2019, 10, 2, 0, 0, 0, 0, time.Local)) {
+		t.Errorf("After was %s", query.After)
+	}
+	if !query.Before.Equal(time.Date(2019, 10, 3, 0, 0, 0, 0, time.Local)) {
+		t.Errorf("Before was %s", query.Before)
+	}
+
+	for _, invalid := range [][]string{nil, {"has:nothing"}, {"before:yesterday"}, {"after:2019-13-01"}} {
+		if _, parseError := ParseSearchQuery(invalid); parseError == nil {
+			t.Errorf("Expected an error for %q", invalid)
+		}
+	}
+}
+
+func TestSearchQueryMatches(t *testing.T) {
+	timestamp := func(date time.Time) discordgo.Timestamp {
+		return discordgo.Timestamp(date.Format(time.RFC3339))
+	}
+	message := &discordgo.Message{
+		Content:     "Hello World",
+		Author:      &discordgo.User{ID: "1", Username: "Marcel", Discriminator: "1234"},
+		Timestamp:   timestamp(time.Date(2019, 10, 2, 12, 0, 0, 0, time.Local)),
+		Attachments: []*discordgo.MessageAttachment{{Filename: "cat.png"}},
+	}
+
+	tests := []struct {
+		terms []string
+		want  bool
+	}{
+		{[]string{"world"}, true},
+		{[]string{"hello", "moon"}, false},
+		{[]string{"from:marcel"}, true},
+		{[]string{"from:Marcel#1234"}, true},
+		{[]string{"from:1"}, true},
+		{[]string{"from:Marcel#4321"}, false},
+		{[]string{"has:attachment"}, true},
+		{[]string{"has:embed"}, false},
+		{[]string{"after:2019-10-01", "before:2019-10-03"}, true},
+		{[]string{"after:2019-10-02"}, false},
+		{[]string{"before:2019-10-02"}, false},
+		{[]string{"in:general"}, true},
+	}
+	for _, tt := range tests {
+		query, parseError := ParseSearchQuery(tt.terms)
+		if parseError != nil {
+			t.Fatalf("Unexpected error for %q: %s", tt.terms, parseError)
+		}
+		if got := query.Matches(message); got != tt.want {
+			t.Errorf("Matches() for %q = %v, want %v", tt.terms, got, tt.want)
+		}
+	}
+}
+
+func TestSearchQueryMatchesChannel(t *testing.T) {
+	guildChannel := &discordgo.Channel{ID: "1", Name: "general", Type: discordgo.ChannelTypeGuildText}
+	dmChannel := &discordgo.Channel{
+		ID:         "2",
+		Type:       discordgo.ChannelTypeDM,
+		Recipients: []*discordgo.User{{Username: "Marcel"}},
+	}
+
+	tests := []struct {
+		in          string
+		wantGuild   bool
+		wantPrivate bool
+	}{
+		{"", true, true},
+		{"#general", true, false},
+		{"General", true, false},
+		{"1", true, false},
+		{"marcel", false, true},
+		{"random", false, false},
+	}
+	for _, tt := range tests {
+		query := &SearchQuery{In: tt.in}
+		if got := query.MatchesChannel(guildChannel); got != tt.wantGuild {
+			t.Errorf("MatchesChannel() for '%s' and the guild channel = %v, want %v", tt.in, got, tt.wantGuild)
+		}
+		if got := query.MatchesChannel(dmChannel); got != tt.wantPrivate {
+			t.Errorf("MatchesChannel() for '%s' and the private channel = %v, want %v", tt.in, got, tt.wantPrivate)
+		}
+	}
+}
@@ -137,3 +137,28 @@ func IsBlocked(state *discordgo.State, user *discordgo.User) bool {
 
 	return false
 }
+
+// FindMembers returns all members of the guild that match the given input.
+// The input can be a user ID, a username with discriminator
+// ("name#1234"), a username or a nickname. If the input matches a user
+// exactly by ID or username with discriminator, only that member is
+// returned.
+func FindMembers(state *discordgo.State, guildID, input string) []*discordgo.Member {
+	guild, cacheError := state.Guild(guildID)
+	if cacheError != nil {
+		return nil
+	}
+
+	var matches []*discordgo.Member
+	for _, member := range guild.Members {
+		if member.User.ID == input || member.User.String() == input {
+			return []*discordgo.Member{member}
+		}
+
+		if member.User.Username == input || (member.Nick != "" && member.Nick == input) {
+			matches = append(matches, member)
+		}
+	}
+
+	return matches
+}
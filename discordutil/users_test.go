@@ -1,6 +1,8 @@
 package discordutil
 
 import (
+	"reflect"
+	"sort"
 	"testing"
 
 	"github.com/Bios-Marcel/discordgo"
@@ -143,3 +145,39 @@ func TestGetMemberName(t *testing.T) {
 		})
 	}
 }
+
+func TestFindMembers(t *testing.T) {
+	state := discordgo.NewState()
+	stateError := state.GuildAdd(&discordgo.Guild{
+		ID: "10",
+		Members: []*discordgo.Member{
+			{GuildID: "10", User: &discordgo.User{ID: "1", Username: "Marcel", Discriminator: "1234"}},
+			{GuildID: "10", User: &discordgo.User{ID: "2", Username: "Marcel", Discriminator: "4321"}},
+			{GuildID: "10", User: &discordgo.User{ID: "3", Username: "Other", Discriminator: "0001"}, Nick: "Nickname"},
+		},
+	})
+	if stateError != nil {
+		t.Fatal(stateError)
+	}
+
+	tests := []struct {
+		input string
+		want  []string
+	}{
+		{"Marcel#4321", []string{"2"}},
+		{"1", []string{"1"}},
+		{"Marcel", []string{"1", "2"}},
+		{"Nickname", []string{"3"}},
+		{"Nobody", nil},
+	}
+	for _, tt := range tests {
+		var got []string
+		for _, member := range FindMembers(state, "10", tt.input) {
+			got = append(got, member.User.ID)
+		}
+		sort.Strings(got)
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("FindMembers(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package readstate
 
 import (
+	"errors"
 	"strconv"
 	"sync"
 	"time"
@@ -10,14 +11,37 @@ import (
 )
 
 var (
+	// data is accessed by the UI and by background acknowledgements and
+	// therefore has to be guarded by dataMutex.
 	data       = make(map[string]uint64)
+	dataMutex  = &sync.RWMutex{}
 	timerMutex = &sync.Mutex{}
 	ackTimers  = make(map[string]*time.Timer)
 	state      *discordgo.State
+
+	mentionMutex  = &sync.Mutex{}
+	mentionCounts = make(map[string]int)
+
+	bulkAckMutex   = &sync.Mutex{}
+	bulkAckRunning bool
 )
 
+// ErrBulkAckRunning is returned by UpdateReadForChannels if channels are
+// already being marked as read in the background.
+var ErrBulkAckRunning = errors.New("channels are already being marked as read, please wait until that has finished")
+
+// bulkAckInterval is the delay between two acknowledgements sent by
+// UpdateReadForChannels, so that marking a lot of channels as read at once
+// doesn't hammer the Discord API.
+const bulkAckInterval = 500 * time.Millisecond
+
 // Load loads the locally saved readmarkers returing an error if this failed.
 func Load(sessionState *discordgo.State) {
+	dataMutex.Lock()
+	defer dataMutex.Unlock()
+	mentionMutex.Lock()
+	defer mentionMutex.Unlock()
+
 	for _, channelState := range sessionState.ReadState {
 		lastMessageID := channelState.GetLastMessageID()
 		if lastMessageID == "" {
@@ -30,6 +54,9 @@ func Load(sessionState *discordgo.State) {
 		}
 
 		data[channelState.ID] = parsed
+		if channelState.MentionCount > 0 {
+			mentionCounts[channelState.ID] = channelState.MentionCount
+		}
 	}
 
 	state = sessionState
@@ -37,10 +64,27 @@ func Load(sessionState *discordgo.State) {
 
 // ClearReadStateFor clears all entries for the given Channel.
 func ClearReadStateFor(channelID string) {
-	timerMutex.Lock()
+	dataMutex.Lock()
 	delete(data, channelID)
+	dataMutex.Unlock()
+
+	timerMutex.Lock()
 	delete(ackTimers, channelID)
 	timerMutex.Unlock()
+
+	resetMentions(channelID)
+}
+
+// GetLastReadMessageID returns the ID of the last message that has been
+// acknowledged in the given channel. If there's no known acknowledgement, an
+// empty string is returned.
+func GetLastReadMessageID(channelID string) string {
+	lastMessageID, isPresent := getLastRead(channelID)
+	if !isPresent {
+		return ""
+	}
+
+	return strconv.FormatUint(lastMessageID, 10)
 }
 
 // UpdateReadLocal can be used to locally update the data without sending
@@ -52,13 +96,26 @@ func UpdateReadLocal(channelID string, lastMessageID string) bool {
 		return false
 	}
 
+	dataMutex.Lock()
 	old, isPresent := data[channelID]
-	if !isPresent || old < parsed {
+	updated := !isPresent || old < parsed
+	if updated {
 		data[channelID] = parsed
-		return true
 	}
+	dataMutex.Unlock()
 
-	return false
+	if updated {
+		resetMentions(channelID)
+	}
+	return updated
+}
+
+func getLastRead(channelID string) (uint64, bool) {
+	dataMutex.RLock()
+	defer dataMutex.RUnlock()
+
+	lastRead, isPresent := data[channelID]
+	return lastRead, isPresent
 }
 
 // UpdateRead tells the discord server that a channel has been read. If the
@@ -70,17 +127,112 @@ func UpdateRead(session *discordgo.Session, channel *discordgo.Channel, lastMess
 		return nil
 	}
 
+	return acknowledge(session, channel, lastMessageID)
+}
+
+// acknowledge updates the local data and tells the discord server that the
+// channel has been read up to the given message.
+func acknowledge(session *discordgo.Session, channel *discordgo.Channel, lastMessageID string) error {
 	parsed, parseError := strconv.ParseUint(lastMessageID, 10, 64)
 	if parseError != nil {
 		return parseError
 	}
 
+	dataMutex.Lock()
 	data[channel.ID] = parsed
+	dataMutex.Unlock()
+	resetMentions(channel.ID)
 
 	_, ackError := session.ChannelMessageAck(channel.ID, lastMessageID, "")
 	return ackError
 }
 
+// needsAcknowledgement checks whether the latest message of the channel
+// hasn't been acknowledged yet. Unlike HasBeenRead, this doesn't treat
+// muted channels as read, since they might still contain mentions.
+func needsAcknowledgement(channel *discordgo.Channel) bool {
+	if channel.LastMessageID == "" {
+		return false
+	}
+
+	parsed, parseError := strconv.ParseUint(channel.LastMessageID, 10, 64)
+	if parseError != nil {
+		return false
+	}
+
+	lastRead, isPresent := getLastRead(channel.ID)
+	return !isPresent || lastRead < parsed
+}
+
+// GetUnreadGuildChannels returns all text channels of the given guild that
+// the current user can read and that haven't been acknowledged yet.
+func GetUnreadGuildChannels(guildID string) []*discordgo.Channel {
+	guild, cacheError := state.Guild(guildID)
+	if cacheError != nil {
+		return nil
+	}
+
+	var unreadChannels []*discordgo.Channel
+	for _, channel := range guild.Channels {
+		if channel.Type == discordgo.ChannelTypeGuildText && needsAcknowledgement(channel) &&
+			discordutil.HasReadMessagesPermission(channel.ID, state) {
+			unreadChannels = append(unreadChannels, channel)
+		}
+	}
+
+	return unreadChannels
+}
+
+// GetUnreadPrivateChannels returns all private chats that haven't been
+// acknowledged yet.
+func GetUnreadPrivateChannels() []*discordgo.Channel {
+	var unreadChannels []*discordgo.Channel
+	for _, channel := range state.PrivateChannels {
+		if needsAcknowledgement(channel) {
+			unreadChannels = append(unreadChannels, channel)
+		}
+	}
+
+	return unreadChannels
+}
+
+// UpdateReadForChannels acknowledges the latest message of each of the given
+// channels, waiting a bit between the single acknowledgements. After each
+// channel, onRead is called, so that the UI can be updated. The first error
+// that occurs aborts the process and is returned. Only one call can run at a
+// time, any further call fails with ErrBulkAckRunning.
+func UpdateReadForChannels(session *discordgo.Session, channels []*discordgo.Channel, onRead func(channel *discordgo.Channel)) error {
+	bulkAckMutex.Lock()
+	if bulkAckRunning {
+		bulkAckMutex.Unlock()
+		return ErrBulkAckRunning
+	}
+	bulkAckRunning = true
+	bulkAckMutex.Unlock()
+
+	defer func() {
+		bulkAckMutex.Lock()
+		bulkAckRunning = false
+		bulkAckMutex.Unlock()
+	}()
+
+	for index, channel := range channels {
+		if index > 0 {
+			time.Sleep(bulkAckInterval)
+		}
+
+		if ackError := acknowledge(session, channel, channel.LastMessageID); ackError != nil {
+			return ackError
+		}
+
+		if onRead != nil {
+			onRead(channel)
+		}
+	}
+
+	return nil
+}
+
 // UpdateReadBuffered triggers an acknowledgement after a certain amount of
 // seconds. If this message is called again during that time, the timer will
 // be reset. This avoid unnecessarily many calls to the Discord servers.
@@ -101,6 +253,97 @@ func UpdateReadBuffered(session *discordgo.Session, channel *discordgo.Channel,
 	timerMutex.Unlock()
 }
 
+// AddMention increases the number of unread mentions of the current user in
+// the given channel. The count is reset as soon as the channel is read.
+func AddMention(channelID string) {
+	mentionMutex.Lock()
+	mentionCounts[channelID]++
+	mentionMutex.Unlock()
+}
+
+// GetMentionCount returns the number of unread mentions of the current user
+// in the given channel.
+func GetMentionCount(channelID string) int {
+	mentionMutex.Lock()
+	defer mentionMutex.Unlock()
+
+	return mentionCounts[channelID]
+}
+
+func resetMentions(channelID string) {
+	mentionMutex.Lock()
+	delete(mentionCounts, channelID)
+	mentionMutex.Unlock()
+}
+
+// GetUnreadCount returns the number of messages in the cache of the given
+// channel, that are newer than the last read message and haven't been sent
+// by the current user. Since the cache doesn't necessarily contain all
+// unread messages, complete tells whether the count is exact. For channels
+// without any cached unread messages, for example because they haven't been
+// loaded yet, the read state still tells that there's at least one unread
+// message.
+func GetUnreadCount(channel *discordgo.Channel) (count int, complete bool) {
+	if HasBeenRead(channel, channel.LastMessageID) {
+		return 0, true
+	}
+
+	state.RLock()
+	defer state.RUnlock()
+
+	lastRead, isPresent := getLastRead(channel.ID)
+	for _, message := range channel.Messages {
+		parsed, parseError := strconv.ParseUint(message.ID, 10, 64)
+		if parseError != nil {
+			continue
+		}
+
+		if !isPresent || parsed > lastRead {
+			if message.Author == nil || message.Author.ID != state.User.ID {
+				count++
+			}
+		} else {
+			// The oldest unread message is the first one that is newer
+			// than a message that has already been read.
+			complete = true
+		}
+	}
+
+	if count == 0 {
+		return 1, false
+	}
+
+	return count, complete
+}
+
+// GetGuildUnreadCount sums up the unread messages and mentions of all
+// channels of the given guild that the current user can read. Unread
+// messages of muted guilds aren't counted, mentions however are.
+func GetGuildUnreadCount(guildID string) (count int, complete bool, mentions int) {
+	complete = true
+	guild, cacheError := state.Guild(guildID)
+	if cacheError != nil {
+		return
+	}
+
+	muted := IsGuildMuted(guildID)
+	for _, channel := range guild.Channels {
+		if channel.Type != discordgo.ChannelTypeGuildText ||
+			!discordutil.HasReadMessagesPermission(channel.ID, state) {
+			continue
+		}
+
+		mentions += GetMentionCount(channel.ID)
+		if !muted {
+			channelCount, channelComplete := GetUnreadCount(channel)
+			count += channelCount
+			complete = complete && channelComplete
+		}
+	}
+
+	return
+}
+
 // IsGuildMuted returns whether the user muted the given guild.
 func IsGuildMuted(guildID string) bool {
 	for _, settings := range state.UserGuildSettings {
@@ -179,7 +422,7 @@ func HasBeenRead(channel *discordgo.Channel, lastMessageID string) bool {
 		return true
 	}
 
-	data, present := data[channel.ID]
+	lastRead, present := getLastRead(channel.ID)
 	if !present {
 		return false
 	}
@@ -189,5 +432,5 @@ func HasBeenRead(channel *discordgo.Channel, lastMessageID string) bool {
 		return true
 	}
 
-	return data >= parsed
+	return lastRead >= parsed
 }
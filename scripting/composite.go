@@ -0,0 +1,164 @@
+package scripting
+
+import (
+	"io"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// CompositeEngine passes every call on to multiple engines, allowing
+// scripts written in different languages to be used at the same time.
+type CompositeEngine struct {
+	engines []Engine
+	host    Host
+}
+
+var _ Engine = &CompositeEngine{}
+
+// NewCompositeEngine creates an Engine that fans out to the given engines.
+// The engines are called in the given order.
+func NewCompositeEngine(engines ...Engine) *CompositeEngine {
+	return &CompositeEngine{engines: engines}
+}
+
+// LoadScripts implements Engine. All engines are given the chance to load
+// their scripts, even if one of them fails.
+func (composite *CompositeEngine) LoadScripts(dirname string) error {
+	return composite.forEachWithError(func(engine Engine) error {
+		return engine.LoadScripts(dirname)
+	})
+}
+
+// Reload implements Engine.
+func (composite *CompositeEngine) Reload() error {
+	return composite.forEachWithError(func(engine Engine) error {
+		return engine.Reload()
+	})
+}
+
+func (composite *CompositeEngine) forEachWithError(function func(engine Engine) error) error {
+	var messages []string
+	for _, engine := range composite.engines {
+		engineError := function(engine)
+		if engineError != nil {
+			messages = append(messages, engineError.Error())
+		}
+	}
+
+	if len(messages) > 0 {
+		return errors.New(strings.Join(messages, "; "))
+	}
+
+	return nil
+}
+
+// GetScripts implements Engine.
+func (composite *CompositeEngine) GetScripts() []*ScriptStatus {
+	var scripts []*ScriptStatus
+	for _, engine := range composite.engines {
+		scripts = append(scripts, engine.GetScripts()...)
+	}
+
+	return scripts
+}
+
+// SetScriptEnabled implements Engine. Since script names contain their file
+// extension, engines simply ignore names of scripts they don't know.
+func (composite *CompositeEngine) SetScriptEnabled(name string, enabled bool) {
+	for _, engine := range composite.engines {
+		engine.SetScriptEnabled(name, enabled)
+	}
+}
+
+// OnStartup implements Engine.
+func (composite *CompositeEngine) OnStartup() {
+	for _, engine := range composite.engines {
+		engine.OnStartup()
+	}
+}
+
+// OnMessageSend implements Engine. The text is passed through the engines
+// one after another, each one receiving the output of the previous one. If
+// an engine redirects the message, the following engines receive the new
+// channel. As soon as an engine cancels the message, the remaining engines
+// are skipped.
+func (composite *CompositeEngine) OnMessageSend(channel *Channel, text string) *SendResult {
+	result := &SendResult{Text: text}
+	for _, engine := range composite.engines {
+		result.Apply(engine.OnMessageSend(channel, result.Text))
+		if result.Cancel {
+			break
+		}
+		channel = result.TargetChannel(composite.host, channel)
+	}
+
+	return result
+}
+
+// OnMessageReceive implements Engine.
+func (composite *CompositeEngine) OnMessageReceive(message *Message) {
+	for _, engine := range composite.engines {
+		engine.OnMessageReceive(message)
+	}
+}
+
+// OnMessageEdit implements Engine.
+func (composite *CompositeEngine) OnMessageEdit(message *Message) {
+	for _, engine := range composite.engines {
+		engine.OnMessageEdit(message)
+	}
+}
+
+// OnMessageDelete implements Engine.
+func (composite *CompositeEngine) OnMessageDelete(message *Message) {
+	for _, engine := range composite.engines {
+		engine.OnMessageDelete(message)
+	}
+}
+
+// OnMessageRender implements Engine. Each engine receives the text
+// rendered by the previous one.
+func (composite *CompositeEngine) OnMessageRender(message *Message, text string) string {
+	for _, engine := range composite.engines {
+		text = engine.OnMessageRender(message, text)
+	}
+
+	return text
+}
+
+// OnChannelSwitch implements Engine.
+func (composite *CompositeEngine) OnChannelSwitch(channel *Channel) {
+	for _, engine := range composite.engines {
+		engine.OnChannelSwitch(channel)
+	}
+}
+
+// SetErrorOutput implements Engine.
+func (composite *CompositeEngine) SetErrorOutput(errorOutput io.Writer) {
+	for _, engine := range composite.engines {
+		engine.SetErrorOutput(errorOutput)
+	}
+}
+
+// SetHost implements Engine.
+func (composite *CompositeEngine) SetHost(host Host) {
+	composite.host = host
+	for _, engine := range composite.engines {
+		engine.SetHost(host)
+	}
+}
+
+// SetStorageDirectory implements Engine.
+func (composite *CompositeEngine) SetStorageDirectory(dirname string) {
+	for _, engine := range composite.engines {
+		engine.SetStorageDirectory(dirname)
+	}
+}
+
+// SetLimits implements Engine.
+func (composite *CompositeEngine) SetLimits(limits Limits) {
+	for _, engine := range composite.engines {
+		engine.SetLimits(limits)
+	}
+}
@@ -0,0 +1,66 @@
+package scripting_test
+
+import (
+	"testing"
+
+	"github.com/Bios-Marcel/cordless/commands"
+	"github.com/Bios-Marcel/cordless/scripting"
+	"github.com/Bios-Marcel/cordless/scripting/js"
+	"github.com/Bios-Marcel/cordless/scripting/lua"
+)
+
+func TestCompositeEngine(t *testing.T) {
+	engine := scripting.NewCompositeEngine(js.New(), lua.New())
+	if err := engine.LoadScripts("test/mixed"); err != nil {
+		t.Fatal("LoadScripts failed:", err)
+	}
+
+	scripts := engine.GetScripts()
+	if len(scripts) != 2 || scripts[0].Name != "append.js" || scripts[1].Name != "append.lua" {
+		t.Fatalf("Expected each engine to load its own script, got %v", scripts)
+	}
+
+	if output := engine.OnMessageSend(nil, "text").Text; output != "text js lua" {
+		t.Errorf("OnMessageSend() = %q, want %q", output, "text js lua")
+	}
+
+	engine.SetScriptEnabled("append.js", false)
+	if output := engine.OnMessageSend(nil, "text").Text; output != "text lua" {
+		t.Errorf("OnMessageSend() = %q, want %q", output, "text lua")
+	}
+}
+
+type channelHost struct {
+	channels map[string]*scripting.Channel
+}
+
+func (host *channelHost) SendMessage(channelID, text string) error { return nil }
+
+func (host *channelHost) GetSelectedChannel() *scripting.Channel { return nil }
+
+func (host *channelHost) GetChannel(channelID string) *scripting.Channel {
+	return host.channels[channelID]
+}
+
+func (host *channelHost) ShowNotification(title, body string) error { return nil }
+
+func (host *channelHost) Print(text string) {}
+
+func (host *channelHost) RegisterCommand(command commands.Command) error { return nil }
+
+func (host *channelHost) UnregisterCommand(command commands.Command) {}
+
+func TestCompositeEngineRedirect(t *testing.T) {
+	engine := scripting.NewCompositeEngine(js.New(), lua.New())
+	engine.SetHost(&channelHost{channels: map[string]*scripting.Channel{
+		"C2": {ID: "C2", Name: "log"},
+	}})
+	if err := engine.LoadScripts("test/redirect"); err != nil {
+		t.Fatal("LoadScripts failed:", err)
+	}
+
+	result := engine.OnMessageSend(&scripting.Channel{ID: "C1", Name: "general"}, "text")
+	if result.Text != "text log" || result.ChannelID != "C2" {
+		t.Errorf("OnMessageSend() = %+v, want the text %q in channel C2", *result, "text log")
+	}
+}
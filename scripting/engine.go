@@ -1,14 +1,74 @@
 package scripting
 
-import "io"
+import (
+	"io"
+	"time"
+)
 
 // Engine describes a type that is capable of handling events from the main
 // application and allows mutation of data.
 type Engine interface {
-	// LoadScripts loads scripts from a directory into the VM
+	// LoadScripts loads scripts from a directory into the VM. Scripts that
+	// fail to load don't prevent other scripts from being loaded, instead
+	// their errors are reported via GetScripts.
 	LoadScripts(string) error
-	// OnMessageSend handles the client sending new messages
-	OnMessageSend(string) string
+	// Reload unloads all scripts and loads them again from the directory
+	// that has previously been passed to LoadScripts.
+	Reload() error
+	// GetScripts returns the status of every script that has been found
+	// during the last load.
+	GetScripts() []*ScriptStatus
+	// SetScriptEnabled enables or disables the script with the given name.
+	// Disabled scripts aren't executed at all.
+	SetScriptEnabled(name string, enabled bool)
+	// OnStartup is called once the application has been fully initialized.
+	OnStartup()
+	// OnMessageSend handles the client sending a new message into the given
+	// channel. The scripts can change the text, redirect the message, ask
+	// for confirmation or cancel sending altogether.
+	OnMessageSend(channel *Channel, text string) *SendResult
+	// OnMessageReceive handles new incoming messages, including the ones
+	// sent by the user itself.
+	OnMessageReceive(message *Message)
+	// OnMessageEdit handles messages that have been edited.
+	OnMessageEdit(message *Message)
+	// OnMessageDelete handles messages that have been deleted. Note that
+	// the message might only contain its ID and channel, in case it wasn't
+	// cached anymore.
+	OnMessageDelete(message *Message)
+	// OnMessageRender allows changing the text that is displayed for a
+	// message. The text contains color tags and doesn't include the author
+	// and the time of the message. The message itself can't be changed.
+	OnMessageRender(message *Message, text string) string
+	// OnChannelSwitch handles the user loading a different channel.
+	OnChannelSwitch(channel *Channel)
 	// SetErrorOutput sets the io.Writer that the errors are piped into.
 	SetErrorOutput(errorOutput io.Writer)
+	// SetHost sets the Host that scripts can call into. This has to be
+	// called before loading any scripts.
+	SetHost(host Host)
+	// SetStorageDirectory sets the directory in which the storage files of
+	// the scripts are kept. If no directory is set, the storage only lives
+	// in memory. This has to be called before loading any scripts.
+	SetStorageDirectory(dirname string)
+	// SetLimits sets the limits that are applied to every script. This has
+	// to be called before loading any scripts.
+	SetLimits(limits Limits)
+}
+
+// Limits restricts the resources a single script may use, so that a faulty
+// script can't hang the application.
+type Limits struct {
+	// Timeout is the maximum time a single hook or command may take before
+	// it is aborted. Zero means no limit.
+	Timeout time.Duration
+	// MaxStackDepth limits the depth of nested function calls, preventing
+	// endless recursion from exhausting the memory. Zero means no limit.
+	MaxStackDepth int
+	// MaxFailures is the amount of consecutive failures after which a
+	// script is disabled until it is reloaded. Zero means no limit.
+	MaxFailures int
+	// MaxStorageSize is the maximum size in bytes of a scripts storage.
+	// Zero means no limit.
+	MaxStorageSize int
 }
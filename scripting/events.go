@@ -0,0 +1,150 @@
+package scripting
+
+import (
+	"github.com/Bios-Marcel/discordgo"
+)
+
+// User is the representation of a discord user that is handed to scripts.
+type User struct {
+	ID            string `json:"id"`
+	Username      string `json:"username"`
+	Discriminator string `json:"discriminator"`
+	Bot           bool   `json:"bot"`
+}
+
+// Guild is the representation of a discord guild that is handed to scripts.
+type Guild struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// Channel is the representation of a discord channel that is handed to
+// scripts. Private channels have no guild, but recipients instead.
+type Channel struct {
+	ID         string  `json:"id"`
+	Name       string  `json:"name"`
+	Topic      string  `json:"topic"`
+	Private    bool    `json:"private"`
+	Guild      *Guild  `json:"guild"`
+	Recipients []*User `json:"recipients"`
+}
+
+// Attachment is a file that has been attached to a message.
+type Attachment struct {
+	ID       string `json:"id"`
+	Filename string `json:"filename"`
+	URL      string `json:"url"`
+	Size     int    `json:"size"`
+}
+
+// Message is the representation of a discord message that is handed to
+// scripts. Channel and Guild are resolved from the state if possible.
+type Message struct {
+	ID          string        `json:"id"`
+	Content     string        `json:"content"`
+	Timestamp   string        `json:"timestamp"`
+	Edited      bool          `json:"edited"`
+	Author      *User         `json:"author"`
+	Channel     *Channel      `json:"channel"`
+	Guild       *Guild        `json:"guild"`
+	Mentions    []*User       `json:"mentions"`
+	Attachments []*Attachment `json:"attachments"`
+}
+
+// NewUser converts a discordgo.User into its scripting representation.
+func NewUser(user *discordgo.User) *User {
+	if user == nil {
+		return nil
+	}
+
+	return &User{
+		ID:            user.ID,
+		Username:      user.Username,
+		Discriminator: user.Discriminator,
+		Bot:           user.Bot,
+	}
+}
+
+// NewGuild converts a discordgo.Guild into its scripting representation.
+func NewGuild(guild *discordgo.Guild) *Guild {
+	if guild == nil {
+		return nil
+	}
+
+	return &Guild{
+		ID:   guild.ID,
+		Name: guild.Name,
+	}
+}
+
+// NewChannel converts a discordgo.Channel into its scripting representation.
+// The guild will be looked up in the given state.
+func NewChannel(state *discordgo.State, channel *discordgo.Channel) *Channel {
+	if channel == nil {
+		return nil
+	}
+
+	scriptChannel := &Channel{
+		ID:         channel.ID,
+		Name:       channel.Name,
+		Topic:      channel.Topic,
+		Private:    channel.GuildID == "",
+		Recipients: make([]*User, 0, len(channel.Recipients)),
+	}
+
+	for _, recipient := range channel.Recipients {
+		scriptChannel.Recipients = append(scriptChannel.Recipients, NewUser(recipient))
+	}
+
+	if channel.GuildID != "" {
+		guild, stateError := state.Guild(channel.GuildID)
+		if stateError == nil {
+			scriptChannel.Guild = NewGuild(guild)
+		} else {
+			scriptChannel.Guild = &Guild{ID: channel.GuildID}
+		}
+	}
+
+	return scriptChannel
+}
+
+// NewMessage converts a discordgo.Message into its scripting representation.
+// Channel and guild will be looked up in the given state. In case the
+// channel isn't present in the state, only its ID will be set.
+func NewMessage(state *discordgo.State, message *discordgo.Message) *Message {
+	scriptMessage := &Message{
+		ID:          message.ID,
+		Content:     message.Content,
+		Timestamp:   string(message.Timestamp),
+		Edited:      message.EditedTimestamp != "",
+		Author:      NewUser(message.Author),
+		Mentions:    make([]*User, 0, len(message.Mentions)),
+		Attachments: make([]*Attachment, 0, len(message.Attachments)),
+	}
+
+	channel, stateError := state.Channel(message.ChannelID)
+	if stateError == nil {
+		scriptMessage.Channel = NewChannel(state, channel)
+		scriptMessage.Guild = scriptMessage.Channel.Guild
+	} else {
+		scriptMessage.Channel = &Channel{ID: message.ChannelID}
+		if message.GuildID != "" {
+			scriptMessage.Guild = &Guild{ID: message.GuildID}
+		}
+	}
+
+	for _, user := range message.Mentions {
+		scriptMessage.Mentions = append(scriptMessage.Mentions, NewUser(user))
+	}
+
+	for _, attachment := range message.Attachments {
+		scriptMessage.Attachments = append(scriptMessage.Attachments, &Attachment{
+			ID:       attachment.ID,
+			Filename: attachment.Filename,
+			URL:      attachment.URL,
+			Size:     attachment.Size,
+		})
+	}
+
+	return scriptMessage
+}
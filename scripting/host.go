@@ -0,0 +1,27 @@
+package scripting
+
+import "github.com/Bios-Marcel/cordless/commands"
+
+// Host is the part of the application that scripts are allowed to call into.
+// Every engine injects it into its scripts in a way that fits the language.
+type Host interface {
+	// SendMessage sends the given text into the channel with the given ID.
+	// The scripting hooks won't be triggered for messages sent this way.
+	SendMessage(channelID, text string) error
+	// GetSelectedChannel returns the currently loaded channel or nil if no
+	// channel is loaded.
+	GetSelectedChannel() *Channel
+	// GetChannel returns the channel with the given ID or nil if it isn't
+	// known.
+	GetChannel(channelID string) *Channel
+	// ShowNotification shows a desktop notification.
+	ShowNotification(title, body string) error
+	// Print writes the given text into the command view.
+	Print(text string)
+	// RegisterCommand makes the given command available in the command
+	// view.
+	RegisterCommand(command commands.Command) error
+	// UnregisterCommand removes a command that has previously been
+	// registered via RegisterCommand.
+	UnregisterCommand(command commands.Command)
+}
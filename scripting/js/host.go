@@ -0,0 +1,249 @@
+package js
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/Bios-Marcel/cordless/commands"
+	"github.com/Bios-Marcel/cordless/scripting"
+	"github.com/robertkrimen/otto"
+)
+
+// injectHost adds the global "cordless" object to the given VM. The object
+// exposes the functions of the engines Host to the script.
+func (engine *JavaScriptEngine) injectHost(vm *otto.Otto) error {
+	if engine.Host() == nil {
+		return nil
+	}
+
+	hostObject, objectError := vm.Object("({})")
+	if objectError != nil {
+		return objectError
+	}
+
+	functions := map[string]func(call otto.FunctionCall) otto.Value{
+		"sendMessage":        engine.jsSendMessage,
+		"getSelectedChannel": engine.jsGetSelectedChannel,
+		"showNotification":   engine.jsShowNotification,
+		"print":              engine.jsPrint,
+		"registerCommand":    engine.jsRegisterCommand,
+	}
+	for name, function := range functions {
+		setError := hostObject.Set(name, function)
+		if setError != nil {
+			return setError
+		}
+	}
+
+	return vm.Set("cordless", hostObject)
+}
+
+func (engine *JavaScriptEngine) jsSendMessage(call otto.FunctionCall) otto.Value {
+	if len(call.ArgumentList) != 2 {
+		panic(call.Otto.MakeTypeError("sendMessage requires a channelID and a text"))
+	}
+
+	sendError := engine.Host().SendMessage(call.Argument(0).String(), call.Argument(1).String())
+	if sendError != nil {
+		panic(call.Otto.MakeCustomError("SendError", sendError.Error()))
+	}
+
+	return otto.UndefinedValue()
+}
+
+func (engine *JavaScriptEngine) jsGetSelectedChannel(call otto.FunctionCall) otto.Value {
+	channel := engine.Host().GetSelectedChannel()
+	if channel == nil {
+		return otto.NullValue()
+	}
+
+	return engine.toValue(call.Otto, channel)
+}
+
+func (engine *JavaScriptEngine) jsShowNotification(call otto.FunctionCall) otto.Value {
+	notifyError := engine.Host().ShowNotification(call.Argument(0).String(), call.Argument(1).String())
+	if notifyError != nil {
+		panic(call.Otto.MakeCustomError("NotificationError", notifyError.Error()))
+	}
+
+	return otto.UndefinedValue()
+}
+
+func (engine *JavaScriptEngine) jsPrint(call otto.FunctionCall) otto.Value {
+	engine.Host().Print(joinArguments(call.ArgumentList))
+	return otto.UndefinedValue()
+}
+
+// jsRegisterCommand registers a new command. It either takes a name and a
+// function or a single object that describes the command via the
+// properties "name", "aliases", "help" and "execute".
+func (engine *JavaScriptEngine) jsRegisterCommand(call otto.FunctionCall) otto.Value {
+	owner := engine.FindScript(call.Otto)
+	if owner == nil {
+		panic(call.Otto.MakeCustomError("CommandError", "commands can only be registered by loaded scripts"))
+	}
+
+	command := &jsCommand{
+		engine: engine,
+		script: owner,
+	}
+
+	descriptor := call.Argument(0)
+	if descriptor.IsObject() && !descriptor.IsFunction() {
+		object := descriptor.Object()
+		command.name = getStringProperty(object, "name")
+		command.help = getStringProperty(object, "help")
+		command.execute, _ = object.Get("execute")
+
+		aliases, _ := object.Get("aliases")
+		if aliases.IsObject() {
+			aliasesObject := aliases.Object()
+			for _, key := range aliasesObject.Keys() {
+				alias, _ := aliasesObject.Get(key)
+				command.aliases = append(command.aliases, alias.String())
+			}
+		}
+	} else {
+		command.name = descriptor.String()
+		command.execute = call.Argument(1)
+	}
+
+	if command.name == "" || strings.ContainsAny(command.name, " \t\n") || !command.execute.IsFunction() {
+		panic(call.Otto.MakeTypeError("registerCommand requires a name without whitespace and an execute function"))
+	}
+
+	registerError := engine.Host().RegisterCommand(command)
+	if registerError != nil {
+		panic(call.Otto.MakeCustomError("CommandError", registerError.Error()))
+	}
+
+	owner.Commands = append(owner.Commands, command)
+
+	return otto.UndefinedValue()
+}
+
+// getStringProperty returns the value of the given property or an empty
+// string if the property isn't defined.
+func getStringProperty(object *otto.Object, name string) string {
+	value, getError := object.Get(name)
+	if getError != nil || !value.IsDefined() || value.IsNull() {
+		return ""
+	}
+
+	return value.String()
+}
+
+// toValue converts the given value into a javascript object. In case of
+// failure, the current call is aborted with a javascript error.
+func (engine *JavaScriptEngine) toValue(vm *otto.Otto, value interface{}) otto.Value {
+	object, conversionError := toJavaScriptObject(value)
+	if conversionError != nil {
+		panic(vm.MakeCustomError("ConversionError", conversionError.Error()))
+	}
+
+	jsValue, conversionError := vm.ToValue(object)
+	if conversionError != nil {
+		panic(vm.MakeCustomError("ConversionError", conversionError.Error()))
+	}
+
+	return jsValue
+}
+
+// newArray creates a real javascript array, since otto would otherwise
+// wrap the Go slice, which lacks most of the array functions.
+func newArray(vm *otto.Otto, values []string) (*otto.Object, error) {
+	array, objectError := vm.Object("[]")
+	if objectError != nil {
+		return nil, objectError
+	}
+
+	for _, value := range values {
+		_, pushError := array.Call("push", value)
+		if pushError != nil {
+			return nil, pushError
+		}
+	}
+
+	return array, nil
+}
+
+func joinArguments(arguments []otto.Value) string {
+	parts := make([]string, 0, len(arguments))
+	for _, argument := range arguments {
+		parts = append(parts, argument.String())
+	}
+
+	return strings.Join(parts, " ")
+}
+
+// jsCommand is a command that has been registered by a script. All output
+// of the script is written into the writer passed on execution.
+type jsCommand struct {
+	engine  *JavaScriptEngine
+	script  *scripting.Script
+	name    string
+	aliases []string
+	help    string
+	execute otto.Value
+}
+
+var _ commands.Command = &jsCommand{}
+
+// Execute calls the scripts function, passing the parameters as an array
+// and an output object that allows writing into the command output.
+func (cmd *jsCommand) Execute(writer io.Writer, parameters []string) {
+	cmd.engine.Lock()
+	defer cmd.engine.Unlock()
+
+	if cmd.script.VM == nil {
+		fmt.Fprintf(writer, "[red]The script that registered the command '%s' isn't loaded anymore.\n", cmd.name)
+		return
+	}
+
+	vm := vmOf(cmd.script)
+	jsParameters, arrayError := newArray(vm, parameters)
+	if arrayError != nil {
+		fmt.Fprintf(writer, "[red]Error executing command '%s':\n\t[red]%s\n", cmd.name, arrayError)
+		return
+	}
+
+	output, objectError := vm.Object("({})")
+	if objectError != nil {
+		fmt.Fprintf(writer, "[red]Error executing command '%s':\n\t[red]%s\n", cmd.name, objectError)
+		return
+	}
+	output.Set("print", func(call otto.FunctionCall) otto.Value {
+		fmt.Fprintln(writer, joinArguments(call.ArgumentList))
+		return otto.UndefinedValue()
+	})
+	output.Set("write", func(call otto.FunctionCall) otto.Value {
+		fmt.Fprint(writer, joinArguments(call.ArgumentList))
+		return otto.UndefinedValue()
+	})
+
+	_, callError := cmd.engine.execute(cmd.script, func() (otto.Value, error) {
+		return cmd.execute.Call(otto.NullValue(), jsParameters, output)
+	})
+	cmd.engine.RecordResult(cmd.script, callError)
+	if callError != nil {
+		fmt.Fprintf(writer, "[red]Error executing command '%s':\n\t[red]%s\n", cmd.name, callError)
+	}
+}
+
+// PrintHelp prints the help text defined by the script.
+func (cmd *jsCommand) PrintHelp(writer io.Writer) {
+	if cmd.help == "" {
+		fmt.Fprintf(writer, "The command '%s' has been registered by a script and has no help page.\n", cmd.name)
+	} else {
+		fmt.Fprintln(writer, cmd.help)
+	}
+}
+
+func (cmd *jsCommand) Name() string {
+	return cmd.name
+}
+
+func (cmd *jsCommand) Aliases() []string {
+	return cmd.aliases
+}
@@ -1,12 +1,10 @@
 package js
 
 import (
+	"encoding/json"
 	"fmt"
-	"io"
-	"io/ioutil"
 	"os"
-	"path/filepath"
-	"strings"
+	"time"
 
 	"github.com/Bios-Marcel/cordless/scripting"
 	"github.com/pkg/errors"
@@ -18,99 +16,280 @@ var _ scripting.Engine = &JavaScriptEngine{}
 
 // JavaScriptEngine stores scripting engine state
 type JavaScriptEngine struct {
-	vms         []*otto.Otto
-	errorOutput io.Writer
+	// ScriptSet takes care of loading the scripts and provides the lock
+	// that prevents concurrent access to the VMs, since otto isn't
+	// threadsafe and events are fired from multiple goroutines.
+	*scripting.ScriptSet
 }
 
+// errTimeout is used to abort scripts that exceed the configured timeout.
+var errTimeout = errors.New("script execution timed out")
+
 // New instantiates a new scripting engine
 func New() (engine *JavaScriptEngine) {
-	engine = &JavaScriptEngine{
-		vms: make([]*otto.Otto, 0),
-	}
+	engine = &JavaScriptEngine{}
+	engine.ScriptSet = scripting.NewScriptSet(".js", engine.startScript, nil)
 
 	return
 }
 
-// LoadScripts implements Engine
-func (engine *JavaScriptEngine) LoadScripts(dirname string) (err error) {
-	_, statError := os.Stat(dirname)
-	if os.IsNotExist(statError) {
-		return nil
-	} else if statError != nil {
-		return errors.Wrapf(statError, "Error loading scripts '%s'", statError.Error())
+// startScript creates a new VM for the given script and runs it.
+func (engine *JavaScriptEngine) startScript(script *scripting.Script) error {
+	file, openError := os.Open(script.Path)
+	if openError != nil {
+		return openError
 	}
+	defer file.Close()
 
-	return engine.readScriptsRecursively(dirname)
-}
+	vm := otto.New()
+	vm.SetStackDepthLimit(engine.Limits().MaxStackDepth)
+	script.VM = vm
+	injectError := engine.injectHost(vm)
+	if injectError == nil {
+		injectError = engine.injectStorage(script)
+	}
+	if injectError != nil {
+		return errors.Wrap(injectError, "failed to prepare script")
+	}
 
-func (engine *JavaScriptEngine) readScriptsRecursively(dirname string) error {
-	files, err := ioutil.ReadDir(dirname)
-	if err != nil {
-		return err
+	_, runError := engine.execute(script, func() (otto.Value, error) {
+		return vm.Run(file)
+	})
+	if runError != nil {
+		return errors.Wrap(runError, "failed to run script")
 	}
 
-	for _, file := range files {
-		path := filepath.Join(dirname, file.Name())
+	return nil
+}
+
+// vmOf returns the VM the given script is running in.
+func vmOf(script *scripting.Script) *otto.Otto {
+	return script.VM.(*otto.Otto)
+}
+
+// execute runs the given function, which is expected to call into the
+// scripts VM. If the execution exceeds the timeout, it is interrupted and
+// errTimeout is returned.
+func (engine *JavaScriptEngine) execute(script *scripting.Script, function func() (otto.Value, error)) (value otto.Value, err error) {
+	timeout := engine.Limits().Timeout
+	if timeout > 0 {
+		//Each execution gets its own channel, so that a timer firing after
+		//the execution has finished can't interrupt the next execution.
+		interrupt := make(chan func(), 1)
+		vmOf(script).Interrupt = interrupt
+		timer := time.AfterFunc(timeout, func() {
+			interrupt <- func() {
+				panic(errTimeout)
+			}
+		})
+		defer timer.Stop()
+	}
 
-		//Skip dotfolders and read non-dotfolders.
-		if file.IsDir() {
-			if !strings.HasPrefix(file.Name(), ".") {
-				readError := engine.readScriptsRecursively(path)
-				if readError != nil {
-					return readError
-				}
+	defer func() {
+		if caught := recover(); caught != nil {
+			if caught != errTimeout {
+				panic(caught)
 			}
+			err = errors.Wrapf(errTimeout, "exceeded %s", timeout)
+		}
+	}()
+
+	return function()
+}
+
+// OnMessageSend implements Engine
+func (engine *JavaScriptEngine) OnMessageSend(channel *scripting.Channel, text string) *scripting.SendResult {
+	engine.Lock()
+	defer engine.Unlock()
+
+	result := &scripting.SendResult{Text: text}
+	jsChannel, conversionError := toJavaScriptObject(channel)
+	if conversionError != nil {
+		engine.printError("onMessageSend", conversionError)
+		return result
+	}
 
+	for _, script := range engine.ActiveScripts() {
+		hook, getError := vmOf(script).Get("onMessageSend")
+		if getError != nil || !hook.IsFunction() {
 			continue
 		}
 
-		//Only javascript files
-		if !strings.HasSuffix(file.Name(), ".js") {
+		//The text is passed as a value, letting otto take care of the
+		//conversion, so that no input can break out of the string.
+		jsValue, jsError := engine.execute(script, func() (otto.Value, error) {
+			return hook.Call(otto.NullValue(), result.Text, jsChannel)
+		})
+		engine.RecordResult(script, jsError)
+		if jsError != nil {
+			engine.printError("onMessageSend", jsError)
+			//This script failed, go to next one
 			continue
 		}
 
-		file, err := os.Open(path)
-		if err != nil {
-			return errors.Wrap(err, path)
+		result.Apply(toSendResult(jsValue, result.Text))
+		if result.Cancel {
+			break
 		}
 
-		vm := otto.New()
-		engine.vms = append(engine.vms, vm)
-		_, err = vm.Run(file)
-		if err != nil {
-			return errors.Wrapf(err, "failed to run script '%s'", path)
+		if target := result.TargetChannel(engine.Host(), channel); target != channel {
+			channel = target
+			jsChannel, conversionError = toJavaScriptObject(channel)
+			if conversionError != nil {
+				engine.printError("onMessageSend", conversionError)
+				return result
+			}
 		}
 	}
 
-	return nil
+	return result
+}
+
+// toSendResult interprets the return value of onMessageSend. Scripts can
+// either return the new text or an object with the fields "text",
+// "cancel", "channel", "reason" and "confirm". Returning nothing leaves
+// the text unchanged.
+func toSendResult(value otto.Value, text string) *scripting.SendResult {
+	if !value.IsDefined() || value.IsNull() {
+		return &scripting.SendResult{Text: text}
+	}
+
+	if !value.IsObject() || value.Class() == "String" {
+		return &scripting.SendResult{Text: value.String()}
+	}
+
+	object := value.Object()
+	decision := &scripting.SendResult{
+		Text:      text,
+		ChannelID: getStringProperty(object, "channel"),
+		Reason:    getStringProperty(object, "reason"),
+	}
+	if newText, getError := object.Get("text"); getError == nil && newText.IsDefined() && !newText.IsNull() {
+		decision.Text = newText.String()
+	}
+	if cancel, getError := object.Get("cancel"); getError == nil {
+		decision.Cancel, _ = cancel.ToBoolean()
+	}
+	if confirm, getError := object.Get("confirm"); getError == nil {
+		decision.Confirm, _ = confirm.ToBoolean()
+	}
+
+	return decision
 }
 
-func (engine *JavaScriptEngine) SetErrorOutput(errorOutput io.Writer) {
-	engine.errorOutput = errorOutput
+// OnStartup implements Engine
+func (engine *JavaScriptEngine) OnStartup() {
+	engine.callHook("onStartup")
 }
 
-// OnMessageSend implements Engine
-func (engine *JavaScriptEngine) OnMessageSend(oldText string) (newText string) {
-	newText = oldText
-	for _, vm := range engine.vms {
-		jsValue, jsError := vm.Run(fmt.Sprintf("onMessageSend(\"%s\")", escapeNewlines(newText)))
+// OnMessageReceive implements Engine
+func (engine *JavaScriptEngine) OnMessageReceive(message *scripting.Message) {
+	engine.callHook("onMessageReceive", message)
+}
+
+// OnMessageEdit implements Engine
+func (engine *JavaScriptEngine) OnMessageEdit(message *scripting.Message) {
+	engine.callHook("onMessageEdit", message)
+}
+
+// OnMessageDelete implements Engine
+func (engine *JavaScriptEngine) OnMessageDelete(message *scripting.Message) {
+	engine.callHook("onMessageDelete", message)
+}
+
+// OnMessageRender implements Engine
+func (engine *JavaScriptEngine) OnMessageRender(message *scripting.Message, text string) string {
+	engine.Lock()
+	defer engine.Unlock()
+
+	jsMessage, conversionError := toJavaScriptObject(message)
+	if conversionError != nil {
+		engine.printError("onMessageRender", conversionError)
+		return text
+	}
+
+	for _, script := range engine.ActiveScripts() {
+		hook, getError := vmOf(script).Get("onMessageRender")
+		if getError != nil || !hook.IsFunction() {
+			continue
+		}
+
+		jsValue, jsError := engine.execute(script, func() (otto.Value, error) {
+			return hook.Call(otto.NullValue(), jsMessage, text)
+		})
+		engine.RecordResult(script, jsError)
 		if jsError != nil {
-			if engine.errorOutput != nil {
-				fmt.Fprintf(engine.errorOutput, "Error occurred during execution of javascript: %s", jsError.Error())
-			}
+			engine.printError("onMessageRender", jsError)
 			//This script failed, go to next one
 			continue
 		}
-		newText = jsValue.String()
+
+		if jsValue.IsDefined() && !jsValue.IsNull() {
+			text = jsValue.String()
+		}
 	}
 
-	return
+	return text
+}
+
+// OnChannelSwitch implements Engine
+func (engine *JavaScriptEngine) OnChannelSwitch(channel *scripting.Channel) {
+	engine.callHook("onChannelSwitch", channel)
+}
+
+// callHook calls the function with the given name in every VM that defines
+// it. VMs that don't define the function are skipped. The arguments are
+// converted into plain javascript objects beforehand.
+func (engine *JavaScriptEngine) callHook(name string, arguments ...interface{}) {
+	engine.Lock()
+	defer engine.Unlock()
+
+	jsArguments := make([]interface{}, 0, len(arguments))
+	for _, argument := range arguments {
+		jsArgument, conversionError := toJavaScriptObject(argument)
+		if conversionError != nil {
+			engine.printError(name, conversionError)
+			return
+		}
+		jsArguments = append(jsArguments, jsArgument)
+	}
+
+	for _, script := range engine.ActiveScripts() {
+		hook, getError := vmOf(script).Get(name)
+		if getError != nil || !hook.IsFunction() {
+			continue
+		}
+
+		_, callError := engine.execute(script, func() (otto.Value, error) {
+			return hook.Call(otto.NullValue(), jsArguments...)
+		})
+		engine.RecordResult(script, callError)
+		if callError != nil {
+			//This script failed, go to next one
+			engine.printError(name, callError)
+		}
+	}
+}
+
+func (engine *JavaScriptEngine) printError(hook string, err error) {
+	if errorOutput := engine.ErrorOutput(); errorOutput != nil {
+		fmt.Fprintf(errorOutput, "[red]Error occurred during execution of javascript hook '%s': %s\n", hook, err.Error())
+	}
 }
 
-func escapeNewlines(parameter string) string {
-	return strings.NewReplacer(
-		"\\", "\\\\",
-		"\n", "\\n",
-		"\"", "\\\"").
-		Replace(parameter)
+// toJavaScriptObject turns the given value into maps and slices, so that
+// the scripts see the same names as the json representation has.
+func toJavaScriptObject(value interface{}) (interface{}, error) {
+	asJSON, marshalError := json.Marshal(value)
+	if marshalError != nil {
+		return nil, marshalError
+	}
+
+	var object interface{}
+	unmarshalError := json.Unmarshal(asJSON, &object)
+	if unmarshalError != nil {
+		return nil, unmarshalError
+	}
+
+	return object, nil
 }
@@ -1,7 +1,17 @@
 package js
 
 import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"strings"
 	"testing"
+	"testing/quick"
+	"time"
+
+	"github.com/Bios-Marcel/cordless/commands"
+	"github.com/Bios-Marcel/cordless/scripting"
 )
 
 func TestJavaScriptEngine(t *testing.T) {
@@ -24,9 +34,347 @@ func TestJavaScriptEngine(t *testing.T) {
 				return
 			}
 
-			if gotNewText := e.OnMessageSend(tt.input); gotNewText != tt.want {
+			if gotNewText := e.OnMessageSend(nil, tt.input).Text; gotNewText != tt.want {
 				t.Errorf("JavaScriptEngine.OnMessageSend() = %v, want %v", gotNewText, tt.want)
 			}
 		})
 	}
 }
+
+func TestJavaScriptEngineEvents(t *testing.T) {
+	e := New()
+	if err := e.LoadScripts("test/events"); err != nil {
+		t.Fatal("LoadScripts failed:", err)
+	}
+
+	guild := &scripting.Guild{ID: "G1", Name: "Gophers"}
+	channel := &scripting.Channel{ID: "C1", Name: "general", Guild: guild}
+
+	e.OnStartup()
+	e.OnMessageReceive(&scripting.Message{
+		ID:      "M1",
+		Content: "Hello",
+		Author:  &scripting.User{ID: "U1", Username: "Marcel"},
+		Channel: channel,
+		Guild:   guild,
+	})
+	e.OnChannelSwitch(channel)
+	//Not defined by the script and therefore has to be skipped silently.
+	e.OnMessageDelete(&scripting.Message{ID: "M1", Channel: channel})
+
+	vm := vmOf(e.ActiveScripts()[0])
+	if started, _ := vm.Get("started"); !started.IsBoolean() || started.String() != "true" {
+		t.Errorf("onStartup wasn't called, started = %v", started)
+	}
+
+	if received, _ := vm.Run("received.join('\\n')"); received.String() != "Marcel@general: Hello" {
+		t.Errorf("onMessageReceive got %v, want %v", received, "Marcel@general: Hello")
+	}
+
+	if switchedTo, _ := vm.Get("switchedTo"); switchedTo.String() != "Gophers/general" {
+		t.Errorf("onChannelSwitch got %v, want %v", switchedTo, "Gophers/general")
+	}
+}
+
+type testHost struct {
+	sent     []string
+	printed  []string
+	commands []commands.Command
+}
+
+func (host *testHost) SendMessage(channelID, text string) error {
+	host.sent = append(host.sent, channelID+":"+text)
+	return nil
+}
+
+func (host *testHost) GetSelectedChannel() *scripting.Channel {
+	return &scripting.Channel{ID: "C1", Name: "general"}
+}
+
+func (host *testHost) GetChannel(channelID string) *scripting.Channel {
+	return &scripting.Channel{ID: channelID}
+}
+
+func (host *testHost) ShowNotification(title, body string) error {
+	return nil
+}
+
+func (host *testHost) Print(text string) {
+	host.printed = append(host.printed, text)
+}
+
+func (host *testHost) RegisterCommand(command commands.Command) error {
+	host.commands = append(host.commands, command)
+	return nil
+}
+
+func (host *testHost) UnregisterCommand(command commands.Command) {
+	for index, registered := range host.commands {
+		if registered == command {
+			host.commands = append(host.commands[:index], host.commands[index+1:]...)
+			return
+		}
+	}
+}
+
+func TestJavaScriptEngineHost(t *testing.T) {
+	host := &testHost{}
+	e := New()
+	e.SetHost(host)
+	if err := e.LoadScripts("test/host"); err != nil {
+		t.Fatal("LoadScripts failed:", err)
+	}
+
+	if len(host.commands) != 2 || host.commands[0].Name() != "greet" || host.commands[1].Name() != "shout" {
+		t.Fatalf("Expected commands 'greet' and 'shout' to be registered, got %v", host.commands)
+	}
+
+	output := &bytes.Buffer{}
+	host.commands[0].Execute(output, []string{"dear", "world"})
+	if output.String() != "Hello dear world\n" {
+		t.Errorf("Command output was %q, want %q", output.String(), "Hello dear world\n")
+	}
+
+	shout := host.commands[1]
+	if !reflect.DeepEqual(shout.Aliases(), []string{"yell"}) {
+		t.Errorf("Aliases were %v, want %v", shout.Aliases(), []string{"yell"})
+	}
+
+	output.Reset()
+	shout.PrintHelp(output)
+	if output.String() != "shout - prints the parameters in uppercase\n" {
+		t.Errorf("Help was %q", output.String())
+	}
+
+	output.Reset()
+	shout.Execute(output, []string{"quiet", "please"})
+	if output.String() != "QUIET PLEASE" {
+		t.Errorf("Command output was %q, want %q", output.String(), "QUIET PLEASE")
+	}
+
+	e.OnMessageReceive(&scripting.Message{Content: "ping", Channel: &scripting.Channel{ID: "C1"}})
+	if !reflect.DeepEqual(host.sent, []string{"C1:pong"}) {
+		t.Errorf("Sent messages were %v, want %v", host.sent, []string{"C1:pong"})
+	}
+	if !reflect.DeepEqual(host.printed, []string{"received ping"}) {
+		t.Errorf("Printed text was %v, want %v", host.printed, []string{"received ping"})
+	}
+}
+
+func TestJavaScriptEngineScriptStatus(t *testing.T) {
+	host := &testHost{}
+	errorOutput := &bytes.Buffer{}
+	e := New()
+	e.SetHost(host)
+	e.SetErrorOutput(errorOutput)
+	if err := e.LoadScripts("test/status"); err != nil {
+		t.Fatal("LoadScripts failed:", err)
+	}
+
+	scripts := e.GetScripts()
+	if len(scripts) != 2 || scripts[0].Name != "broken.js" || scripts[1].Name != "working.js" {
+		t.Fatalf("Expected scripts 'broken.js' and 'working.js', got %v", scripts)
+	}
+	if scripts[0].Error == nil {
+		t.Error("Expected broken.js to report an error")
+	}
+	if scripts[1].Error != nil {
+		t.Errorf("Expected working.js to load, got %s", scripts[1].Error)
+	}
+	if errorOutput.Len() == 0 {
+		t.Error("Expected the load error to be printed")
+	}
+
+	//The broken script registers a command before failing, which has to be
+	//removed again.
+	if len(host.commands) != 1 || host.commands[0].Name() != "working" {
+		t.Fatalf("Expected only command 'working' to be registered, got %v", host.commands)
+	}
+
+	e.SetScriptEnabled("working.js", false)
+	if len(host.commands) != 0 {
+		t.Errorf("Expected commands to be removed after disabling, got %v", host.commands)
+	}
+	if e.GetScripts()[1].Enabled {
+		t.Error("Expected working.js to be disabled")
+	}
+
+	if err := e.Reload(); err != nil {
+		t.Fatal("Reload failed:", err)
+	}
+	if len(host.commands) != 0 {
+		t.Errorf("Expected disabled script to stay unloaded after reload, got %v", host.commands)
+	}
+
+	e.SetScriptEnabled("working.js", true)
+	if len(host.commands) != 1 || host.commands[0].Name() != "working" {
+		t.Errorf("Expected command 'working' to be registered again, got %v", host.commands)
+	}
+}
+
+func TestJavaScriptEngineLimits(t *testing.T) {
+	errorOutput := &bytes.Buffer{}
+	e := New()
+	e.SetErrorOutput(errorOutput)
+	e.SetLimits(scripting.Limits{
+		Timeout:       50 * time.Millisecond,
+		MaxStackDepth: 100,
+		MaxFailures:   2,
+	})
+	if err := e.LoadScripts("test/limits"); err != nil {
+		t.Fatal("LoadScripts failed:", err)
+	}
+
+	message := &scripting.Message{Content: "Hello", Channel: &scripting.Channel{ID: "C1"}}
+	e.OnMessageReceive(message)
+	if !strings.Contains(errorOutput.String(), "timed out") {
+		t.Errorf("Expected the endless loop to time out, got %q", errorOutput.String())
+	}
+
+	e.OnChannelSwitch(message.Channel)
+	if !strings.Contains(errorOutput.String(), "Maximum call stack size exceeded") {
+		t.Errorf("Expected the recursion to be stopped, got %q", errorOutput.String())
+	}
+
+	e.OnMessageReceive(message)
+	scripts := e.GetScripts()
+	if scripts[0].Name != "loop.js" || scripts[0].Error == nil {
+		t.Errorf("Expected loop.js to be disabled after two failures, got %v", scripts[0])
+	}
+	if scripts[1].Name != "recursion.js" || scripts[1].Error != nil {
+		t.Errorf("Expected recursion.js to still be active after one failure, got %v", scripts[1])
+	}
+
+	//The disabled script mustn't be called anymore.
+	errorOutput.Reset()
+	e.OnMessageReceive(message)
+	if errorOutput.Len() != 0 {
+		t.Errorf("Expected no further errors, got %q", errorOutput.String())
+	}
+}
+
+func TestJavaScriptEngineOnMessageSendRoundTrip(t *testing.T) {
+	e := New()
+	if err := e.LoadScripts("test/identity"); err != nil {
+		t.Fatal("LoadScripts failed:", err)
+	}
+
+	inputs := []string{
+		"",
+		"carriage\rreturn",
+		"line\nfeed, line\u2028separator and paragraph\u2029separator",
+		"\"); cordless.sendMessage(\"C1\", \"injected\"); (\"",
+		"back\\slash\\\" and 'single' `back` quotes",
+		"\x00\x01\x1f control characters",
+		"emoji 😀👍🏽 and CJK 漢字",
+		"</script><!--",
+	}
+	for _, input := range inputs {
+		if output := e.OnMessageSend(nil, input).Text; output != input {
+			t.Errorf("OnMessageSend(%q) = %q", input, output)
+		}
+	}
+
+	roundTrip := func(input string) bool {
+		return e.OnMessageSend(nil, input).Text == input
+	}
+	if err := quick.Check(roundTrip, &quick.Config{MaxCount: 1000}); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestJavaScriptEngineMissingHooks(t *testing.T) {
+	errorOutput := &bytes.Buffer{}
+	e := New()
+	e.SetErrorOutput(errorOutput)
+	e.SetLimits(scripting.Limits{MaxFailures: 1})
+	if err := e.LoadScripts("test/nohooks"); err != nil {
+		t.Fatal("LoadScripts failed:", err)
+	}
+
+	if output := e.OnMessageSend(nil, "unchanged").Text; output != "unchanged" {
+		t.Errorf("OnMessageSend() = %q, want %q", output, "unchanged")
+	}
+	e.OnStartup()
+	e.OnMessageReceive(&scripting.Message{Channel: &scripting.Channel{}})
+
+	if errorOutput.Len() != 0 {
+		t.Errorf("Expected scripts without hooks to be skipped, got %q", errorOutput.String())
+	}
+	if scripts := e.GetScripts(); scripts[0].Error != nil {
+		t.Errorf("Expected script to stay active, got %s", scripts[0].Error)
+	}
+}
+
+func TestJavaScriptEngineStorage(t *testing.T) {
+	directory, tempError := ioutil.TempDir("", "cordless-storage")
+	if tempError != nil {
+		t.Fatal(tempError)
+	}
+	defer os.RemoveAll(directory)
+
+	load := func() *testHost {
+		host := &testHost{}
+		e := New()
+		e.SetHost(host)
+		e.SetStorageDirectory(directory)
+		if err := e.LoadScripts("test/storage"); err != nil {
+			t.Fatal("LoadScripts failed:", err)
+		}
+		return host
+	}
+
+	output := &bytes.Buffer{}
+	load().commands[0].Execute(output, []string{"a"})
+	//A new engine has to see the data persisted by the previous one.
+	host := load()
+	host.commands[0].Execute(output, []string{"b"})
+	host.commands[1].Execute(output, nil)
+
+	want := "1 count,last\n2 count,last\ntrue\n"
+	if output.String() != want {
+		t.Errorf("Command output was %q, want %q", output.String(), want)
+	}
+}
+
+func TestJavaScriptEngineSendResult(t *testing.T) {
+	e := New()
+	if err := e.LoadScripts("test/decisions"); err != nil {
+		t.Fatal("LoadScripts failed:", err)
+	}
+
+	general := &scripting.Channel{ID: "C1", Name: "general"}
+	announcements := &scripting.Channel{ID: "C3", Name: "announcements"}
+	tests := []struct {
+		channel *scripting.Channel
+		input   string
+		want    scripting.SendResult
+	}{
+		{general, "hello", scripting.SendResult{Text: "hello"}},
+		{general, "my password is 1234", scripting.SendResult{Text: "my password is 1234", Cancel: true, Reason: "Looks like a secret"}},
+		{announcements, "hello", scripting.SendResult{Text: "hello", Confirm: true, Reason: "This is an announcement channel"}},
+		{general, "!log hello", scripting.SendResult{Text: "hello", ChannelID: "C2"}},
+	}
+	for _, tt := range tests {
+		if result := e.OnMessageSend(tt.channel, tt.input); !reflect.DeepEqual(*result, tt.want) {
+			t.Errorf("OnMessageSend(%s, %q) = %+v, want %+v", tt.channel.Name, tt.input, *result, tt.want)
+		}
+	}
+}
+
+func TestJavaScriptEngineOnMessageRender(t *testing.T) {
+	e := New()
+	if err := e.LoadScripts("test/render"); err != nil {
+		t.Fatal("LoadScripts failed:", err)
+	}
+
+	user := &scripting.Message{Author: &scripting.User{Username: "Marcel"}}
+	if output := e.OnMessageRender(user, "I like cordless"); output != "I like [yellow]cordless[white]" {
+		t.Errorf("OnMessageRender() = %q", output)
+	}
+
+	bot := &scripting.Message{Author: &scripting.User{Username: "Bot", Bot: true}}
+	if output := e.OnMessageRender(bot, "spam"); output != "[gray](bot message hidden)" {
+		t.Errorf("OnMessageRender() = %q", output)
+	}
+}
@@ -0,0 +1,71 @@
+package js
+
+import (
+	"github.com/Bios-Marcel/cordless/scripting"
+	"github.com/robertkrimen/otto"
+)
+
+// injectStorage adds the global "storage" object to the scripts VM. The
+// object gives the script access to its persistent key/value store.
+func (engine *JavaScriptEngine) injectStorage(script *scripting.Script) error {
+	storageObject, objectError := vmOf(script).Object("({})")
+	if objectError != nil {
+		return objectError
+	}
+
+	functions := map[string]func(call otto.FunctionCall) otto.Value{
+		"get": func(call otto.FunctionCall) otto.Value {
+			var value interface{}
+			found, getError := script.Storage.Get(call.Argument(0).String(), &value)
+			if getError != nil {
+				panic(call.Otto.MakeCustomError("StorageError", getError.Error()))
+			}
+			if !found {
+				return otto.UndefinedValue()
+			}
+
+			return engine.toValue(call.Otto, value)
+		},
+		"set": func(call otto.FunctionCall) otto.Value {
+			if len(call.ArgumentList) != 2 {
+				panic(call.Otto.MakeTypeError("set requires a key and a value"))
+			}
+
+			value, exportError := call.Argument(1).Export()
+			if exportError != nil {
+				panic(call.Otto.MakeCustomError("StorageError", exportError.Error()))
+			}
+
+			setError := script.Storage.Set(call.Argument(0).String(), value)
+			if setError != nil {
+				panic(call.Otto.MakeCustomError("StorageError", setError.Error()))
+			}
+
+			return otto.UndefinedValue()
+		},
+		"delete": func(call otto.FunctionCall) otto.Value {
+			deleteError := script.Storage.Delete(call.Argument(0).String())
+			if deleteError != nil {
+				panic(call.Otto.MakeCustomError("StorageError", deleteError.Error()))
+			}
+
+			return otto.UndefinedValue()
+		},
+		"keys": func(call otto.FunctionCall) otto.Value {
+			keys, arrayError := newArray(call.Otto, script.Storage.Keys())
+			if arrayError != nil {
+				panic(call.Otto.MakeCustomError("StorageError", arrayError.Error()))
+			}
+
+			return keys.Value()
+		},
+	}
+	for name, function := range functions {
+		setError := storageObject.Set(name, function)
+		if setError != nil {
+			return setError
+		}
+	}
+
+	return vmOf(script).Set("storage", storageObject)
+}
@@ -0,0 +1,13 @@
+function onMessageSend(text, channel) {
+  if (text.indexOf("password") !== -1) {
+    return { cancel: true, reason: "Looks like a secret" };
+  }
+
+  if (channel && channel.name === "announcements") {
+    return { confirm: true, reason: "This is an announcement channel" };
+  }
+
+  if (text.indexOf("!log ") === 0) {
+    return { text: text.substring(5), channel: "C2" };
+  }
+}
@@ -0,0 +1,15 @@
+var received = [];
+var switchedTo = "";
+var started = false;
+
+function onStartup() {
+  started = true;
+}
+
+function onMessageReceive(message) {
+  received.push(message.author.username + "@" + message.channel.name + ": " + message.content);
+}
+
+function onChannelSwitch(channel) {
+  switchedTo = channel.guild.name + "/" + channel.name;
+}
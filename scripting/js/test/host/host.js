@@ -0,0 +1,20 @@
+cordless.registerCommand("greet", function(args, output) {
+  output.print("Hello", args.join(" "));
+});
+
+function onMessageReceive(message) {
+  var channel = cordless.getSelectedChannel();
+  if (channel !== null && message.channel.id === channel.id) {
+    cordless.sendMessage(channel.id, "pong");
+  }
+  cordless.print("received", message.content);
+}
+
+cordless.registerCommand({
+  name: "shout",
+  aliases: ["yell"],
+  help: "shout - prints the parameters in uppercase",
+  execute: function(args, output) {
+    output.write(args.join(" ").toUpperCase());
+  }
+});
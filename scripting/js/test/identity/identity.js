@@ -0,0 +1,5 @@
+// Returns the input unchanged, used to verify that no text gets mangled on
+// its way into the VM and back.
+function onMessageSend(input) {
+  return input;
+}
@@ -0,0 +1,7 @@
+function recurse() {
+  return recurse();
+}
+
+function onChannelSwitch(channel) {
+  recurse();
+}
@@ -0,0 +1 @@
+var loaded = true;
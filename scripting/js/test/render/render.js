@@ -0,0 +1,7 @@
+function onMessageRender(message, text) {
+  if (message.author.bot) {
+    return "[gray](bot message hidden)";
+  }
+
+  return text.replace(/cordless/g, "[yellow]cordless[white]");
+}
@@ -0,0 +1,5 @@
+cordless.registerCommand("broken", function(args, output) {
+  output.print("never reached");
+});
+
+thisFunctionDoesNotExist();
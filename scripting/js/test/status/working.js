@@ -0,0 +1,3 @@
+cordless.registerCommand("working", function(args, output) {
+  output.print("works");
+});
@@ -0,0 +1,11 @@
+cordless.registerCommand("count", function(args, output) {
+  var count = (storage.get("count") || 0) + 1;
+  storage.set("count", count);
+  storage.set("last", { args: args });
+  output.print(count, storage.keys().join(","));
+});
+
+cordless.registerCommand("forget", function(args, output) {
+  storage.delete("count");
+  output.print(storage.get("count") === undefined);
+});
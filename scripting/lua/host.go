@@ -0,0 +1,281 @@
+package lua
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/Bios-Marcel/cordless/commands"
+	"github.com/Bios-Marcel/cordless/scripting"
+	gopherlua "github.com/yuin/gopher-lua"
+)
+
+// injectHost adds the global "cordless" table to the scripts state. The
+// table exposes the functions of the engines Host to the script. The
+// builtin print function is replaced as well, since writing to the
+// standard output would break the terminal user interface.
+func (engine *LuaEngine) injectHost(script *scripting.Script) {
+	state := stateOf(script)
+	state.SetGlobal("print", state.NewFunction(engine.luaPrint))
+
+	if engine.Host() == nil {
+		return
+	}
+
+	hostTable := state.SetFuncs(state.NewTable(), map[string]gopherlua.LGFunction{
+		"sendMessage":        engine.luaSendMessage,
+		"getSelectedChannel": engine.luaGetSelectedChannel,
+		"showNotification":   engine.luaShowNotification,
+		"print":              engine.luaPrint,
+		"registerCommand":    engine.luaRegisterCommand,
+	})
+	state.SetGlobal("cordless", hostTable)
+}
+
+func (engine *LuaEngine) luaSendMessage(state *gopherlua.LState) int {
+	sendError := engine.Host().SendMessage(state.CheckString(1), state.CheckString(2))
+	if sendError != nil {
+		state.RaiseError("%s", sendError.Error())
+	}
+
+	return 0
+}
+
+func (engine *LuaEngine) luaGetSelectedChannel(state *gopherlua.LState) int {
+	channel := engine.Host().GetSelectedChannel()
+	if channel == nil {
+		state.Push(gopherlua.LNil)
+		return 1
+	}
+
+	plainChannel, conversionError := toPlainObject(channel)
+	if conversionError != nil {
+		state.RaiseError("%s", conversionError.Error())
+	}
+
+	state.Push(toLuaValue(state, plainChannel))
+	return 1
+}
+
+func (engine *LuaEngine) luaShowNotification(state *gopherlua.LState) int {
+	notifyError := engine.Host().ShowNotification(state.OptString(1, ""), state.OptString(2, ""))
+	if notifyError != nil {
+		state.RaiseError("%s", notifyError.Error())
+	}
+
+	return 0
+}
+
+func (engine *LuaEngine) luaPrint(state *gopherlua.LState) int {
+	if engine.Host() != nil {
+		engine.Host().Print(joinArguments(state))
+	}
+
+	return 0
+}
+
+// luaRegisterCommand registers a new command. It either takes a name and a
+// function or a single table that describes the command via the fields
+// "name", "aliases", "help" and "execute".
+func (engine *LuaEngine) luaRegisterCommand(state *gopherlua.LState) int {
+	owner := engine.FindScript(state)
+	if owner == nil {
+		state.RaiseError("commands can only be registered by loaded scripts")
+	}
+
+	command := &luaCommand{
+		engine: engine,
+		script: owner,
+	}
+
+	if descriptor, isTable := state.Get(1).(*gopherlua.LTable); isTable {
+		command.name = getStringField(descriptor, "name")
+		command.help = getStringField(descriptor, "help")
+		command.execute, _ = descriptor.RawGetString("execute").(*gopherlua.LFunction)
+
+		if aliases, isTable := descriptor.RawGetString("aliases").(*gopherlua.LTable); isTable {
+			for index := 1; index <= aliases.Len(); index++ {
+				command.aliases = append(command.aliases, aliases.RawGetInt(index).String())
+			}
+		}
+	} else {
+		command.name = state.OptString(1, "")
+		command.execute, _ = state.Get(2).(*gopherlua.LFunction)
+	}
+
+	if command.name == "" || strings.ContainsAny(command.name, " \t\n") || command.execute == nil {
+		state.ArgError(1, "registerCommand requires a name without whitespace and an execute function")
+	}
+
+	registerError := engine.Host().RegisterCommand(command)
+	if registerError != nil {
+		state.RaiseError("%s", registerError.Error())
+	}
+
+	owner.Commands = append(owner.Commands, command)
+
+	return 0
+}
+
+// getStringField returns the value of the given field or an empty string if
+// the field isn't defined.
+func getStringField(table *gopherlua.LTable, name string) string {
+	value := table.RawGetString(name)
+	if value == gopherlua.LNil {
+		return ""
+	}
+
+	return value.String()
+}
+
+// toLuaValue converts maps, slices and primitives, as produced by
+// toPlainObject, into their lua counterparts.
+func toLuaValue(state *gopherlua.LState, value interface{}) gopherlua.LValue {
+	switch value := value.(type) {
+	case bool:
+		return gopherlua.LBool(value)
+	case float64:
+		return gopherlua.LNumber(value)
+	case string:
+		return gopherlua.LString(value)
+	case []interface{}:
+		table := state.NewTable()
+		for _, element := range value {
+			table.Append(toLuaValue(state, element))
+		}
+		return table
+	case map[string]interface{}:
+		table := state.NewTable()
+		for key, element := range value {
+			table.RawSetString(key, toLuaValue(state, element))
+		}
+		return table
+	default:
+		return gopherlua.LNil
+	}
+}
+
+// fromLuaValue converts a lua value into maps, slices and primitives.
+// Tables that only have consecutive integer keys starting at 1 are treated
+// as arrays.
+func fromLuaValue(value gopherlua.LValue) (interface{}, error) {
+	switch value := value.(type) {
+	case *gopherlua.LNilType:
+		return nil, nil
+	case gopherlua.LBool:
+		return bool(value), nil
+	case gopherlua.LNumber:
+		return float64(value), nil
+	case gopherlua.LString:
+		return string(value), nil
+	case *gopherlua.LTable:
+		var keyCount int
+		value.ForEach(func(_, _ gopherlua.LValue) {
+			keyCount++
+		})
+
+		if keyCount > 0 && keyCount == value.Len() {
+			array := make([]interface{}, 0, keyCount)
+			for index := 1; index <= keyCount; index++ {
+				element, conversionError := fromLuaValue(value.RawGetInt(index))
+				if conversionError != nil {
+					return nil, conversionError
+				}
+				array = append(array, element)
+			}
+			return array, nil
+		}
+
+		object := make(map[string]interface{}, keyCount)
+		var conversionError error
+		value.ForEach(func(key, element gopherlua.LValue) {
+			if conversionError != nil {
+				return
+			}
+			object[key.String()], conversionError = fromLuaValue(element)
+		})
+		return object, conversionError
+	default:
+		return nil, fmt.Errorf("values of type %s can't be converted", value.Type())
+	}
+}
+
+func joinArguments(state *gopherlua.LState) string {
+	parts := make([]string, 0, state.GetTop())
+	for index := 1; index <= state.GetTop(); index++ {
+		parts = append(parts, state.ToStringMeta(state.Get(index)).String())
+	}
+
+	return strings.Join(parts, " ")
+}
+
+// luaCommand is a command that has been registered by a script. All output
+// of the script is written into the writer passed on execution.
+type luaCommand struct {
+	engine  *LuaEngine
+	script  *scripting.Script
+	name    string
+	aliases []string
+	help    string
+	execute *gopherlua.LFunction
+}
+
+var _ commands.Command = &luaCommand{}
+
+// Execute calls the scripts function, passing the parameters as a table
+// and an output table that allows writing into the command output.
+func (cmd *luaCommand) Execute(writer io.Writer, parameters []string) {
+	cmd.engine.Lock()
+	defer cmd.engine.Unlock()
+
+	if cmd.script.VM == nil {
+		fmt.Fprintf(writer, "[red]The script that registered the command '%s' isn't loaded anymore.\n", cmd.name)
+		return
+	}
+
+	state := stateOf(cmd.script)
+	luaParameters := state.NewTable()
+	for _, parameter := range parameters {
+		luaParameters.Append(gopherlua.LString(parameter))
+	}
+
+	output := state.SetFuncs(state.NewTable(), map[string]gopherlua.LGFunction{
+		"print": func(state *gopherlua.LState) int {
+			fmt.Fprintln(writer, joinArguments(state))
+			return 0
+		},
+		"write": func(state *gopherlua.LState) int {
+			fmt.Fprint(writer, joinArguments(state))
+			return 0
+		},
+	})
+
+	callError := cmd.engine.execute(cmd.script, func() error {
+		return state.CallByParam(gopherlua.P{
+			Fn:      cmd.execute,
+			NRet:    0,
+			Protect: true,
+		}, luaParameters, output)
+	})
+	cmd.engine.RecordResult(cmd.script, callError)
+	if callError != nil {
+		fmt.Fprintf(writer, "[red]Error executing command '%s':\n\t[red]%s\n", cmd.name, callError)
+	}
+}
+
+// PrintHelp prints the help text defined by the script.
+func (cmd *luaCommand) PrintHelp(writer io.Writer) {
+	if cmd.help == "" {
+		fmt.Fprintf(writer, "The command '%s' has been registered by a script and has no help page.\n", cmd.name)
+	} else {
+		fmt.Fprintln(writer, cmd.help)
+	}
+}
+
+func (cmd *luaCommand) Name() string {
+	return cmd.name
+}
+
+func (cmd *luaCommand) Aliases() []string {
+	return cmd.aliases
+}
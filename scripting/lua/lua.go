@@ -0,0 +1,340 @@
+package lua
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/Bios-Marcel/cordless/scripting"
+	"github.com/pkg/errors"
+	gopherlua "github.com/yuin/gopher-lua"
+)
+
+var _ scripting.Engine = &LuaEngine{}
+
+// LuaEngine runs lua scripts. Each script runs in its own state, only
+// having access to the base, table, string and math libraries.
+type LuaEngine struct {
+	// ScriptSet takes care of loading the scripts and provides the lock
+	// that prevents concurrent access to the states, since gopher-lua
+	// isn't threadsafe and events are fired from multiple goroutines.
+	*scripting.ScriptSet
+}
+
+// unlimitedStackDepth is used as the call stack size if MaxStackDepth is
+// zero, since gopher-lua would otherwise fall back to its default of 256
+// calls. Both stacks grow on demand, so the memory is only used by scripts
+// that actually recurse that deep.
+const unlimitedStackDepth = 1 << 16
+
+// New instantiates a new lua scripting engine.
+func New() *LuaEngine {
+	engine := &LuaEngine{}
+	engine.ScriptSet = scripting.NewScriptSet(".lua", engine.startScript, engine.stopScript)
+	return engine
+}
+
+// startScript creates a new state for the given script and runs it.
+func (engine *LuaEngine) startScript(script *scripting.Script) error {
+	options := gopherlua.Options{
+		SkipOpenLibs:        true,
+		CallStackSize:       engine.Limits().MaxStackDepth,
+		MinimizeStackMemory: true,
+	}
+	if options.CallStackSize <= 0 {
+		options.CallStackSize = unlimitedStackDepth
+		//Every call needs a couple of registers, so the registry has to be
+		//able to grow along with the call stack.
+		options.RegistryMaxSize = unlimitedStackDepth * 16
+	}
+	state := gopherlua.NewState(options)
+	script.VM = state
+	openLibraries(state)
+	engine.injectHost(script)
+	engine.injectStorage(script)
+
+	runError := engine.execute(script, func() error {
+		return state.DoFile(script.Path)
+	})
+	if runError != nil {
+		return errors.Wrap(runError, "failed to run script")
+	}
+
+	return nil
+}
+
+// stopScript closes the scripts state.
+func (engine *LuaEngine) stopScript(script *scripting.Script) {
+	stateOf(script).Close()
+}
+
+// stateOf returns the state the given script is running in.
+func stateOf(script *scripting.Script) *gopherlua.LState {
+	return script.VM.(*gopherlua.LState)
+}
+
+// openLibraries opens all libraries that don't allow access to the system.
+func openLibraries(state *gopherlua.LState) {
+	libraries := []struct {
+		name     string
+		function gopherlua.LGFunction
+	}{
+		{gopherlua.BaseLibName, gopherlua.OpenBase},
+		{gopherlua.TabLibName, gopherlua.OpenTable},
+		{gopherlua.StringLibName, gopherlua.OpenString},
+		{gopherlua.MathLibName, gopherlua.OpenMath},
+	}
+	for _, library := range libraries {
+		state.Push(state.NewFunction(library.function))
+		state.Push(gopherlua.LString(library.name))
+		state.Call(1, 0)
+	}
+
+	//Loading arbitrary files would circumvent the sandbox.
+	state.SetGlobal("dofile", gopherlua.LNil)
+	state.SetGlobal("loadfile", gopherlua.LNil)
+}
+
+// execute runs the given function, which is expected to call into the
+// scripts state. If the execution exceeds the timeout, it is cancelled.
+func (engine *LuaEngine) execute(script *scripting.Script, function func() error) error {
+	timeout := engine.Limits().Timeout
+	if timeout <= 0 {
+		return function()
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	state := stateOf(script)
+	state.SetContext(ctx)
+	defer state.RemoveContext()
+
+	executionError := function()
+	if executionError != nil && ctx.Err() == context.DeadlineExceeded {
+		return errors.Errorf("script execution timed out: exceeded %s", timeout)
+	}
+
+	return executionError
+}
+
+// OnMessageSend implements Engine
+func (engine *LuaEngine) OnMessageSend(channel *scripting.Channel, text string) *scripting.SendResult {
+	engine.Lock()
+	defer engine.Unlock()
+
+	result := &scripting.SendResult{Text: text}
+	plainChannel, conversionError := toPlainObject(channel)
+	if conversionError != nil {
+		engine.printError("onMessageSend", conversionError)
+		return result
+	}
+
+	for _, script := range engine.ActiveScripts() {
+		state := stateOf(script)
+		hook, isFunction := state.GetGlobal("onMessageSend").(*gopherlua.LFunction)
+		if !isFunction {
+			continue
+		}
+
+		var returnValue gopherlua.LValue
+		callError := engine.execute(script, func() error {
+			callError := state.CallByParam(gopherlua.P{
+				Fn:      hook,
+				NRet:    1,
+				Protect: true,
+			}, gopherlua.LString(result.Text), toLuaValue(state, plainChannel))
+			if callError == nil {
+				returnValue = state.Get(-1)
+				state.Pop(1)
+			}
+			return callError
+		})
+		engine.RecordResult(script, callError)
+		if callError != nil {
+			engine.printError("onMessageSend", callError)
+			//This script failed, go to next one
+			continue
+		}
+
+		result.Apply(toSendResult(returnValue, result.Text))
+		if result.Cancel {
+			break
+		}
+
+		if target := result.TargetChannel(engine.Host(), channel); target != channel {
+			channel = target
+			plainChannel, conversionError = toPlainObject(channel)
+			if conversionError != nil {
+				engine.printError("onMessageSend", conversionError)
+				return result
+			}
+		}
+	}
+
+	return result
+}
+
+// toSendResult interprets the return value of onMessageSend. Scripts can
+// either return the new text or a table with the fields "text", "cancel",
+// "channel", "reason" and "confirm". Returning nothing leaves the text
+// unchanged.
+func toSendResult(value gopherlua.LValue, text string) *scripting.SendResult {
+	table, isTable := value.(*gopherlua.LTable)
+	if !isTable {
+		if value == gopherlua.LNil {
+			return &scripting.SendResult{Text: text}
+		}
+		return &scripting.SendResult{Text: value.String()}
+	}
+
+	decision := &scripting.SendResult{
+		Text:      text,
+		ChannelID: getStringField(table, "channel"),
+		Reason:    getStringField(table, "reason"),
+		Cancel:    gopherlua.LVAsBool(table.RawGetString("cancel")),
+		Confirm:   gopherlua.LVAsBool(table.RawGetString("confirm")),
+	}
+	if newText := table.RawGetString("text"); newText != gopherlua.LNil {
+		decision.Text = newText.String()
+	}
+
+	return decision
+}
+
+// OnStartup implements Engine
+func (engine *LuaEngine) OnStartup() {
+	engine.callHook("onStartup")
+}
+
+// OnMessageReceive implements Engine
+func (engine *LuaEngine) OnMessageReceive(message *scripting.Message) {
+	engine.callHook("onMessageReceive", message)
+}
+
+// OnMessageEdit implements Engine
+func (engine *LuaEngine) OnMessageEdit(message *scripting.Message) {
+	engine.callHook("onMessageEdit", message)
+}
+
+// OnMessageDelete implements Engine
+func (engine *LuaEngine) OnMessageDelete(message *scripting.Message) {
+	engine.callHook("onMessageDelete", message)
+}
+
+// OnMessageRender implements Engine
+func (engine *LuaEngine) OnMessageRender(message *scripting.Message, text string) string {
+	engine.Lock()
+	defer engine.Unlock()
+
+	plainMessage, conversionError := toPlainObject(message)
+	if conversionError != nil {
+		engine.printError("onMessageRender", conversionError)
+		return text
+	}
+
+	for _, script := range engine.ActiveScripts() {
+		state := stateOf(script)
+		hook, isFunction := state.GetGlobal("onMessageRender").(*gopherlua.LFunction)
+		if !isFunction {
+			continue
+		}
+
+		var returnValue gopherlua.LValue
+		callError := engine.execute(script, func() error {
+			callError := state.CallByParam(gopherlua.P{
+				Fn:      hook,
+				NRet:    1,
+				Protect: true,
+			}, toLuaValue(state, plainMessage), gopherlua.LString(text))
+			if callError == nil {
+				returnValue = state.Get(-1)
+				state.Pop(1)
+			}
+			return callError
+		})
+		engine.RecordResult(script, callError)
+		if callError != nil {
+			engine.printError("onMessageRender", callError)
+			//This script failed, go to next one
+			continue
+		}
+
+		if returnValue != gopherlua.LNil {
+			text = returnValue.String()
+		}
+	}
+
+	return text
+}
+
+// OnChannelSwitch implements Engine
+func (engine *LuaEngine) OnChannelSwitch(channel *scripting.Channel) {
+	engine.callHook("onChannelSwitch", channel)
+}
+
+// callHook calls the function with the given name in every state that
+// defines it. States that don't define the function are skipped. The
+// arguments are converted into lua tables beforehand.
+func (engine *LuaEngine) callHook(name string, arguments ...interface{}) {
+	engine.Lock()
+	defer engine.Unlock()
+
+	plainArguments := make([]interface{}, 0, len(arguments))
+	for _, argument := range arguments {
+		plainArgument, conversionError := toPlainObject(argument)
+		if conversionError != nil {
+			engine.printError(name, conversionError)
+			return
+		}
+		plainArguments = append(plainArguments, plainArgument)
+	}
+
+	for _, script := range engine.ActiveScripts() {
+		state := stateOf(script)
+		hook, isFunction := state.GetGlobal(name).(*gopherlua.LFunction)
+		if !isFunction {
+			continue
+		}
+
+		luaArguments := make([]gopherlua.LValue, 0, len(plainArguments))
+		for _, argument := range plainArguments {
+			luaArguments = append(luaArguments, toLuaValue(state, argument))
+		}
+
+		callError := engine.execute(script, func() error {
+			return state.CallByParam(gopherlua.P{
+				Fn:      hook,
+				NRet:    0,
+				Protect: true,
+			}, luaArguments...)
+		})
+		engine.RecordResult(script, callError)
+		if callError != nil {
+			//This script failed, go to next one
+			engine.printError(name, callError)
+		}
+	}
+}
+
+func (engine *LuaEngine) printError(hook string, err error) {
+	if errorOutput := engine.ErrorOutput(); errorOutput != nil {
+		fmt.Fprintf(errorOutput, "[red]Error occurred during execution of lua hook '%s': %s\n", hook, err.Error())
+	}
+}
+
+// toPlainObject turns the given value into maps and slices, so that the
+// scripts see the same names as the json representation has.
+func toPlainObject(value interface{}) (interface{}, error) {
+	asJSON, marshalError := json.Marshal(value)
+	if marshalError != nil {
+		return nil, marshalError
+	}
+
+	var object interface{}
+	unmarshalError := json.Unmarshal(asJSON, &object)
+	if unmarshalError != nil {
+		return nil, unmarshalError
+	}
+
+	return object, nil
+}
@@ -0,0 +1,262 @@
+package lua
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/quick"
+	"time"
+
+	"github.com/Bios-Marcel/cordless/commands"
+	"github.com/Bios-Marcel/cordless/scripting"
+)
+
+func TestLuaEngineOnMessageSend(t *testing.T) {
+	e := New()
+	if err := e.LoadScripts("test/simple"); err != nil {
+		t.Fatal("LoadScripts failed:", err)
+	}
+
+	if output := e.OnMessageSend(nil, "Replace me").Text; output != "Replace this" {
+		t.Errorf("OnMessageSend() = %q, want %q", output, "Replace this")
+	}
+
+	roundTrip := func(input string) bool {
+		return e.OnMessageSend(nil, input).Text == strings.Replace(input, "me", "this", -1)
+	}
+	if err := quick.Check(roundTrip, nil); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestLuaEngineEvents(t *testing.T) {
+	e := New()
+	if err := e.LoadScripts("test/events"); err != nil {
+		t.Fatal("LoadScripts failed:", err)
+	}
+
+	guild := &scripting.Guild{ID: "G1", Name: "Gophers"}
+	channel := &scripting.Channel{ID: "C1", Name: "general", Guild: guild}
+
+	e.OnStartup()
+	e.OnMessageReceive(&scripting.Message{
+		ID:      "M1",
+		Content: "Hello",
+		Author:  &scripting.User{ID: "U1", Username: "Marcel"},
+		Channel: channel,
+		Guild:   guild,
+	})
+	e.OnChannelSwitch(channel)
+	//Not defined by the script and therefore has to be skipped silently.
+	e.OnMessageDelete(&scripting.Message{ID: "M1", Channel: channel})
+
+	state := stateOf(e.ActiveScripts()[0])
+	if started := state.GetGlobal("started").String(); started != "true" {
+		t.Errorf("onStartup wasn't called, started = %v", started)
+	}
+
+	if err := state.DoString(`result = table.concat(received, "\n")`); err != nil {
+		t.Fatal(err)
+	}
+	if received := state.GetGlobal("result").String(); received != "Marcel@general: Hello" {
+		t.Errorf("onMessageReceive got %v, want %v", received, "Marcel@general: Hello")
+	}
+
+	if switchedTo := state.GetGlobal("switchedTo").String(); switchedTo != "Gophers/general" {
+		t.Errorf("onChannelSwitch got %v, want %v", switchedTo, "Gophers/general")
+	}
+}
+
+type testHost struct {
+	sent     []string
+	printed  []string
+	commands []commands.Command
+}
+
+func (host *testHost) SendMessage(channelID, text string) error {
+	host.sent = append(host.sent, channelID+":"+text)
+	return nil
+}
+
+func (host *testHost) GetSelectedChannel() *scripting.Channel {
+	return &scripting.Channel{ID: "C1", Name: "general"}
+}
+
+func (host *testHost) GetChannel(channelID string) *scripting.Channel {
+	return &scripting.Channel{ID: channelID}
+}
+
+func (host *testHost) ShowNotification(title, body string) error {
+	return nil
+}
+
+func (host *testHost) Print(text string) {
+	host.printed = append(host.printed, text)
+}
+
+func (host *testHost) RegisterCommand(command commands.Command) error {
+	host.commands = append(host.commands, command)
+	return nil
+}
+
+func (host *testHost) UnregisterCommand(command commands.Command) {
+	for index, registered := range host.commands {
+		if registered == command {
+			host.commands = append(host.commands[:index], host.commands[index+1:]...)
+			return
+		}
+	}
+}
+
+func TestLuaEngineHost(t *testing.T) {
+	host := &testHost{}
+	e := New()
+	e.SetHost(host)
+	if err := e.LoadScripts("test/host"); err != nil {
+		t.Fatal("LoadScripts failed:", err)
+	}
+
+	if len(host.commands) != 2 || host.commands[0].Name() != "greet" || host.commands[1].Name() != "shout" {
+		t.Fatalf("Expected commands 'greet' and 'shout' to be registered, got %v", host.commands)
+	}
+
+	output := &bytes.Buffer{}
+	host.commands[0].Execute(output, []string{"dear", "world"})
+	if output.String() != "Hello dear world\n" {
+		t.Errorf("Command output was %q, want %q", output.String(), "Hello dear world\n")
+	}
+
+	shout := host.commands[1]
+	if !reflect.DeepEqual(shout.Aliases(), []string{"yell"}) {
+		t.Errorf("Aliases were %v, want %v", shout.Aliases(), []string{"yell"})
+	}
+
+	output.Reset()
+	shout.Execute(output, []string{"quiet", "please"})
+	if output.String() != "QUIET PLEASE" {
+		t.Errorf("Command output was %q, want %q", output.String(), "QUIET PLEASE")
+	}
+
+	e.OnMessageReceive(&scripting.Message{Content: "ping", Channel: &scripting.Channel{ID: "C1"}})
+	if !reflect.DeepEqual(host.sent, []string{"C1:pong"}) {
+		t.Errorf("Sent messages were %v, want %v", host.sent, []string{"C1:pong"})
+	}
+	if !reflect.DeepEqual(host.printed, []string{"received ping"}) {
+		t.Errorf("Printed text was %v, want %v", host.printed, []string{"received ping"})
+	}
+
+	e.SetScriptEnabled("host.lua", false)
+	if len(host.commands) != 0 {
+		t.Errorf("Expected commands to be removed after disabling, got %v", host.commands)
+	}
+}
+
+func TestLuaEngineLimits(t *testing.T) {
+	errorOutput := &bytes.Buffer{}
+	e := New()
+	e.SetErrorOutput(errorOutput)
+	e.SetLimits(scripting.Limits{
+		Timeout:       50 * time.Millisecond,
+		MaxStackDepth: 100,
+		MaxFailures:   2,
+	})
+	if err := e.LoadScripts("test/limits"); err != nil {
+		t.Fatal("LoadScripts failed:", err)
+	}
+
+	message := &scripting.Message{Content: "Hello", Channel: &scripting.Channel{ID: "C1"}}
+	e.OnMessageReceive(message)
+	if !strings.Contains(errorOutput.String(), "timed out") {
+		t.Errorf("Expected the endless loop to time out, got %q", errorOutput.String())
+	}
+
+	e.OnChannelSwitch(message.Channel)
+	if !strings.Contains(errorOutput.String(), "stack overflow") {
+		t.Errorf("Expected the recursion to be stopped, got %q", errorOutput.String())
+	}
+
+	e.OnMessageReceive(message)
+	scripts := e.GetScripts()
+	if scripts[0].Name != "loop.lua" || scripts[0].Error == nil {
+		t.Errorf("Expected loop.lua to be disabled after two failures, got %v", scripts[0])
+	}
+	if scripts[1].Name != "recursion.lua" || scripts[1].Error != nil {
+		t.Errorf("Expected recursion.lua to still be active after one failure, got %v", scripts[1])
+	}
+}
+
+func TestLuaEngineUnlimitedStackDepth(t *testing.T) {
+	errorOutput := &bytes.Buffer{}
+	e := New()
+	e.SetErrorOutput(errorOutput)
+	if err := e.LoadScripts("test/depth"); err != nil {
+		t.Fatal("LoadScripts failed:", err)
+	}
+
+	e.OnChannelSwitch(&scripting.Channel{ID: "C1"})
+	if errorOutput.Len() != 0 {
+		t.Fatalf("Expected no errors without a stack depth limit, got %q", errorOutput.String())
+	}
+	if depth := stateOf(e.ActiveScripts()[0]).GetGlobal("depth").String(); depth != "5000" {
+		t.Errorf("Expected a recursion depth of 5000, got %s", depth)
+	}
+}
+
+func TestLuaEngineStorage(t *testing.T) {
+	directory, tempError := ioutil.TempDir("", "cordless-storage")
+	if tempError != nil {
+		t.Fatal(tempError)
+	}
+	defer os.RemoveAll(directory)
+
+	load := func() *testHost {
+		host := &testHost{}
+		e := New()
+		e.SetHost(host)
+		e.SetStorageDirectory(directory)
+		if err := e.LoadScripts("test/storage"); err != nil {
+			t.Fatal("LoadScripts failed:", err)
+		}
+		return host
+	}
+
+	output := &bytes.Buffer{}
+	load().commands[0].Execute(output, []string{"a"})
+	//A new engine has to see the data persisted by the previous one.
+	host := load()
+	host.commands[0].Execute(output, []string{"b"})
+	host.commands[1].Execute(output, nil)
+
+	want := "1 count,last\n2 count,last\ntrue\n"
+	if output.String() != want {
+		t.Errorf("Command output was %q, want %q", output.String(), want)
+	}
+}
+
+func TestLuaEngineSendResult(t *testing.T) {
+	e := New()
+	if err := e.LoadScripts("test/decisions"); err != nil {
+		t.Fatal("LoadScripts failed:", err)
+	}
+
+	general := &scripting.Channel{ID: "C1", Name: "general"}
+	announcements := &scripting.Channel{ID: "C3", Name: "announcements"}
+	tests := []struct {
+		channel *scripting.Channel
+		input   string
+		want    scripting.SendResult
+	}{
+		{general, "hello", scripting.SendResult{Text: "hello"}},
+		{general, "my password is 1234", scripting.SendResult{Text: "my password is 1234", Cancel: true, Reason: "Looks like a secret"}},
+		{announcements, "hello", scripting.SendResult{Text: "hello", Confirm: true, Reason: "This is an announcement channel"}},
+		{general, "!log hello", scripting.SendResult{Text: "hello", ChannelID: "C2"}},
+	}
+	for _, tt := range tests {
+		if result := e.OnMessageSend(tt.channel, tt.input); !reflect.DeepEqual(*result, tt.want) {
+			t.Errorf("OnMessageSend(%s, %q) = %+v, want %+v", tt.channel.Name, tt.input, *result, tt.want)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package lua
+
+import (
+	"github.com/Bios-Marcel/cordless/scripting"
+	gopherlua "github.com/yuin/gopher-lua"
+)
+
+// injectStorage adds the global "storage" table to the scripts state. The
+// table gives the script access to its persistent key/value store.
+func (engine *LuaEngine) injectStorage(script *scripting.Script) {
+	state := stateOf(script)
+	storageTable := state.SetFuncs(state.NewTable(), map[string]gopherlua.LGFunction{
+		"get": func(state *gopherlua.LState) int {
+			var value interface{}
+			found, getError := script.Storage.Get(state.CheckString(1), &value)
+			if getError != nil {
+				state.RaiseError("%s", getError.Error())
+			}
+			if !found {
+				state.Push(gopherlua.LNil)
+			} else {
+				state.Push(toLuaValue(state, value))
+			}
+
+			return 1
+		},
+		"set": func(state *gopherlua.LState) int {
+			value, conversionError := fromLuaValue(state.CheckAny(2))
+			if conversionError != nil {
+				state.RaiseError("%s", conversionError.Error())
+			}
+
+			setError := script.Storage.Set(state.CheckString(1), value)
+			if setError != nil {
+				state.RaiseError("%s", setError.Error())
+			}
+
+			return 0
+		},
+		"delete": func(state *gopherlua.LState) int {
+			deleteError := script.Storage.Delete(state.CheckString(1))
+			if deleteError != nil {
+				state.RaiseError("%s", deleteError.Error())
+			}
+
+			return 0
+		},
+		"keys": func(state *gopherlua.LState) int {
+			keys := state.NewTable()
+			for _, key := range script.Storage.Keys() {
+				keys.Append(gopherlua.LString(key))
+			}
+			state.Push(keys)
+
+			return 1
+		},
+	})
+	state.SetGlobal("storage", storageTable)
+}
@@ -0,0 +1,13 @@
+function onMessageSend(text, channel)
+  if string.find(text, "password", 1, true) then
+    return { cancel = true, reason = "Looks like a secret" }
+  end
+
+  if channel and channel.name == "announcements" then
+    return { confirm = true, reason = "This is an announcement channel" }
+  end
+
+  if string.sub(text, 1, 5) == "!log " then
+    return { text = string.sub(text, 6), channel = "C2" }
+  end
+end
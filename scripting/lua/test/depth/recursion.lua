@@ -0,0 +1,10 @@
+local function recurse(remaining)
+  if remaining == 0 then
+    return 0
+  end
+  return 1 + recurse(remaining - 1)
+end
+
+function onChannelSwitch(channel)
+  depth = recurse(5000)
+end
@@ -0,0 +1,15 @@
+started = false
+received = {}
+switchedTo = nil
+
+function onStartup()
+  started = true
+end
+
+function onMessageReceive(message)
+  table.insert(received, message.author.username .. "@" .. message.channel.name .. ": " .. message.content)
+end
+
+function onChannelSwitch(channel)
+  switchedTo = channel.guild.name .. "/" .. channel.name
+end
@@ -0,0 +1,19 @@
+cordless.registerCommand("greet", function(args, output)
+  output.print("Hello", table.concat(args, " "))
+end)
+
+cordless.registerCommand({
+  name = "shout",
+  aliases = { "yell" },
+  help = "shout - prints the parameters in uppercase",
+  execute = function(args, output)
+    output.write(string.upper(table.concat(args, " ")))
+  end
+})
+
+function onMessageReceive(message)
+  print("received", message.content)
+  if message.content == "ping" then
+    cordless.sendMessage(message.channel.id, "pong")
+  end
+end
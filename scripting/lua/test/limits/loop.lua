@@ -0,0 +1,4 @@
+function onMessageReceive(message)
+  while true do
+  end
+end
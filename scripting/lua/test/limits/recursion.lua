@@ -0,0 +1,7 @@
+local function recurse(depth)
+  return 1 + recurse(depth + 1)
+end
+
+function onChannelSwitch(channel)
+  recurse(0)
+end
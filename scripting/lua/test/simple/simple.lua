@@ -0,0 +1,3 @@
+function onMessageSend(input)
+  return (string.gsub(input, "me", "this"))
+end
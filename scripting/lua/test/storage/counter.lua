@@ -0,0 +1,11 @@
+cordless.registerCommand("count", function(args, output)
+  local count = (storage.get("count") or 0) + 1
+  storage.set("count", count)
+  storage.set("last", { args = args })
+  output.print(count, table.concat(storage.keys(), ","))
+end)
+
+cordless.registerCommand("forget", function(args, output)
+  storage.delete("count")
+  output.print(storage.get("count") == nil)
+end)
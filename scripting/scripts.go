@@ -0,0 +1,137 @@
+package scripting
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// ScriptStatus describes the state of a single script known to an Engine.
+type ScriptStatus struct {
+	// Name is the path of the script relative to the script directory.
+	Name string
+	// Enabled is false if the user has disabled the script.
+	Enabled bool
+	// Error is the last error that occurred while loading the script. If
+	// this is set, the script isn't active.
+	Error error
+}
+
+// FindScripts returns the paths of all files in the given directory and its
+// subdirectories that end with the given extension. Dotfolders are skipped.
+// A non-existent directory is treated as an empty one.
+func FindScripts(dirname, extension string) ([]string, error) {
+	_, statError := os.Stat(dirname)
+	if os.IsNotExist(statError) {
+		return nil, nil
+	} else if statError != nil {
+		return nil, statError
+	}
+
+	var scripts []string
+	files, readError := ioutil.ReadDir(dirname)
+	if readError != nil {
+		return nil, readError
+	}
+
+	for _, file := range files {
+		path := filepath.Join(dirname, file.Name())
+
+		//Skip dotfolders and read non-dotfolders.
+		if file.IsDir() {
+			if !strings.HasPrefix(file.Name(), ".") {
+				subScripts, subReadError := FindScripts(path, extension)
+				if subReadError != nil {
+					return nil, subReadError
+				}
+				scripts = append(scripts, subScripts...)
+			}
+
+			continue
+		}
+
+		if strings.HasSuffix(file.Name(), extension) {
+			scripts = append(scripts, path)
+		}
+	}
+
+	return scripts, nil
+}
+
+// ScriptName returns the name of a script, which is its path relative to
+// the script directory, using forward slashes on every platform.
+func ScriptName(dirname, path string) string {
+	relativePath, relError := filepath.Rel(dirname, path)
+	if relError != nil {
+		return filepath.ToSlash(path)
+	}
+
+	return filepath.ToSlash(relativePath)
+}
+
+// WatchDirectory polls the given directory in the given interval and calls
+// onChange whenever a file has been added, removed or modified. Calling the
+// returned function stops the watcher.
+func WatchDirectory(dirname string, interval time.Duration, onChange func()) (stop func()) {
+	stopChannel := make(chan struct{})
+	go func() {
+		lastSnapshot := snapshotDirectory(dirname)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-stopChannel:
+				return
+			case <-ticker.C:
+				newSnapshot := snapshotDirectory(dirname)
+				if !snapshotsEqual(lastSnapshot, newSnapshot) {
+					lastSnapshot = newSnapshot
+					onChange()
+				}
+			}
+		}
+	}()
+
+	return func() {
+		close(stopChannel)
+	}
+}
+
+func snapshotDirectory(dirname string) map[string]time.Time {
+	snapshot := make(map[string]time.Time)
+	filepath.Walk(dirname, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return nil
+		}
+
+		if info.IsDir() {
+			if path != dirname && strings.HasPrefix(info.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		snapshot[path] = info.ModTime()
+		return nil
+	})
+
+	return snapshot
+}
+
+func snapshotsEqual(a, b map[string]time.Time) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for path, modTime := range a {
+		otherModTime, contains := b[path]
+		if !contains || !otherModTime.Equal(modTime) {
+			return false
+		}
+	}
+
+	return true
+}
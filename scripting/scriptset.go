@@ -0,0 +1,288 @@
+package scripting
+
+import (
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/Bios-Marcel/cordless/commands"
+	"github.com/pkg/errors"
+)
+
+// Script is a single script file known to a ScriptSet.
+type Script struct {
+	// Name is the path of the script relative to the script directory.
+	Name string
+	// Path is the full path of the script file.
+	Path string
+	// VM is the engine specific virtual machine the script runs in. It is
+	// nil as long as the script isn't loaded.
+	VM interface{}
+	// Storage is the scripts persistent key/value store.
+	Storage *Storage
+	// Commands are all commands that have been registered by this script.
+	Commands []commands.Command
+
+	// err is the error that occurred during loading. Scripts that failed
+	// loading won't receive any events.
+	err error
+	// failures is the amount of consecutive failed hook or command calls.
+	failures int
+}
+
+// ScriptSet implements the script management shared by all engines, such
+// as loading, enabling and disabling scripts and keeping track of their
+// failures. Engines embed it and only have to deal with their VMs.
+type ScriptSet struct {
+	extension   string
+	start       func(script *Script) error
+	stop        func(script *Script)
+	scripts     []*Script
+	dirname     string
+	storageDir  string
+	disabled    map[string]bool
+	errorOutput io.Writer
+	host        Host
+	limits      Limits
+
+	// mutex prevents concurrent access to the VMs, since they aren't
+	// threadsafe and events are fired from multiple goroutines.
+	mutex *sync.Mutex
+}
+
+// NewScriptSet creates an empty ScriptSet for scripts with the given file
+// extension. start has to create the VM for a script, set Script.VM and
+// run the script. The storage has already been opened at that point. stop
+// is optional and releases the resources of a scripts VM. Script.VM is
+// reset afterwards.
+func NewScriptSet(extension string, start func(script *Script) error, stop func(script *Script)) *ScriptSet {
+	return &ScriptSet{
+		extension: extension,
+		start:     start,
+		stop:      stop,
+		scripts:   make([]*Script, 0),
+		disabled:  make(map[string]bool),
+		mutex:     &sync.Mutex{},
+	}
+}
+
+// Lock has to be held while calling into any of the VMs.
+func (set *ScriptSet) Lock() {
+	set.mutex.Lock()
+}
+
+// Unlock releases the lock acquired via Lock.
+func (set *ScriptSet) Unlock() {
+	set.mutex.Unlock()
+}
+
+// LoadScripts implements Engine
+func (set *ScriptSet) LoadScripts(dirname string) error {
+	set.mutex.Lock()
+	defer set.mutex.Unlock()
+
+	set.dirname = dirname
+	return set.loadScripts()
+}
+
+// Reload implements Engine
+func (set *ScriptSet) Reload() error {
+	set.mutex.Lock()
+	defer set.mutex.Unlock()
+
+	for _, script := range set.scripts {
+		set.unloadScript(script)
+	}
+	set.scripts = make([]*Script, 0)
+
+	return set.loadScripts()
+}
+
+func (set *ScriptSet) loadScripts() error {
+	paths, findError := FindScripts(set.dirname, set.extension)
+	if findError != nil {
+		return errors.Wrap(findError, "Error loading scripts")
+	}
+
+	for _, path := range paths {
+		newScript := &Script{
+			Name: ScriptName(set.dirname, path),
+			Path: path,
+		}
+		set.scripts = append(set.scripts, newScript)
+
+		if set.disabled[newScript.Name] {
+			continue
+		}
+
+		newScript.err = set.loadScript(newScript)
+		if newScript.err != nil && set.errorOutput != nil {
+			fmt.Fprintf(set.errorOutput, "[red]Error loading script '%s':\n\t[red]%s\n", newScript.Name, newScript.err)
+		}
+	}
+
+	return nil
+}
+
+// loadScript opens the scripts storage and starts the script. In case of
+// an error, all commands registered so far are removed again.
+func (set *ScriptSet) loadScript(script *Script) error {
+	storagePath := ""
+	if set.storageDir != "" {
+		storagePath = StoragePath(set.storageDir, script.Name)
+	}
+	storage, storageError := OpenStorage(storagePath, set.limits.MaxStorageSize)
+	if storageError != nil {
+		return errors.Wrap(storageError, "failed to open storage")
+	}
+	script.Storage = storage
+	script.failures = 0
+
+	startError := set.start(script)
+	if startError != nil {
+		set.unloadScript(script)
+		return startError
+	}
+
+	return nil
+}
+
+// unloadScript stops the scripts VM and removes all its commands.
+func (set *ScriptSet) unloadScript(script *Script) {
+	if set.host != nil {
+		for _, command := range script.Commands {
+			set.host.UnregisterCommand(command)
+		}
+	}
+	script.Commands = nil
+	script.Storage = nil
+	if script.VM != nil && set.stop != nil {
+		set.stop(script)
+	}
+	script.VM = nil
+}
+
+// GetScripts implements Engine
+func (set *ScriptSet) GetScripts() []*ScriptStatus {
+	set.mutex.Lock()
+	defer set.mutex.Unlock()
+
+	statuses := make([]*ScriptStatus, 0, len(set.scripts))
+	for _, script := range set.scripts {
+		statuses = append(statuses, &ScriptStatus{
+			Name:    script.Name,
+			Enabled: !set.disabled[script.Name],
+			Error:   script.err,
+		})
+	}
+
+	return statuses
+}
+
+// SetScriptEnabled implements Engine
+func (set *ScriptSet) SetScriptEnabled(name string, enabled bool) {
+	set.mutex.Lock()
+	defer set.mutex.Unlock()
+
+	if enabled {
+		delete(set.disabled, name)
+	} else {
+		set.disabled[name] = true
+	}
+
+	for _, script := range set.scripts {
+		if script.Name != name {
+			continue
+		}
+
+		if enabled && script.VM == nil {
+			script.err = set.loadScript(script)
+		} else if !enabled {
+			set.unloadScript(script)
+			script.err = nil
+		}
+		break
+	}
+}
+
+// ActiveScripts returns all scripts that are loaded and enabled. The lock
+// has to be held by the caller.
+func (set *ScriptSet) ActiveScripts() []*Script {
+	scripts := make([]*Script, 0, len(set.scripts))
+	for _, script := range set.scripts {
+		if script.VM != nil {
+			scripts = append(scripts, script)
+		}
+	}
+
+	return scripts
+}
+
+// FindScript returns the script that runs in the given VM. The lock has to
+// be held by the caller.
+func (set *ScriptSet) FindScript(vm interface{}) *Script {
+	for _, script := range set.scripts {
+		if script.VM == vm {
+			return script
+		}
+	}
+
+	return nil
+}
+
+// RecordResult keeps track of consecutive failures of a script. Once the
+// limit has been reached, the script is disabled until it gets reloaded.
+// The lock has to be held by the caller.
+func (set *ScriptSet) RecordResult(script *Script, err error) {
+	if err == nil {
+		script.failures = 0
+		return
+	}
+
+	script.failures++
+	if set.limits.MaxFailures <= 0 || script.failures < set.limits.MaxFailures || script.VM == nil {
+		return
+	}
+
+	set.unloadScript(script)
+	script.err = errors.Wrapf(err, "disabled after %d consecutive failures", script.failures)
+	if set.errorOutput != nil {
+		fmt.Fprintf(set.errorOutput, "[red]The script '%s' has been disabled after %d consecutive failures. Use 'scripts enable %s' to load it again.\n",
+			script.Name, script.failures, script.Name)
+	}
+}
+
+// ErrorOutput returns the writer set via SetErrorOutput, which may be nil.
+func (set *ScriptSet) ErrorOutput() io.Writer {
+	return set.errorOutput
+}
+
+// Host returns the Host set via SetHost, which may be nil.
+func (set *ScriptSet) Host() Host {
+	return set.host
+}
+
+// Limits returns the limits set via SetLimits.
+func (set *ScriptSet) Limits() Limits {
+	return set.limits
+}
+
+// SetErrorOutput implements Engine
+func (set *ScriptSet) SetErrorOutput(errorOutput io.Writer) {
+	set.errorOutput = errorOutput
+}
+
+// SetHost implements Engine
+func (set *ScriptSet) SetHost(host Host) {
+	set.host = host
+}
+
+// SetStorageDirectory implements Engine
+func (set *ScriptSet) SetStorageDirectory(dirname string) {
+	set.storageDir = dirname
+}
+
+// SetLimits implements Engine
+func (set *ScriptSet) SetLimits(limits Limits) {
+	set.limits = limits
+}
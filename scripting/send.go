@@ -0,0 +1,53 @@
+package scripting
+
+// SendResult is the decision of the scripts about an outgoing message.
+type SendResult struct {
+	// Text is the text that will be sent.
+	Text string
+	// Cancel prevents the message from being sent at all.
+	Cancel bool
+	// ChannelID redirects the message into a different channel, if set.
+	ChannelID string
+	// Reason explains to the user why a message has been cancelled or why
+	// confirmation is required.
+	Reason string
+	// Confirm requires the user to confirm sending the message.
+	Confirm bool
+}
+
+// TargetChannel returns the channel that the message will be sent to. If a
+// script redirected the message, the new channel is retrieved via the host,
+// so that the following scripts are called with it.
+func (result *SendResult) TargetChannel(host Host, channel *Channel) *Channel {
+	if result.ChannelID == "" || (channel != nil && channel.ID == result.ChannelID) {
+		return channel
+	}
+
+	if host != nil {
+		if target := host.GetChannel(result.ChannelID); target != nil {
+			return target
+		}
+	}
+
+	return &Channel{ID: result.ChannelID}
+}
+
+// Apply merges the decision of a single script into the result. The text
+// and channel of later scripts win, while cancellation, confirmation and
+// reasons accumulate.
+func (result *SendResult) Apply(decision *SendResult) {
+	result.Text = decision.Text
+	result.Cancel = result.Cancel || decision.Cancel
+	result.Confirm = result.Confirm || decision.Confirm
+	if decision.ChannelID != "" {
+		result.ChannelID = decision.ChannelID
+	}
+
+	if decision.Reason != "" {
+		if result.Reason == "" {
+			result.Reason = decision.Reason
+		} else {
+			result.Reason = result.Reason + "\n" + decision.Reason
+		}
+	}
+}
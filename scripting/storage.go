@@ -0,0 +1,169 @@
+package scripting
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// Storage is a persistent key/value store that belongs to a single script.
+// Values can be anything that can be represented as JSON. Every change is
+// written to disk immediately.
+type Storage struct {
+	path    string
+	maxSize int
+	values  map[string]json.RawMessage
+}
+
+// StoragePath returns the path of the file that stores the data of the
+// script with the given name.
+func StoragePath(dirname, scriptName string) string {
+	return filepath.Join(dirname, filepath.FromSlash(scriptName)+".json")
+}
+
+// OpenStorage loads the storage from the given file. A non-existent file is
+// treated as an empty storage. If path is empty, the storage only lives in
+// memory. A maxSize of 0 means that the size isn't limited.
+func OpenStorage(path string, maxSize int) (*Storage, error) {
+	storage := &Storage{
+		path:    path,
+		maxSize: maxSize,
+		values:  make(map[string]json.RawMessage),
+	}
+
+	if path == "" {
+		return storage, nil
+	}
+
+	data, readError := ioutil.ReadFile(path)
+	if os.IsNotExist(readError) {
+		return storage, nil
+	} else if readError != nil {
+		return nil, readError
+	}
+
+	if len(data) == 0 {
+		return storage, nil
+	}
+
+	unmarshalError := json.Unmarshal(data, &storage.values)
+	if unmarshalError != nil {
+		return nil, fmt.Errorf("storage file '%s' is corrupt: %s", path, unmarshalError)
+	}
+
+	return storage, nil
+}
+
+// Get unmarshals the value stored under the given key into target. False
+// is returned if the key doesn't exist.
+func (storage *Storage) Get(key string, target interface{}) (bool, error) {
+	value, contains := storage.values[key]
+	if !contains {
+		return false, nil
+	}
+
+	return true, json.Unmarshal(value, target)
+}
+
+// Set stores the given value under the given key. If the storage would
+// exceed its size limit, nothing is changed and an error is returned.
+func (storage *Storage) Set(key string, value interface{}) error {
+	valueAsJSON, marshalError := json.Marshal(value)
+	if marshalError != nil {
+		return marshalError
+	}
+
+	oldValue, contained := storage.values[key]
+	storage.values[key] = valueAsJSON
+
+	persistError := storage.persist()
+	if persistError != nil {
+		if contained {
+			storage.values[key] = oldValue
+		} else {
+			delete(storage.values, key)
+		}
+	}
+
+	return persistError
+}
+
+// Delete removes the given key. Deleting a non-existent key is a no-op.
+func (storage *Storage) Delete(key string) error {
+	oldValue, contained := storage.values[key]
+	if !contained {
+		return nil
+	}
+
+	delete(storage.values, key)
+	persistError := storage.persist()
+	if persistError != nil {
+		storage.values[key] = oldValue
+	}
+
+	return persistError
+}
+
+// Keys returns all keys in alphabetical order.
+func (storage *Storage) Keys() []string {
+	keys := make([]string, 0, len(storage.values))
+	for key := range storage.values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+// persist writes the storage to a temporary file first and then replaces
+// the actual file, so that a crash can't leave a half-written file behind.
+func (storage *Storage) persist() error {
+	data, marshalError := json.Marshal(storage.values)
+	if marshalError != nil {
+		return marshalError
+	}
+
+	if storage.maxSize > 0 && len(data) > storage.maxSize {
+		return fmt.Errorf("storage would exceed its limit of %d bytes", storage.maxSize)
+	}
+
+	if storage.path == "" {
+		return nil
+	}
+
+	//Folders have to be executable for some reason, therefore 766 instead of 666.
+	directory := filepath.Dir(storage.path)
+	createDirsError := os.MkdirAll(directory, 0766)
+	if createDirsError != nil {
+		return createDirsError
+	}
+
+	tempFile, createError := ioutil.TempFile(directory, filepath.Base(storage.path)+".tmp")
+	if createError != nil {
+		return createError
+	}
+
+	_, writeError := tempFile.Write(data)
+	if writeError == nil {
+		writeError = tempFile.Sync()
+	}
+	closeError := tempFile.Close()
+	if writeError == nil {
+		writeError = closeError
+	}
+	if writeError != nil {
+		os.Remove(tempFile.Name())
+		return writeError
+	}
+
+	renameError := os.Rename(tempFile.Name(), storage.path)
+	if renameError != nil {
+		os.Remove(tempFile.Name())
+		return renameError
+	}
+
+	return nil
+}
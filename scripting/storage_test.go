@@ -0,0 +1,59 @@
+package scripting
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestStorage(t *testing.T) {
+	directory, tempError := ioutil.TempDir("", "cordless-storage")
+	if tempError != nil {
+		t.Fatal(tempError)
+	}
+	defer os.RemoveAll(directory)
+
+	path := StoragePath(directory, "sub/script.js")
+	if path != filepath.Join(directory, "sub", "script.js.json") {
+		t.Errorf("StoragePath() = %s", path)
+	}
+
+	storage, openError := OpenStorage(path, 64)
+	if openError != nil {
+		t.Fatal("OpenStorage failed:", openError)
+	}
+
+	if err := storage.Set("counter", 5); err != nil {
+		t.Fatal("Set failed:", err)
+	}
+	if err := storage.Set("name", "cordless"); err != nil {
+		t.Fatal("Set failed:", err)
+	}
+	if err := storage.Set("toolarge", string(make([]byte, 64))); err == nil {
+		t.Error("Expected size limit to be enforced")
+	}
+	if err := storage.Delete("name"); err != nil {
+		t.Fatal("Delete failed:", err)
+	}
+
+	reopened, openError := OpenStorage(path, 64)
+	if openError != nil {
+		t.Fatal("OpenStorage failed:", openError)
+	}
+
+	if keys := reopened.Keys(); !reflect.DeepEqual(keys, []string{"counter"}) {
+		t.Errorf("Keys() = %v, want %v", keys, []string{"counter"})
+	}
+
+	var counter int
+	if found, err := reopened.Get("counter", &counter); !found || err != nil || counter != 5 {
+		t.Errorf("Get() = %d, %v, %v", counter, found, err)
+	}
+
+	files, _ := ioutil.ReadDir(filepath.Dir(path))
+	if len(files) != 1 {
+		t.Errorf("Expected temporary files to be cleaned up, got %d files", len(files))
+	}
+}
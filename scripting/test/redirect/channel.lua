@@ -0,0 +1,3 @@
+function onMessageSend(input, channel)
+  return input .. " " .. channel.name
+end
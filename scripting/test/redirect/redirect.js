@@ -0,0 +1,3 @@
+function onMessageSend(input) {
+  return { text: input, channel: "C2" };
+}
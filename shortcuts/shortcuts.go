@@ -16,19 +16,24 @@ var (
 	globalScope        = addScope("global", "Application wide", nil)
 	multilineTextInput = addScope("multiline_text_input", "Multiline text input", globalScope)
 	chatview           = addScope("chatview", "Chatview", globalScope)
+	commandInput       = addScope("command_input", "Command input", multilineTextInput)
 
 	QuoteSelectedMessage = addShortcut("quote_selected_message", "Quote selected message",
 		chatview, tcell.NewEventKey(tcell.KeyRune, 'q', tcell.ModNone))
 	EditSelectedMessage = addShortcut("edit_selected_message", "Edit selected message",
 		chatview, tcell.NewEventKey(tcell.KeyRune, 'e', tcell.ModNone))
-	ReplySelectedMessage = addShortcut("reply_selected_message", "Reply to author selected message",
+	ReplySelectedMessage = addShortcut("reply_selected_message", "Reply to selected message",
 		chatview, tcell.NewEventKey(tcell.KeyRune, 'r', tcell.ModNone))
+	JumpToReferencedMessage = addShortcut("jump_to_referenced_message", "Jump to message replied to",
+		chatview, tcell.NewEventKey(tcell.KeyRune, 'o', tcell.ModNone))
 	CopySelectedMessageLink = addShortcut("copy_selected_message_link", "Copy link to selected message",
 		chatview, tcell.NewEventKey(tcell.KeyRune, 'l', tcell.ModNone))
 	CopySelectedMessage = addShortcut("copy_selected_message", "Copy content of selected message",
 		chatview, tcell.NewEventKey(tcell.KeyRune, 'c', tcell.ModNone))
 	ToggleSelectedMessageSpoilers = addShortcut("toggle_selected_message_spoilers", "Toggle spoilers in selected message",
 		chatview, tcell.NewEventKey(tcell.KeyRune, 's', tcell.ModNone))
+	ReactToSelectedMessage = addShortcut("react_to_selected_message", "React to selected message",
+		chatview, tcell.NewEventKey(tcell.KeyRune, 'a', tcell.ModNone))
 	DeleteSelectedMessage = addShortcut("toggle_selected_message_spoilers", "Toggle spoilers in selected message",
 		chatview, tcell.NewEventKey(tcell.KeyDelete, 0, tcell.ModNone))
 
@@ -71,6 +76,9 @@ var (
 	AddNewLineInCodeBlock = addShortcut("add_new_line_in_code_block", "Adds a new line inside a code block",
 		multilineTextInput, tcell.NewEventKey(tcell.KeyEnter, rune(tcell.KeyEnter), tcell.ModNone))
 
+	SearchCommandHistory = addShortcut("search_command_history", "Search command history",
+		commandInput, tcell.NewEventKey(tcell.KeyCtrlR, rune(tcell.KeyCtrlR), tcell.ModCtrl))
+
 	ExitApplication = addShortcut("exit_application", "Exit application",
 		globalScope, tcell.NewEventKey(tcell.KeyCtrlC, rune(tcell.KeyCtrlC), tcell.ModCtrl))
 
@@ -88,6 +96,12 @@ var (
 		globalScope, tcell.NewEventKey(tcell.KeyRune, 'm', tcell.ModAlt))
 	FocusMessageContainer = addShortcut("focus_message_container", "Focus message container",
 		globalScope, tcell.NewEventKey(tcell.KeyRune, 't', tcell.ModAlt))
+	JumpToFirstUnreadMessage = addShortcut("jump_to_first_unread_message", "Jump to first unread message",
+		globalScope, tcell.NewEventKey(tcell.KeyRune, 'n', tcell.ModAlt))
+	MarkGuildAsRead = addShortcut("mark_guild_as_read", "Mark selected guild as read",
+		globalScope, tcell.NewEventKey(tcell.KeyRune, 'r', tcell.ModAlt))
+	MarkEverythingAsRead = addShortcut("mark_everything_as_read", "Mark all guilds and private chats as read",
+		globalScope, tcell.NewEventKey(tcell.KeyRune, 'R', tcell.ModAlt))
 	FocusCommandInput = addShortcut("focus_command_input", "Focus command input",
 		globalScope, tcell.NewEventKey(tcell.KeyCtrlI, rune(tcell.KeyCtrlI), tcell.ModNone))
 	FocusCommandOutput = addShortcut("focus_command_output", "Focus command output",
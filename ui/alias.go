@@ -0,0 +1,71 @@
+package ui
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/Bios-Marcel/cordless/commands"
+	"github.com/Bios-Marcel/tview"
+)
+
+// aliasCommand runs the command lines of a user-defined alias.
+type aliasCommand struct {
+	window       *Window
+	name         string
+	commandLines []string
+}
+
+var _ commands.InputCommand = &aliasCommand{}
+var _ commands.SensitiveCommand = &aliasCommand{}
+
+// Execute runs all command lines of the alias after substituting the
+// parameters into them.
+func (cmd *aliasCommand) Execute(writer io.Writer, parameters []string) {
+	cmd.ExecuteWithInput(writer, nil, parameters)
+}
+
+// ExecuteWithInput works like Execute, but passes the input on to the first
+// command line of the alias.
+func (cmd *aliasCommand) ExecuteWithInput(writer io.Writer, input io.Reader, parameters []string) {
+	cmd.window.activeAliases[cmd.name] = true
+	defer delete(cmd.window.activeAliases, cmd.name)
+
+	for _, commandLine := range commands.ExpandAlias(cmd.commandLines, parameters) {
+		cmd.window.executeCommandLine(writer, input, commandLine)
+		input = nil
+	}
+}
+
+// IsSensitive checks whether any of the command lines the alias expands to
+// passes secrets to a sensitive command. Just like during execution, the
+// alias refers to the command it shadows instead of itself.
+func (cmd *aliasCommand) IsSensitive(parameters []string) bool {
+	cmd.window.activeAliases[cmd.name] = true
+	defer delete(cmd.window.activeAliases, cmd.name)
+
+	for _, commandLine := range commands.ExpandAlias(cmd.commandLines, parameters) {
+		if commands.IsSensitive(commandLine, cmd.window.FindCommand) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// PrintHelp prints the command lines the alias expands to.
+func (cmd *aliasCommand) PrintHelp(writer io.Writer) {
+	fmt.Fprintf(writer, "[::b]%s[::-] is an alias for:\n", cmd.name)
+	for _, commandLine := range cmd.commandLines {
+		fmt.Fprintf(writer, "\t%s\n", tview.Escape(commandLine))
+	}
+}
+
+// Name returns the name of the alias.
+func (cmd *aliasCommand) Name() string {
+	return cmd.name
+}
+
+// Aliases returns nil, since aliases can't have aliases themselves.
+func (cmd *aliasCommand) Aliases() []string {
+	return nil
+}
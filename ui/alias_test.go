@@ -0,0 +1,53 @@
+package ui
+
+import (
+	"io"
+	"testing"
+
+	"github.com/Bios-Marcel/cordless/commands"
+	"github.com/Bios-Marcel/cordless/config"
+)
+
+type tokenCommand struct{}
+
+func (cmd *tokenCommand) Execute(writer io.Writer, parameters []string) {}
+func (cmd *tokenCommand) PrintHelp(writer io.Writer)                    {}
+func (cmd *tokenCommand) Name() string                                  { return "token" }
+func (cmd *tokenCommand) Aliases() []string                             { return nil }
+func (cmd *tokenCommand) IsSensitive(parameters []string) bool {
+	return len(parameters) > 0
+}
+
+func TestAliasCommand_IsSensitive(t *testing.T) {
+	aliases := config.GetAliases()
+	aliases["login"] = []string{"token $1"}
+	aliases["relogin"] = []string{"login $@"}
+	aliases["token"] = []string{"token $@"}
+	defer func() {
+		delete(aliases, "login")
+		delete(aliases, "relogin")
+		delete(aliases, "token")
+	}()
+
+	window := &Window{
+		commands:      []commands.Command{&tokenCommand{}},
+		activeAliases: make(map[string]bool),
+	}
+	tests := []struct {
+		commandLine string
+		want        bool
+	}{
+		{"login secret", true},
+		{"relogin secret", true},
+		{"token secret", true},
+		{"login", false},
+	}
+	for _, tt := range tests {
+		if got := commands.IsSensitive(tt.commandLine, window.FindCommand); got != tt.want {
+			t.Errorf("IsSensitive(%q) = %v, want %v", tt.commandLine, got, tt.want)
+		}
+	}
+	if len(window.activeAliases) != 0 {
+		t.Errorf("No alias should be active after the check, but got %v", window.activeAliases)
+	}
+}
@@ -145,7 +145,7 @@ func createSecondLevelChannelNodes(channelTree *ChannelTree, channel *discordgo.
 }
 
 func createChannelNode(channel *discordgo.Channel) *tview.TreeNode {
-	channelNode := tview.NewTreeNode(discordutil.GetChannelNameForTree(channel))
+	channelNode := tview.NewTreeNode(discordutil.GetChannelNameForTree(channel) + channelUnreadBadge(channel))
 	channelNode.SetReference(channel.ID)
 	return channelNode
 }
@@ -166,7 +166,11 @@ func (channelTree *ChannelTree) AddOrUpdateChannel(channel *discordgo.Channel) {
 			}*/
 
 			updated = true
-			node.SetText(discordutil.GetChannelNameForTree(channel))
+			if channelTree.channelStates[node] == channelLoaded {
+				node.SetText(discordutil.GetChannelNameForTree(channel))
+			} else {
+				node.SetText(discordutil.GetChannelNameForTree(channel) + channelUnreadBadge(channel))
+			}
 
 			return false
 		}
@@ -235,12 +239,17 @@ func (channelTree *ChannelTree) removeNode(node, parent *tview.TreeNode, channel
 	}
 }
 
-// MarkChannelAsUnread marks a channel as unread.
+// MarkChannelAsUnread marks a channel as unread and updates its unread
+// badge.
 func (channelTree *ChannelTree) MarkChannelAsUnread(channelID string) {
 	channelTree.GetRoot().Walk(func(node, parent *tview.TreeNode) bool {
 		referenceChannelID, ok := node.GetReference().(string)
 		if ok && referenceChannelID == channelID {
 			channelTree.channelStates[node] = channelUnread
+			channel, stateError := channelTree.state.Channel(channelID)
+			if stateError == nil {
+				node.SetText(discordutil.GetChannelNameForTree(channel) + channelUnreadBadge(channel))
+			}
 			node.SetColor(tcell.ColorRed)
 
 			return false
@@ -257,7 +266,7 @@ func (channelTree *ChannelTree) MarkChannelAsRead(channelID string) {
 		if ok && referenceChannelID == channelID {
 			channel, stateError := channelTree.state.Channel(channelID)
 			if stateError == nil {
-				node.SetText(discordutil.GetChannelNameForTree(channel))
+				node.SetText(discordutil.GetChannelNameForTree(channel) + channelUnreadBadge(channel))
 			}
 
 			if channelTree.channelStates[node] != channelLoaded {
@@ -272,7 +281,8 @@ func (channelTree *ChannelTree) MarkChannelAsRead(channelID string) {
 	})
 }
 
-// MarkChannelAsMentioned marks a channel as mentioned.
+// MarkChannelAsMentioned marks a channel as mentioned and updates its unread
+// badge, which contains the number of mentions.
 func (channelTree *ChannelTree) MarkChannelAsMentioned(channelID string) {
 	channelTree.GetRoot().Walk(func(node, parent *tview.TreeNode) bool {
 		referenceChannelID, ok := node.GetReference().(string)
@@ -280,7 +290,7 @@ func (channelTree *ChannelTree) MarkChannelAsMentioned(channelID string) {
 			channelTree.channelStates[node] = channelMentioned
 			channel, stateError := channelTree.state.Channel(channelID)
 			if stateError == nil {
-				node.SetText("(@You) " + discordutil.GetChannelNameForTree(channel))
+				node.SetText(discordutil.GetChannelNameForTree(channel) + channelUnreadBadge(channel))
 			}
 			node.SetColor(tcell.ColorRed)
 
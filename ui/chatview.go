@@ -12,6 +12,7 @@ import (
 	linkshortener "github.com/Bios-Marcel/shortnotforlong"
 
 	"github.com/Bios-Marcel/cordless/discordutil"
+	"github.com/Bios-Marcel/cordless/maths"
 	"github.com/Bios-Marcel/cordless/times"
 	"github.com/gdamore/tcell"
 
@@ -28,15 +29,30 @@ import (
 )
 
 var (
-	linkColor           = "[#efec1c]"
-	codeBlockRegex      = regexp.MustCompile("(?sm)(^|.)?(\x60\x60\x60(.*?)?\n(.+?)\x60\x60\x60)($|.)")
-	colorRegex          = regexp.MustCompile("\\[#.{6}\\]")
-	channelMentionRegex = regexp.MustCompile(`<#\d*>`)
-	urlRegex            = regexp.MustCompile(`<?(https?://)(.+?)(/.+?)?($|\s|\||>)`)
-	spoilerRegex        = regexp.MustCompile(`(?s)\|\|(.+?)\|\|`)
-	roleMentionRegex    = regexp.MustCompile(`<@&\d*>`)
+	linkColor      = "[#efec1c]"
+	codeBlockRegex = regexp.MustCompile("(?sm)(^|.)?(\x60\x60\x60(.*?)?\n(.+?)\x60\x60\x60)($|.)")
+	colorRegex     = regexp.MustCompile("\\[#.{6}\\]")
+	urlRegex       = regexp.MustCompile(`<?(https?://)(.+?)(/.+?)?($|\s|\||>)`)
+	spoilerRegex   = regexp.MustCompile(`(?s)\|\|(.+?)\|\|`)
 )
 
+// messageTypeReply is the type of replies, it is missing in discordgo.
+const messageTypeReply discordgo.MessageType = 19
+
+// defaultBufferSize is the amount of messages the ChatView keeps, unless
+// older messages have been loaded explicitly.
+const defaultBufferSize = 100
+
+// beginningOfChannelText is shown above the first message of a channel.
+const beginningOfChannelText = "[gray]── This is the beginning of the channel ──"
+
+// unreadDividerText is shown in front of the first unread message.
+const unreadDividerText = "[red]──────── New messages ────────"
+
+// replyPreviewLength is the maximum amount of characters shown of a message
+// that is being replied to.
+const replyPreviewLength = 80
+
 // ChatView is using a tview.TextView in order to be able to display messages
 // in a simple way. It supports highlighting specific element types and it
 // also supports multiline.
@@ -50,6 +66,12 @@ type ChatView struct {
 	bufferSize int
 	ownUserID  string
 
+	beginningReached bool
+	endReached       bool
+
+	lastReadMessageID    uint64
+	firstUnreadMessageID string
+
 	shortenLinks bool
 
 	selection     int
@@ -59,6 +81,9 @@ type ChatView struct {
 	formattedMessages  map[string]string
 
 	onMessageAction func(message *discordgo.Message, event *tcell.EventKey) *tcell.EventKey
+	onMessageRender func(message *discordgo.Message, text string) string
+	onReachTop      func()
+	onReachBottom   func()
 
 	mutex *sync.Mutex
 }
@@ -70,7 +95,8 @@ func NewChatView(state *discordgo.State, ownUserID string) *ChatView {
 		state:              state,
 		ownUserID:          ownUserID,
 		selection:          -1,
-		bufferSize:         100,
+		bufferSize:         defaultBufferSize,
+		endReached:         true,
 		selectionMode:      false,
 		showSpoilerContent: make(map[string]bool),
 		shortenLinks:       config.GetConfig().ShortenLinks,
@@ -106,6 +132,7 @@ func NewChatView(state *discordgo.State, ownUserID string) *ChatView {
 				} else if chatView.selection >= 1 {
 					chatView.selection--
 				} else {
+					chatView.signalReachTop()
 					return nil
 				}
 
@@ -120,6 +147,7 @@ func NewChatView(state *discordgo.State, ownUserID string) *ChatView {
 				} else if chatView.selection <= len(chatView.data)-2 {
 					chatView.selection++
 				} else {
+					chatView.signalReachBottom()
 					return nil
 				}
 
@@ -191,6 +219,67 @@ func (chatView *ChatView) SetOnMessageAction(onMessageAction func(message *disco
 	chatView.onMessageAction = onMessageAction
 }
 
+// SetOnReachTop sets the handler that will get called if the user tries to
+// move past the oldest displayed message, either by scrolling or by moving
+// the selection. The handler is expected to load older messages.
+func (chatView *ChatView) SetOnReachTop(onReachTop func()) {
+	chatView.onReachTop = onReachTop
+}
+
+func (chatView *ChatView) signalReachTop() {
+	if !chatView.beginningReached && chatView.onReachTop != nil {
+		chatView.onReachTop()
+	}
+}
+
+// ScrollUp scrolls up by a single line. If the top has been reached, the
+// handler set via SetOnReachTop is called.
+func (chatView *ChatView) ScrollUp() {
+	chatView.internalTextView.ScrollUp()
+	if row, _ := chatView.internalTextView.GetScrollOffset(); row <= 0 {
+		chatView.signalReachTop()
+	}
+}
+
+// SetOnReachBottom sets the handler that will get called if the user tries
+// to move past the newest displayed message while the latest messages of the
+// channel aren't displayed. The handler is expected to load newer messages.
+func (chatView *ChatView) SetOnReachBottom(onReachBottom func()) {
+	chatView.onReachBottom = onReachBottom
+}
+
+func (chatView *ChatView) signalReachBottom() {
+	if !chatView.endReached && chatView.onReachBottom != nil {
+		chatView.onReachBottom()
+	}
+}
+
+// ScrollDown scrolls down by a single line. If the bottom has been reached,
+// the handler set via SetOnReachBottom is called.
+func (chatView *ChatView) ScrollDown() {
+	chatView.internalTextView.ScrollDown()
+	if chatView.internalTextView.IsScrolledToEnd() {
+		chatView.signalReachBottom()
+	}
+}
+
+// SetOnMessageRender sets the handler that is allowed to change the text
+// that will be displayed for a message. The handler receives the already
+// formatted text and its result is cached until the message changes.
+func (chatView *ChatView) SetOnMessageRender(onMessageRender func(message *discordgo.Message, text string) string) {
+	chatView.onMessageRender = onMessageRender
+}
+
+// ReformatMessages formats all currently displayed messages again, ignoring
+// the cache, and triggers a rerender. This is required if the result of the
+// message render handler might have changed.
+func (chatView *ChatView) ReformatMessages() {
+	for _, message := range chatView.data {
+		chatView.formattedMessages[message.ID] = chatView.formatMessageOrPlaceholder(message)
+	}
+	chatView.Rerender()
+}
+
 func intToString(value int) string {
 	return strconv.FormatInt(int64(value), 10)
 }
@@ -263,6 +352,11 @@ OUTER_LOOP:
 // all messages.
 func (chatView *ChatView) ClearViewAndCache() {
 	chatView.data = make([]*discordgo.Message, 0)
+	chatView.bufferSize = defaultBufferSize
+	chatView.beginningReached = false
+	chatView.endReached = true
+	chatView.lastReadMessageID = 0
+	chatView.firstUnreadMessageID = ""
 	chatView.showSpoilerContent = make(map[string]bool)
 	chatView.formattedMessages = make(map[string]string)
 	chatView.selection = -1
@@ -283,6 +377,7 @@ func (chatView *ChatView) addMessageInternal(message *discordgo.Message) {
 		delete(chatView.showSpoilerContent, idToDrop)
 		delete(chatView.formattedMessages, idToDrop)
 		chatView.data = append(chatView.data[1:], message)
+		chatView.beginningReached = false
 		rerender = true
 		if chatView.selection > -1 {
 			chatView.selection--
@@ -297,11 +392,7 @@ func (chatView *ChatView) addMessageInternal(message *discordgo.Message) {
 	if messageAlreadyFormatted {
 		newText = formattedMessage
 	} else {
-		if isBlocked {
-			newText = messagePartsToColouredString(message.Timestamp, "Blocked user", "Blocked message")
-		} else {
-			newText = chatView.formatMessage(message)
-		}
+		newText = chatView.formatMessageOrPlaceholder(message)
 		chatView.formattedMessages[message.ID] = newText
 	}
 
@@ -341,14 +432,157 @@ func (chatView *ChatView) AddMessages(messages []*discordgo.Message) {
 	}
 }
 
+// PrependMessages adds older messages in front of the displayed messages.
+// The messages have to be sorted from oldest to newest. The selection and
+// the scroll position are kept, so that the user doesn't lose track of what
+// they were looking at.
+func (chatView *ChatView) PrependMessages(messages []*discordgo.Message) {
+	_, _, width, _ := chatView.internalTextView.GetInnerRect()
+	prepended := make([]*discordgo.Message, 0, len(messages))
+	var addedLines int
+	for _, message := range messages {
+		if !config.GetConfig().ShowPlaceholderForBlockedMessages && discordutil.IsBlocked(chatView.state, message.Author) {
+			continue
+		}
+
+		formattedMessage, messageAlreadyFormatted := chatView.formattedMessages[message.ID]
+		if !messageAlreadyFormatted {
+			formattedMessage = chatView.formatMessageOrPlaceholder(message)
+			chatView.formattedMessages[message.ID] = formattedMessage
+		}
+		prepended = append(prepended, message)
+
+		//Approximation, since the regions are counted as text.
+		if width > 0 {
+			addedLines += len(tview.WordWrap(formattedMessage, width))
+		}
+	}
+
+	if len(prepended) == 0 {
+		return
+	}
+
+	chatView.data = append(prepended, chatView.data...)
+	if len(chatView.data) > chatView.bufferSize {
+		chatView.bufferSize = len(chatView.data)
+	}
+	chatView.updateFirstUnreadMessage()
+
+	row, column := chatView.internalTextView.GetScrollOffset()
+	chatView.Rerender()
+	if chatView.selection != -1 {
+		chatView.selection += len(prepended)
+		chatView.updateHighlights()
+	} else {
+		chatView.internalTextView.ScrollTo(maths.Max(row, 0)+addedLines, column)
+	}
+}
+
+// SetBeginningReached defines whether the oldest displayed message is the
+// first message of the channel. If so, a marker is shown above it and no
+// more older messages are requested.
+func (chatView *ChatView) SetBeginningReached(reached bool) {
+	if chatView.beginningReached == reached {
+		return
+	}
+
+	chatView.beginningReached = reached
+	row, column := chatView.internalTextView.GetScrollOffset()
+	chatView.Rerender()
+	if reached && row > 0 && chatView.selection == -1 {
+		chatView.internalTextView.ScrollTo(row+1, column)
+	}
+}
+
+// SetEndReached defines whether the newest displayed message is the latest
+// message of the channel. If not, newer messages are requested once the user
+// moves past the newest displayed message.
+func (chatView *ChatView) SetEndReached(reached bool) {
+	chatView.endReached = reached
+}
+
+// IsEndReached indicates whether the latest messages of the channel are
+// displayed. Incoming messages may only be added if that's the case.
+func (chatView *ChatView) IsEndReached() bool {
+	return chatView.endReached
+}
+
+// SetLastReadMessage shows a divider in front of the first message that is
+// newer than the given message, which is the last message the user has
+// read. An empty ID removes the divider.
+func (chatView *ChatView) SetLastReadMessage(lastReadMessageID string) {
+	if lastReadMessageID == "" {
+		chatView.lastReadMessageID = 0
+	} else {
+		parsed, parseError := strconv.ParseUint(lastReadMessageID, 10, 64)
+		if parseError != nil {
+			return
+		}
+		chatView.lastReadMessageID = parsed
+	}
+
+	oldFirstUnreadMessageID := chatView.firstUnreadMessageID
+	chatView.updateFirstUnreadMessage()
+	if oldFirstUnreadMessageID != chatView.firstUnreadMessageID {
+		chatView.Rerender()
+	}
+}
+
+// updateFirstUnreadMessage searches for the oldest displayed message that
+// is newer than the last read message.
+func (chatView *ChatView) updateFirstUnreadMessage() {
+	chatView.firstUnreadMessageID = ""
+	if chatView.lastReadMessageID == 0 {
+		return
+	}
+
+	for _, message := range chatView.data {
+		id, parseError := strconv.ParseUint(message.ID, 10, 64)
+		if parseError == nil && id > chatView.lastReadMessageID {
+			chatView.firstUnreadMessageID = message.ID
+			return
+		}
+	}
+}
+
+// GetUnreadCount returns the amount of displayed messages that are newer
+// than the last read message. If all displayed messages are unread and
+// there are older messages that haven't been loaded yet, the actual amount
+// is unknown, which is indicated by the second return value.
+func (chatView *ChatView) GetUnreadCount() (int, bool) {
+	for index, message := range chatView.data {
+		if message.ID == chatView.firstUnreadMessageID {
+			return len(chatView.data) - index, index == 0 && !chatView.beginningReached
+		}
+	}
+
+	return 0, false
+}
+
+// SelectFirstUnreadMessage selects the first unread message and scrolls to
+// it. If there are no unread messages, false is returned.
+func (chatView *ChatView) SelectFirstUnreadMessage() bool {
+	if chatView.firstUnreadMessageID == "" {
+		return false
+	}
+
+	return chatView.SelectMessage(chatView.firstUnreadMessageID)
+}
+
 // Rerender clears the text view and fills it again using the current cache.
 func (chatView *ChatView) Rerender() {
 	chatView.internalTextView.SetText("")
 	var newContent string
+	if chatView.beginningReached {
+		newContent = beginningOfChannelText
+	}
 	for index, message := range chatView.data {
 		formattedMessage, contains := chatView.formattedMessages[message.ID]
 		//Should always be true, otherwise we got ourselves a bug.
 		if contains {
+			if message.ID == chatView.firstUnreadMessageID {
+				newContent = newContent + "\n" + unreadDividerText
+			}
 			newContent = newContent + "\n[\"" + intToString(index) + "\"]" + formattedMessage
 		} else {
 			panic("Bug in chatview, a message could not be found.")
@@ -357,11 +591,91 @@ func (chatView *ChatView) Rerender() {
 	fmt.Fprint(chatView.internalTextView, newContent)
 }
 
+// formatMessageOrPlaceholder formats the message or returns a placeholder in
+// case the author is blocked.
+func (chatView *ChatView) formatMessageOrPlaceholder(message *discordgo.Message) string {
+	if discordutil.IsBlocked(chatView.state, message.Author) {
+		return messagePartsToColouredString(message.Timestamp, "Blocked user", "Blocked message")
+	}
+
+	return chatView.formatMessage(message)
+}
+
 func (chatView *ChatView) formatMessage(message *discordgo.Message) string {
-	return messagePartsToColouredString(
+	messageText := chatView.formatMessageText(message)
+	if chatView.onMessageRender != nil {
+		messageText = chatView.onMessageRender(message, messageText)
+	}
+	messageText += formatReactions(message)
+
+	return chatView.formatReplyPreview(message) + messagePartsToColouredString(
 		message.Timestamp,
 		chatView.formatMessageAuthor(message),
-		chatView.formatMessageText(message))
+		messageText)
+}
+
+// formatReplyPreview renders a single line showing the beginning of the
+// message that the given message replies to. If the message isn't a reply,
+// an empty string is returned.
+func (chatView *ChatView) formatReplyPreview(message *discordgo.Message) string {
+	reference := discordutil.GetMessageReference(message.ChannelID, message.ID)
+	if reference == nil {
+		return ""
+	}
+
+	original := discordutil.GetReferencedMessage(reference)
+	for _, loadedMessage := range chatView.data {
+		if loadedMessage.ID == reference.MessageID {
+			original = loadedMessage
+			break
+		}
+	}
+	if original == nil {
+		original, _ = chatView.state.Message(reference.ChannelID, reference.MessageID)
+	}
+	if original == nil || original.Author == nil {
+		return "[gray]╭─ Original message isn't loaded\n"
+	}
+
+	content := discordutil.ResolveMentions(chatView.state, original, original.Content, func(name string, _ bool) string {
+		return name
+	})
+	if newLine := strings.IndexRune(content, '\n'); newLine != -1 {
+		content = content[:newLine] + " …"
+	}
+	if runes := []rune(content); len(runes) > replyPreviewLength {
+		content = string(runes[:replyPreviewLength]) + "…"
+	}
+	if content == "" && len(original.Attachments) > 0 {
+		content = "(attachment)"
+	}
+
+	author := discordutil.GetDisplayName(chatView.state, reference.GuildID, original.Author)
+	return "[gray]╭─ " + tview.Escape(author) + ": " + tview.Escape(content) + "\n"
+}
+
+// formatReactions renders the reactions of a message into a separate line.
+// Reactions that contain our own reaction are highlighted.
+func formatReactions(message *discordgo.Message) string {
+	if len(message.Reactions) == 0 {
+		return ""
+	}
+
+	reactions := make([]string, 0, len(message.Reactions))
+	for _, reaction := range message.Reactions {
+		emoji := reaction.Emoji.Name
+		if reaction.Emoji.ID != "" {
+			emoji = ":" + emoji + ":"
+		}
+
+		color := "[gray]"
+		if reaction.Me {
+			color = "[#ef9826]"
+		}
+		reactions = append(reactions, color+tview.Escape(emoji)+" "+strconv.Itoa(reaction.Count))
+	}
+
+	return "\n" + strings.Join(reactions, "  ") + "[white]"
 }
 
 func (chatView *ChatView) formatMessageAuthor(message *discordgo.Message) string {
@@ -385,7 +699,7 @@ func (chatView *ChatView) formatMessageAuthor(message *discordgo.Message) string
 }
 
 func (chatView *ChatView) formatMessageText(message *discordgo.Message) string {
-	if message.Type == discordgo.MessageTypeDefault {
+	if message.Type == discordgo.MessageTypeDefault || message.Type == messageTypeReply {
 		return chatView.formatDefaultMessageText(message)
 	} else if message.Type == discordgo.MessageTypeGuildMemberJoin {
 		return "[gray]joined the server."
@@ -410,61 +724,13 @@ func (chatView *ChatView) formatMessageText(message *discordgo.Message) string {
 func (chatView *ChatView) formatDefaultMessageText(message *discordgo.Message) string {
 	messageText := tview.Escape(message.Content)
 
-	//Message.MentionRoles only contains the mentions for mentionable.
-	//Therefore we do it like this, in order to render every mention.
-	messageText = roleMentionRegex.
-		ReplaceAllStringFunc(messageText, func(data string) string {
-			roleID := strings.TrimSuffix(strings.TrimPrefix(data, "<@&"), ">")
-			role, cacheError := chatView.state.Role(message.GuildID, roleID)
-			if cacheError != nil {
-				return data
-			}
-
-			return linkColor + "@" + role.Name + "[white]"
-		})
-
-	messageText = strings.NewReplacer(
-		"@everyone", linkColor+"@everyone[white]",
-		"@here", linkColor+"@here[white]",
-	).Replace(messageText)
-
-	for _, user := range message.Mentions {
-		var userName string
-		if message.GuildID != "" {
-			member, cacheError := chatView.state.Member(message.GuildID, user.ID)
-			if cacheError == nil {
-				userName = discordutil.GetMemberName(member)
-			}
-		}
-
-		if userName == "" {
-			userName = discordutil.GetUserName(user)
+	messageText = discordutil.ResolveMentions(chatView.state, message, messageText, func(name string, mentionsSelf bool) string {
+		if mentionsSelf {
+			return "[#ef9826]" + tview.Escape(name) + "[white]"
 		}
 
-		var color string
-		if chatView.state.User.ID == user.ID {
-			color = "[#ef9826]"
-		} else {
-			color = linkColor
-		}
-
-		replacement := color + "@" + userName + "[white]"
-		messageText = strings.NewReplacer(
-			"<@"+user.ID+">", replacement,
-			"<@!"+user.ID+">", replacement,
-		).Replace(messageText)
-	}
-
-	messageText = channelMentionRegex.
-		ReplaceAllStringFunc(messageText, func(data string) string {
-			channelID := strings.TrimSuffix(strings.TrimPrefix(data, "<#"), ">")
-			channel, cacheError := chatView.state.Channel(channelID)
-			if cacheError != nil {
-				return data
-			}
-
-			return linkColor + "#" + channel.Name + "[white]"
-		})
+		return linkColor + tview.Escape(name) + "[white]"
+	})
 
 	// FIXME Needs improvement, as it wastes space and breaks things
 	if message.Attachments != nil && len(message.Attachments) > 0 {
@@ -768,6 +1034,20 @@ func (chatView *ChatView) ClearSelection() {
 	chatView.updateHighlights()
 }
 
+// SelectMessage selects the message with the given ID and scrolls to it.
+// If no such message is currently displayed, false is returned.
+func (chatView *ChatView) SelectMessage(messageID string) bool {
+	for index, message := range chatView.data {
+		if message.ID == messageID {
+			chatView.selection = index
+			chatView.updateHighlights()
+			return true
+		}
+	}
+
+	return false
+}
+
 // SignalSelectionDeleted notifies the ChatView that its currently selected
 // message doesn't exist anymore, moving the selection up by a row if possible.
 func (chatView *ChatView) SignalSelectionDeleted() {
@@ -780,6 +1060,11 @@ func (chatView *ChatView) SignalSelectionDeleted() {
 // manipulation of single message elements happens in this function.
 func (chatView *ChatView) SetMessages(messages []*discordgo.Message) {
 	chatView.data = make([]*discordgo.Message, 0)
+	chatView.bufferSize = maths.Max(defaultBufferSize, len(messages))
+	chatView.beginningReached = false
+	chatView.endReached = true
+	chatView.lastReadMessageID = 0
+	chatView.firstUnreadMessageID = ""
 	chatView.internalTextView.SetText("")
 
 	chatView.AddMessages(messages)
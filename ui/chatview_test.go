@@ -1,8 +1,10 @@
 package ui
 
 import (
+	"strings"
 	"testing"
 
+	"github.com/Bios-Marcel/cordless/discordutil"
 	_ "github.com/Bios-Marcel/cordless/syntax"
 	"github.com/Bios-Marcel/discordgo"
 )
@@ -434,3 +436,210 @@ func Test_removeLeadingWhitespaceInCode(t *testing.T) {
 		})
 	}
 }
+
+func TestChatView_onMessageRender(t *testing.T) {
+	state := discordgo.NewState()
+	state.User = &discordgo.User{ID: "1"}
+	chatView := NewChatView(state, "1")
+
+	var suffix string
+	chatView.SetOnMessageRender(func(message *discordgo.Message, text string) string {
+		return text + suffix
+	})
+
+	message := &discordgo.Message{
+		ID:      "M1",
+		Content: "**simple**",
+		Author:  &discordgo.User{ID: "2", Username: "Marcel"},
+	}
+	suffix = " [red](rendered)"
+	chatView.AddMessage(message)
+
+	want := "[::b]simple[::-] [red](rendered)"
+	if formatted := chatView.formattedMessages["M1"]; !strings.HasSuffix(formatted, want+"[\"\"][\"\"]") {
+		t.Errorf("Formatted message was '%s', want suffix '%s'", formatted, want)
+	}
+	if message.Content != "**simple**" {
+		t.Errorf("Original message has been changed to '%s'", message.Content)
+	}
+
+	//The cached output stays the same until the messages are reformatted.
+	suffix = " (changed)"
+	chatView.Rerender()
+	if formatted := chatView.formattedMessages["M1"]; !strings.Contains(formatted, "(rendered)") {
+		t.Errorf("Expected cached output to be used, got '%s'", formatted)
+	}
+
+	chatView.ReformatMessages()
+	if formatted := chatView.formattedMessages["M1"]; !strings.Contains(formatted, "(changed)") {
+		t.Errorf("Expected message to be reformatted, got '%s'", formatted)
+	}
+}
+
+func Test_formatReactions(t *testing.T) {
+	message := &discordgo.Message{
+		Reactions: []*discordgo.MessageReactions{
+			{Count: 2, Me: true, Emoji: &discordgo.Emoji{Name: "👍"}},
+			{Count: 1, Emoji: &discordgo.Emoji{ID: "50", Name: "gopher"}},
+		},
+	}
+
+	want := "\n[#ef9826]👍 2  [gray]:gopher: 1[white]"
+	if got := formatReactions(message); got != want {
+		t.Errorf("formatReactions() = %q, want %q", got, want)
+	}
+
+	if got := formatReactions(&discordgo.Message{}); got != "" {
+		t.Errorf("formatReactions() = %q, want an empty string", got)
+	}
+}
+
+func TestChatView_formatReplyPreview(t *testing.T) {
+	state := discordgo.NewState()
+	state.User = &discordgo.User{ID: "1"}
+	chatView := NewChatView(state, "1")
+
+	_, parseError := discordutil.ParseMessageReferences([]byte(`[
+		{"id": "R1", "channel_id": "C1", "message_reference": {"message_id": "O1", "channel_id": "C1"}},
+		{"id": "R2", "channel_id": "C1", "message_reference": {"message_id": "O2", "channel_id": "C1"}}
+	]`))
+	if parseError != nil {
+		t.Fatal(parseError)
+	}
+
+	chatView.AddMessage(&discordgo.Message{
+		ID:      "O1",
+		Content: "first line [red]\nsecond line",
+		Author:  &discordgo.User{ID: "2", Username: "Marcel"},
+	})
+
+	want := "[gray]╭─ Marcel: first line [red[] …\n"
+	if got := chatView.formatReplyPreview(&discordgo.Message{ID: "R1", ChannelID: "C1"}); got != want {
+		t.Errorf("formatReplyPreview() = %q, want %q", got, want)
+	}
+
+	want = "[gray]╭─ Original message isn't loaded\n"
+	if got := chatView.formatReplyPreview(&discordgo.Message{ID: "R2", ChannelID: "C1"}); got != want {
+		t.Errorf("formatReplyPreview() = %q, want %q", got, want)
+	}
+
+	if got := chatView.formatReplyPreview(&discordgo.Message{ID: "O1", ChannelID: "C1"}); got != "" {
+		t.Errorf("formatReplyPreview() = %q, want an empty string", got)
+	}
+}
+
+func TestChatView_PrependMessages(t *testing.T) {
+	state := discordgo.NewState()
+	state.User = &discordgo.User{ID: "1"}
+	chatView := NewChatView(state, "1")
+
+	author := &discordgo.User{ID: "2", Username: "Marcel"}
+	chatView.AddMessages([]*discordgo.Message{
+		{ID: "3", Content: "three", Author: author},
+		{ID: "4", Content: "four", Author: author},
+	})
+	chatView.SelectMessage("3")
+
+	var reachedTop int
+	chatView.SetOnReachTop(func() {
+		reachedTop++
+	})
+
+	chatView.PrependMessages([]*discordgo.Message{
+		{ID: "1", Content: "one", Author: author},
+		{ID: "2", Content: "two", Author: author},
+	})
+
+	var ids []string
+	for _, message := range chatView.data {
+		ids = append(ids, message.ID)
+	}
+	if strings.Join(ids, ",") != "1,2,3,4" {
+		t.Errorf("Messages were %v, want [1 2 3 4]", ids)
+	}
+	if chatView.selection != 2 {
+		t.Errorf("Selection was %d, want it to stay on the same message at index 2", chatView.selection)
+	}
+
+	chatView.signalReachTop()
+	chatView.SetBeginningReached(true)
+	chatView.signalReachTop()
+	if reachedTop != 1 {
+		t.Errorf("Reach top handler was called %d times, want 1", reachedTop)
+	}
+	if text := chatView.internalTextView.GetText(false); !strings.HasPrefix(text, beginningOfChannelText+"\n") {
+		t.Errorf("Expected the beginning marker at the top, got '%s'", text)
+	}
+
+	chatView.SetMessages(nil)
+	if chatView.beginningReached {
+		t.Error("Setting new messages should remove the beginning marker")
+	}
+}
+
+func TestChatView_SetLastReadMessage(t *testing.T) {
+	state := discordgo.NewState()
+	state.User = &discordgo.User{ID: "1"}
+	chatView := NewChatView(state, "1")
+
+	author := &discordgo.User{ID: "2", Username: "Marcel"}
+	chatView.SetMessages([]*discordgo.Message{
+		{ID: "10", Content: "read", Author: author},
+		{ID: "11", Content: "unread", Author: author},
+		{ID: "12", Content: "unread", Author: author},
+	})
+
+	chatView.SetLastReadMessage("10")
+	if count, more := chatView.GetUnreadCount(); count != 2 || more {
+		t.Errorf("GetUnreadCount() = %d, %v, want 2, false", count, more)
+	}
+	text := chatView.internalTextView.GetText(false)
+	if strings.Index(text, unreadDividerText) > strings.Index(text, "unread") ||
+		strings.Index(text, unreadDividerText) < strings.Index(text, "read") {
+		t.Errorf("Expected the divider between the read and unread messages, got '%s'", text)
+	}
+	if !chatView.SelectFirstUnreadMessage() || chatView.selection != 1 {
+		t.Errorf("Expected the first unread message to be selected, but selection was %d", chatView.selection)
+	}
+
+	//All loaded messages are unread, so there might be more.
+	chatView.SetLastReadMessage("5")
+	if count, more := chatView.GetUnreadCount(); count != 3 || !more {
+		t.Errorf("GetUnreadCount() = %d, %v, want 3, true", count, more)
+	}
+
+	chatView.SetLastReadMessage("12")
+	if count, _ := chatView.GetUnreadCount(); count != 0 {
+		t.Errorf("GetUnreadCount() = %d, want 0", count)
+	}
+	if strings.Contains(chatView.internalTextView.GetText(false), unreadDividerText) {
+		t.Error("There shouldn't be a divider if all messages are read")
+	}
+}
+
+func TestChatView_SetEndReached(t *testing.T) {
+	state := discordgo.NewState()
+	state.User = &discordgo.User{ID: "1"}
+	chatView := NewChatView(state, "1")
+
+	var reachedBottom int
+	chatView.SetOnReachBottom(func() {
+		reachedBottom++
+	})
+
+	chatView.signalReachBottom()
+	if reachedBottom != 0 {
+		t.Error("Reach bottom handler shouldn't be called while the latest messages are displayed")
+	}
+
+	chatView.SetEndReached(false)
+	chatView.signalReachBottom()
+	if reachedBottom != 1 {
+		t.Errorf("Reach bottom handler was called %d times, want 1", reachedBottom)
+	}
+
+	chatView.SetMessages(nil)
+	if !chatView.IsEndReached() {
+		t.Error("Setting new messages should assume that the latest messages are displayed")
+	}
+}
@@ -1,8 +1,11 @@
 package ui
 
 import (
+	"fmt"
 	"strings"
 
+	"github.com/Bios-Marcel/cordless/commands"
+	"github.com/Bios-Marcel/cordless/shortcuts"
 	"github.com/Bios-Marcel/tview"
 	"github.com/gdamore/tcell"
 )
@@ -18,16 +21,33 @@ type CommandView struct {
 	// commandHistoryIndex is the current index cycling thorugh the history.
 	// -1 means that no index is selected.
 	commandHistoryIndex int
-	// commandHistory is just a flat array of text that the user has sent.
-	// The more recent ones are on the right side of the array.
-	commandHistory []string
+	// commandHistory contains the commands that the user has sent.
+	commandHistory *commands.History
+
+	// searching decides whether the input is currently used for searching
+	// through the history instead of editing the command.
+	searching bool
+	// searchQuery is the text that is being searched for in the history.
+	searchQuery string
+	// searchIndex is the index of the current search result in the history.
+	searchIndex int
+	// searchOriginalText is the input before the search was started, it is
+	// restored if the search is cancelled.
+	searchOriginalText string
 
 	onExecuteCommand func(command string)
+	// isSensitive decides whether a command contains secrets and therefore
+	// mustn't be added to the history.
+	isSensitive func(command string) bool
+	// onComplete completes the given input and returns the completed input
+	// and all possible candidates.
+	onComplete func(input string) (string, []string)
 }
 
 // NewCommandView creates a new struct containing the components necessary
 // for a command view. It also contains the state for those components.
-func NewCommandView(onExecuteCommand func(command string)) *CommandView {
+// Commands for which isSensitive returns true aren't added to the history.
+func NewCommandView(onExecuteCommand func(command string), isSensitive func(command string) bool) *CommandView {
 	commandOutput := tview.NewTextView()
 	commandOutput.SetDynamicColors(true).
 		SetWordWrap(true).
@@ -45,16 +65,42 @@ func NewCommandView(onExecuteCommand func(command string)) *CommandView {
 		commandInput:  commandInput,
 
 		commandHistoryIndex: noHistoryIndexSelected,
-		commandHistory:      make([]string, 0),
+		commandHistory:      commands.NewHistory(0),
 
 		onExecuteCommand: onExecuteCommand,
+		isSensitive:      isSensitive,
 	}
 
 	commandInput.SetInputCapture(cmdView.handleInput)
 
+	//The search has to intercept all events before the editor handles them,
+	//since it would otherwise edit the text instead of the search query.
+	editorInputCapture := commandInput.internalTextView.GetInputCapture()
+	commandInput.internalTextView.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if cmdView.searching {
+			event = cmdView.handleSearchInput(event)
+			if event == nil {
+				return nil
+			}
+		}
+
+		return editorInputCapture(event)
+	})
+
 	return cmdView
 }
 
+// SetHistory replaces the command history.
+func (cmdView *CommandView) SetHistory(history *commands.History) {
+	cmdView.commandHistory = history
+	cmdView.commandHistoryIndex = noHistoryIndexSelected
+}
+
+// GetHistory returns the command history.
+func (cmdView *CommandView) GetHistory() *commands.History {
+	return cmdView.commandHistory
+}
+
 // SetInputCaptureForInput defines the input capture for the input component of
 // the command view while priorizing the predefined handler before passing the
 // event to the externally specified handler.
@@ -91,6 +137,11 @@ func (cmdView *CommandView) handleInput(event *tcell.EventKey) *tcell.EventKey {
 			return nil
 		}
 
+		if event.Key() == tcell.KeyTab {
+			cmdView.completeInput()
+			return nil
+		}
+
 		if event.Key() == tcell.KeyEnter {
 			//We are resetting the index whenever hitting enter, no matter
 			//whether the command itself was run sucessfully or not.
@@ -98,35 +149,40 @@ func (cmdView *CommandView) handleInput(event *tcell.EventKey) *tcell.EventKey {
 			//meaning the one that you just entered.
 			cmdView.commandHistoryIndex = noHistoryIndexSelected
 
-			command := cmdView.commandInput.GetText()
+			command := strings.TrimSpace(cmdView.commandInput.GetText())
 			if command == "" {
 				return nil
 			}
 
-			cmdView.onExecuteCommand(strings.TrimSpace(command))
+			cmdView.onExecuteCommand(command)
 			cmdView.commandInput.SetText("")
-			cmdView.commandHistory = append(cmdView.commandHistory, command)
+			if !cmdView.isSensitive(command) {
+				historyError := cmdView.commandHistory.Add(command)
+				if historyError != nil {
+					fmt.Fprintf(cmdView, "[red]Error saving command history:\n\t[red]%s\n", historyError)
+				}
+			}
 
 			return nil
 		}
 
 		if event.Key() == tcell.KeyDown {
-			if cmdView.commandHistoryIndex > len(cmdView.commandHistory)-1 {
+			if cmdView.commandHistoryIndex > cmdView.commandHistory.Len()-1 {
 				cmdView.commandHistoryIndex = 0
 			} else {
 				cmdView.commandHistoryIndex++
 			}
 
-			if cmdView.commandHistoryIndex > len(cmdView.commandHistory)-1 {
+			if cmdView.commandHistoryIndex > cmdView.commandHistory.Len()-1 {
 				return nil
 			}
 
-			cmdView.commandInput.SetText(cmdView.commandHistory[cmdView.commandHistoryIndex])
+			cmdView.commandInput.SetText(cmdView.commandHistory.Get(cmdView.commandHistoryIndex))
 		}
 
 		if event.Key() == tcell.KeyUp {
-			if cmdView.commandHistoryIndex < 0 {
-				cmdView.commandHistoryIndex = len(cmdView.commandHistory) - 1
+			if cmdView.commandHistoryIndex < 0 || cmdView.commandHistoryIndex > cmdView.commandHistory.Len() {
+				cmdView.commandHistoryIndex = cmdView.commandHistory.Len() - 1
 			} else {
 				cmdView.commandHistoryIndex--
 			}
@@ -135,10 +191,15 @@ func (cmdView *CommandView) handleInput(event *tcell.EventKey) *tcell.EventKey {
 				return nil
 			}
 
-			cmdView.commandInput.SetText(cmdView.commandHistory[cmdView.commandHistoryIndex])
+			cmdView.commandInput.SetText(cmdView.commandHistory.Get(cmdView.commandHistoryIndex))
 		}
 	}
 
+	if shortcuts.SearchCommandHistory.Equals(event) {
+		cmdView.startSearch()
+		return nil
+	}
+
 	if event.Modifiers() == tcell.ModCtrl {
 		if event.Key() == tcell.KeyUp {
 			handler := cmdView.commandOutput.InputHandler()
@@ -156,6 +217,106 @@ func (cmdView *CommandView) handleInput(event *tcell.EventKey) *tcell.EventKey {
 	return event
 }
 
+// startSearch starts a reverse search through the command history. Until
+// the search is finished, all input is used for the search query.
+func (cmdView *CommandView) startSearch() {
+	cmdView.searching = true
+	cmdView.searchQuery = ""
+	cmdView.searchIndex = cmdView.commandHistory.Len()
+	cmdView.searchOriginalText = cmdView.commandInput.GetText()
+	cmdView.updateSearchTitle(true)
+}
+
+// stopSearch ends the reverse search, keeping the current input.
+func (cmdView *CommandView) stopSearch() {
+	cmdView.searching = false
+	cmdView.commandInput.internalTextView.SetTitle("")
+}
+
+// handleSearchInput handles all input while searching through the history.
+// Events that don't belong to the search finish it and are passed on.
+func (cmdView *CommandView) handleSearchInput(event *tcell.EventKey) *tcell.EventKey {
+	if shortcuts.SearchCommandHistory.Equals(event) {
+		cmdView.search(cmdView.searchIndex - 1)
+		return nil
+	}
+
+	switch event.Key() {
+	case tcell.KeyEscape:
+		cmdView.stopSearch()
+		cmdView.commandInput.SetText(cmdView.searchOriginalText)
+		return nil
+	case tcell.KeyBackspace, tcell.KeyBackspace2:
+		queryRunes := []rune(cmdView.searchQuery)
+		if len(queryRunes) > 0 {
+			cmdView.searchQuery = string(queryRunes[:len(queryRunes)-1])
+		}
+		cmdView.search(cmdView.commandHistory.Len() - 1)
+		return nil
+	case tcell.KeyRune:
+		if event.Modifiers() == tcell.ModNone || event.Modifiers() == tcell.ModShift {
+			cmdView.searchQuery += string(event.Rune())
+			//The current result might still match the longer query.
+			cmdView.search(cmdView.searchIndex)
+			return nil
+		}
+	}
+
+	cmdView.stopSearch()
+	return event
+}
+
+// search looks for the search query in the history, starting at the given
+// index and going back in time. If there's a match, it is put into the
+// input.
+func (cmdView *CommandView) search(startIndex int) {
+	index := cmdView.commandHistory.Search(cmdView.searchQuery, startIndex)
+	if index != -1 {
+		cmdView.searchIndex = index
+		cmdView.commandInput.SetText(cmdView.commandHistory.Get(index))
+	}
+
+	cmdView.updateSearchTitle(index != -1 || cmdView.searchQuery == "")
+}
+
+func (cmdView *CommandView) updateSearchTitle(found bool) {
+	if found {
+		cmdView.commandInput.internalTextView.SetTitle(fmt.Sprintf("(reverse-i-search)`%s'", tview.Escape(cmdView.searchQuery)))
+	} else {
+		cmdView.commandInput.internalTextView.SetTitle(fmt.Sprintf("(failed reverse-i-search)`%s'", tview.Escape(cmdView.searchQuery)))
+	}
+}
+
+// SetOnComplete sets the handler that is used for completing the users
+// input. The handler returns the completed input and all candidates that
+// were considered for the completion.
+func (cmdView *CommandView) SetOnComplete(handler func(input string) (string, []string)) {
+	cmdView.onComplete = handler
+}
+
+// completeInput completes the last word of the current input. If there's
+// more than one candidate for the completion, all of them are printed into
+// the output.
+func (cmdView *CommandView) completeInput() {
+	if cmdView.onComplete == nil {
+		return
+	}
+
+	input := cmdView.commandInput.GetText()
+	completed, candidates := cmdView.onComplete(input)
+	if len(candidates) > 1 {
+		escapedCandidates := make([]string, 0, len(candidates))
+		for _, candidate := range candidates {
+			escapedCandidates = append(escapedCandidates, tview.Escape(candidate))
+		}
+		fmt.Fprintf(cmdView, "[gray]%s\n", strings.Join(escapedCandidates, "  "))
+	}
+
+	if completed != input {
+		cmdView.commandInput.SetText(completed)
+	}
+}
+
 // GetCommandInputWidget returns the component that can be added to the layout
 // for the users command input.
 func (cmdView *CommandView) GetCommandInputWidget() *tview.TextView {
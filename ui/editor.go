@@ -465,6 +465,11 @@ func (editor *Editor) SetBorderFocusColor(color tcell.Color) {
 	editor.internalTextView.SetBorderFocusColor(color)
 }
 
+// SetTitle delegates to the underlying components SetTitle method.
+func (editor *Editor) SetTitle(title string) {
+	editor.internalTextView.SetTitle(title)
+}
+
 // SetBorderColor delegates to the underlying components SetBorderColor
 // method.
 func (editor *Editor) SetBorderColor(color tcell.Color) {
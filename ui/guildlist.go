@@ -75,7 +75,7 @@ func (g *GuildList) RemoveGuild(guildID string) {
 // AddGuild adds a new node that references the given guildID and shows the
 // given name.
 func (g *GuildList) AddGuild(guildID, name string) {
-	node := tview.NewTreeNode(name)
+	node := tview.NewTreeNode(name + guildUnreadBadge(guildID))
 	node.SetReference(guildID)
 	g.GetRoot().AddChild(node)
 }
@@ -84,7 +84,7 @@ func (g *GuildList) AddGuild(guildID, name string) {
 func (g *GuildList) UpdateName(guildID, newName string) {
 	for _, node := range g.GetRoot().GetChildren() {
 		if node.GetReference() == guildID {
-			node.SetText(newName)
+			node.SetText(newName + guildUnreadBadge(guildID))
 			break
 		}
 	}
@@ -0,0 +1,113 @@
+package ui
+
+import (
+	"sync"
+	"time"
+
+	"github.com/Bios-Marcel/cordless/scripting"
+	"github.com/Bios-Marcel/discordgo"
+)
+
+// renderWaitTime is the time the UI waits for the scripts to render a
+// message. If they take longer, for example because the engine is still busy
+// with another hook, the unchanged text is displayed until the result
+// arrives.
+const renderWaitTime = 50 * time.Millisecond
+
+// messageRenderer calls the onMessageRender hook of the scripts without
+// blocking the UI thread for longer than renderWaitTime.
+type messageRenderer struct {
+	engine scripting.Engine
+	state  *discordgo.State
+	// onLateResult is called from a background goroutine as soon as a
+	// result that the UI stopped waiting for has arrived. It has to format
+	// the message again, which then picks up the result.
+	onLateResult func(message *discordgo.Message)
+
+	mutex *sync.Mutex
+	// pending contains the IDs of the messages that are being rendered.
+	pending map[string]bool
+	// lateResults contains the results that arrived too late, until the
+	// message is formatted again.
+	lateResults map[string]renderResult
+}
+
+// renderResult is the text a script has rendered for a certain input text.
+type renderResult struct {
+	text         string
+	renderedText string
+}
+
+func newMessageRenderer(engine scripting.Engine, state *discordgo.State, onLateResult func(message *discordgo.Message)) *messageRenderer {
+	return &messageRenderer{
+		engine:       engine,
+		state:        state,
+		onLateResult: onLateResult,
+		mutex:        &sync.Mutex{},
+		pending:      make(map[string]bool),
+		lateResults:  make(map[string]renderResult),
+	}
+}
+
+// render returns the text the scripts render for the given message. If the
+// scripts don't finish in time, the given text is returned instead. The
+// message is only rendered once at a time, further calls return the
+// unchanged text until the rendering has finished.
+func (renderer *messageRenderer) render(message *discordgo.Message, text string) string {
+	renderer.mutex.Lock()
+	lateResult, isLate := renderer.lateResults[message.ID]
+	delete(renderer.lateResults, message.ID)
+	if isLate && lateResult.text == text {
+		renderer.mutex.Unlock()
+		return lateResult.renderedText
+	}
+	if renderer.pending[message.ID] {
+		renderer.mutex.Unlock()
+		return text
+	}
+	renderer.pending[message.ID] = true
+	renderer.mutex.Unlock()
+
+	//The message is converted up front, since it might be changed by the
+	//event handlers while the scripts are still running.
+	scriptMessage := scripting.NewMessage(renderer.state, message)
+	done := make(chan string, 1)
+	go func() {
+		done <- renderer.engine.OnMessageRender(scriptMessage, text)
+	}()
+
+	timer := time.NewTimer(renderWaitTime)
+	defer timer.Stop()
+	select {
+	case renderedText := <-done:
+		renderer.mutex.Lock()
+		delete(renderer.pending, message.ID)
+		renderer.mutex.Unlock()
+		return renderedText
+	case <-timer.C:
+		go renderer.awaitLateResult(message, text, done)
+		return text
+	}
+}
+
+func (renderer *messageRenderer) awaitLateResult(message *discordgo.Message, text string, done <-chan string) {
+	renderedText := <-done
+
+	renderer.mutex.Lock()
+	delete(renderer.pending, message.ID)
+	renderer.lateResults[message.ID] = renderResult{
+		text:         text,
+		renderedText: renderedText,
+	}
+	renderer.mutex.Unlock()
+
+	renderer.onLateResult(message)
+}
+
+// dropLateResult removes the late result for the given message in case it
+// hasn't been picked up, since the message isn't displayed anymore.
+func (renderer *messageRenderer) dropLateResult(messageID string) {
+	renderer.mutex.Lock()
+	delete(renderer.lateResults, messageID)
+	renderer.mutex.Unlock()
+}
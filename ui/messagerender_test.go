@@ -0,0 +1,66 @@
+package ui
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Bios-Marcel/cordless/scripting"
+	"github.com/Bios-Marcel/discordgo"
+)
+
+// blockingEngine renders messages by appending a suffix, but only once it
+// has been released.
+type blockingEngine struct {
+	scripting.Engine
+	release chan bool
+	calls   int32
+}
+
+func (engine *blockingEngine) OnMessageRender(message *scripting.Message, text string) string {
+	atomic.AddInt32(&engine.calls, 1)
+	<-engine.release
+	return text + " (rendered)"
+}
+
+func TestMessageRenderer_render(t *testing.T) {
+	engine := &blockingEngine{release: make(chan bool, 2)}
+	lateResults := make(chan *discordgo.Message, 1)
+	renderer := newMessageRenderer(engine, discordgo.NewState(), func(message *discordgo.Message) {
+		lateResults <- message
+	})
+	message := &discordgo.Message{
+		ID:     "M1",
+		Author: &discordgo.User{ID: "2", Username: "Marcel"},
+	}
+
+	engine.release <- true
+	if rendered := renderer.render(message, "text"); rendered != "text (rendered)" {
+		t.Errorf("render() = '%s', want 'text (rendered)'", rendered)
+	}
+
+	//The engine is busy now, so the UI mustn't wait for it.
+	if rendered := renderer.render(message, "text"); rendered != "text" {
+		t.Errorf("render() = '%s' while the engine is busy, want 'text'", rendered)
+	}
+	if rendered := renderer.render(message, "text"); rendered != "text" {
+		t.Errorf("render() = '%s' while pending, want 'text'", rendered)
+	}
+
+	engine.release <- true
+	select {
+	case lateMessage := <-lateResults:
+		if lateMessage != message {
+			t.Errorf("Late result was reported for %v, want %v", lateMessage, message)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("The late result was never reported")
+	}
+
+	if rendered := renderer.render(message, "text"); rendered != "text (rendered)" {
+		t.Errorf("render() = '%s' after the late result, want 'text (rendered)'", rendered)
+	}
+	if calls := atomic.LoadInt32(&engine.calls); calls != 2 {
+		t.Errorf("The engine has been called %d times, want 2", calls)
+	}
+}
@@ -86,7 +86,11 @@ func (privateList *PrivateChatList) AddOrUpdateChannel(channel *discordgo.Channe
 	for _, node := range privateList.chatsNode.GetChildren() {
 		referenceChannelID, ok := node.GetReference().(string)
 		if ok && referenceChannelID == channel.ID {
-			node.SetText(discordutil.GetPrivateChannelName(channel))
+			if privateList.privateChannelStates[node] == loaded {
+				node.SetText(discordutil.GetPrivateChannelName(channel))
+			} else {
+				node.SetText(discordutil.GetPrivateChannelName(channel) + channelUnreadBadge(channel))
+			}
 			return
 		}
 	}
@@ -120,7 +124,7 @@ func (privateList *PrivateChatList) addChannel(channel *discordgo.Channel) {
 }
 
 func createPrivateChannelNode(channel *discordgo.Channel) *tview.TreeNode {
-	channelNode := tview.NewTreeNode(discordutil.GetPrivateChannelName(channel))
+	channelNode := tview.NewTreeNode(discordutil.GetPrivateChannelName(channel) + channelUnreadBadge(channel))
 	channelNode.SetReference(channel.ID)
 	return channelNode
 }
@@ -201,12 +205,14 @@ func (privateList *PrivateChatList) RemoveChannel(channel *discordgo.Channel) {
 	privateList.chatsNode.SetChildren(newChildren)
 }
 
-// MarkChannelAsUnread marks the channel as unread, coloring it red.
+// MarkChannelAsUnread marks the channel as unread, coloring it red and
+// updating its unread badge.
 func (privateList *PrivateChatList) MarkChannelAsUnread(channel *discordgo.Channel) {
 	for _, node := range privateList.chatsNode.GetChildren() {
 		referenceChannelID, ok := node.GetReference().(string)
 		if ok && referenceChannelID == channel.ID {
 			privateList.privateChannelStates[node] = unread
+			node.SetText(discordutil.GetPrivateChannelName(channel) + channelUnreadBadge(channel))
 			node.SetColor(tcell.ColorRed)
 			break
 		}
@@ -218,6 +224,11 @@ func (privateList *PrivateChatList) MarkChannelAsRead(channelID string) {
 	for _, node := range privateList.chatsNode.GetChildren() {
 		referenceChannelID, ok := node.GetReference().(string)
 		if ok && referenceChannelID == channelID {
+			channel, stateError := privateList.state.Channel(channelID)
+			if stateError == nil {
+				node.SetText(discordutil.GetPrivateChannelName(channel) + channelUnreadBadge(channel))
+			}
+
 			if privateList.privateChannelStates[node] != loaded {
 				privateList.privateChannelStates[node] = read
 				node.SetColor(tcell.ColorWhite)
@@ -272,6 +283,7 @@ func (privateList *PrivateChatList) MarkChannelAsLoaded(channel *discordgo.Chann
 		referenceChannelID, ok := node.GetReference().(string)
 		if ok && referenceChannelID == channel.ID {
 			privateList.privateChannelStates[node] = loaded
+			node.SetText(discordutil.GetPrivateChannelName(channel))
 			node.SetColor(tview.Styles.ContrastBackgroundColor)
 			break
 		}
@@ -0,0 +1,87 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/Bios-Marcel/cordless/commands"
+	"github.com/Bios-Marcel/cordless/scripting"
+	"github.com/gen2brain/beeep"
+)
+
+// scriptHost exposes parts of the window to the scripting engines. It
+// mustn't trigger any scripting hooks itself, since the scripts calling into
+// the host might currently be executing a hook.
+type scriptHost struct {
+	window *Window
+}
+
+var _ scripting.Host = &scriptHost{}
+
+// SendMessage implements scripting.Host.
+func (host *scriptHost) SendMessage(channelID, text string) error {
+	channel, stateError := host.window.session.State.Channel(channelID)
+	if stateError != nil {
+		return fmt.Errorf("channel '%s' couldn't be found", channelID)
+	}
+
+	text = strings.TrimSpace(text)
+	if len(text) == 0 {
+		return errors.New("messages can't be empty")
+	}
+
+	text = host.window.prepareMessage(channel, text)
+	if len(text) > 2000 {
+		return errors.New("messages must be 2000 characters or less to send")
+	}
+
+	go func() {
+		_, sendError := host.window.session.ChannelMessageSend(channel.ID, text)
+		if sendError != nil {
+			fmt.Fprintf(host.window.GetBackgroundOutput(), "[red]Error sending message from script:\n\t[red]%s\n", sendError)
+		}
+	}()
+
+	return nil
+}
+
+// GetSelectedChannel implements scripting.Host.
+func (host *scriptHost) GetSelectedChannel() *scripting.Channel {
+	if host.window.selectedChannel == nil {
+		return nil
+	}
+
+	return scripting.NewChannel(host.window.session.State, host.window.selectedChannel)
+}
+
+// GetChannel implements scripting.Host.
+func (host *scriptHost) GetChannel(channelID string) *scripting.Channel {
+	channel, stateError := host.window.session.State.Channel(channelID)
+	if stateError != nil {
+		return nil
+	}
+
+	return scripting.NewChannel(host.window.session.State, channel)
+}
+
+// ShowNotification implements scripting.Host.
+func (host *scriptHost) ShowNotification(title, body string) error {
+	return beeep.Notify(title, body, "assets/information.png")
+}
+
+// Print implements scripting.Host. Since the hooks of scripts are also
+// called outside of the UI thread, the text is printed via the update queue.
+func (host *scriptHost) Print(text string) {
+	fmt.Fprintln(host.window.GetBackgroundOutput(), text)
+}
+
+// RegisterCommand implements scripting.Host.
+func (host *scriptHost) RegisterCommand(command commands.Command) error {
+	return host.window.registerScriptCommand(command)
+}
+
+// UnregisterCommand implements scripting.Host.
+func (host *scriptHost) UnregisterCommand(command commands.Command) {
+	host.window.unregisterScriptCommand(command)
+}
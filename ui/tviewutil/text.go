@@ -1,6 +1,9 @@
 package tviewutil
 
-import "strings"
+import (
+	"regexp"
+	"strings"
+)
 
 // CalculateNeccessaryHeight calculates the necessary height in the ui given
 // the text and the width of the component the text will appear in.
@@ -17,3 +20,30 @@ func CalculateNeccessaryHeight(width int, text string) int {
 	return len(splitLines) + wrappedLines
 
 }
+
+var (
+	colorTagPattern  = regexp.MustCompile(`\[([a-zA-Z]+|#[0-9a-zA-Z]{6}|\-)?(:([a-zA-Z]+|#[0-9a-zA-Z]{6}|\-)?(:([lbdru]+|\-)?)?)?\]`)
+	regionTagPattern = regexp.MustCompile(`\["([a-zA-Z0-9_,;: \-\.]*)"\]`)
+	escapedPattern   = regexp.MustCompile(`\[([a-zA-Z0-9_,;: \-\."#]+)\[(\[*)\]`)
+)
+
+// StripTags removes all color and region tags from the given text and
+// unescapes escaped tags. The result is the text as it would be displayed
+// by a tview.TextView with dynamic colors and regions enabled.
+func StripTags(text string) string {
+	var result strings.Builder
+	var lastIndex int
+	for _, match := range escapedPattern.FindAllStringSubmatchIndex(text, -1) {
+		result.WriteString(stripUnescapedTags(text[lastIndex:match[0]]))
+		result.WriteString("[" + text[match[2]:match[3]] + text[match[4]:match[5]] + "]")
+		lastIndex = match[1]
+	}
+	result.WriteString(stripUnescapedTags(text[lastIndex:]))
+
+	return result.String()
+}
+
+func stripUnescapedTags(text string) string {
+	text = regionTagPattern.ReplaceAllString(text, "")
+	return colorTagPattern.ReplaceAllString(text, "")
+}
@@ -9,3 +9,22 @@ func TestCalculateNeccessaryHeight(t *testing.T) {
 		t.Errorf("Result was %d, but should've been 3", neccessaryHeight)
 	}
 }
+
+func TestStripTags(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"no tags", "no tags"},
+		{"[red]Error:\n\t[red]broken[white]", "Error:\n\tbroken"},
+		{"[::b]NAME[::-] [#ff0000]x", "NAME x"},
+		{`["region"]text[""]`, "text"},
+		{"escaped [red[]", "escaped [red]"},
+		{"[a b] stays", "[a b] stays"},
+	}
+	for _, tt := range tests {
+		if got := StripTags(tt.input); got != tt.want {
+			t.Errorf("StripTags(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
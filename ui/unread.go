@@ -0,0 +1,47 @@
+package ui
+
+import (
+	"strconv"
+
+	"github.com/Bios-Marcel/cordless/readstate"
+	"github.com/Bios-Marcel/discordgo"
+)
+
+// formatUnreadBadge creates the text that is appended to the name of a
+// channel, guild or private chat in order to show the number of unread
+// messages and mentions. If the count isn't complete, a plus is appended to
+// it. If there's nothing unread, an empty string is returned.
+func formatUnreadBadge(count int, complete bool, mentions int) string {
+	var badge string
+	if count > 0 {
+		badge = " (" + strconv.Itoa(count)
+		if !complete {
+			badge += "+"
+		}
+		badge += ")"
+	}
+
+	if mentions > 0 {
+		badge += " (@" + strconv.Itoa(mentions) + ")"
+	}
+
+	return badge
+}
+
+// channelUnreadBadge returns the unread badge for the given channel. Since
+// every message in a private chat is directed at the current user, mentions
+// are only shown for guild channels.
+func channelUnreadBadge(channel *discordgo.Channel) string {
+	count, complete := readstate.GetUnreadCount(channel)
+	var mentions int
+	if channel.GuildID != "" {
+		mentions = readstate.GetMentionCount(channel.ID)
+	}
+
+	return formatUnreadBadge(count, complete, mentions)
+}
+
+// guildUnreadBadge returns the unread badge for the guild with the given ID.
+func guildUnreadBadge(guildID string) string {
+	return formatUnreadBadge(readstate.GetGuildUnreadCount(guildID))
+}
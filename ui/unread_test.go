@@ -0,0 +1,24 @@
+package ui
+
+import "testing"
+
+func TestFormatUnreadBadge(t *testing.T) {
+	tests := []struct {
+		count    int
+		complete bool
+		mentions int
+		want     string
+	}{
+		{0, true, 0, ""},
+		{0, false, 0, ""},
+		{3, true, 0, " (3)"},
+		{3, false, 0, " (3+)"},
+		{0, true, 2, " (@2)"},
+		{12, false, 1, " (12+) (@1)"},
+	}
+	for _, tt := range tests {
+		if got := formatUnreadBadge(tt.count, tt.complete, tt.mentions); got != tt.want {
+			t.Errorf("formatUnreadBadge(%d, %v, %d) = %q, want %q", tt.count, tt.complete, tt.mentions, got, tt.want)
+		}
+	}
+}
@@ -3,9 +3,14 @@ package ui
 import (
 	"bytes"
 	"fmt"
+	"io"
 	"log"
+	"os"
+	"os/user"
+	"path/filepath"
 	"regexp"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/Bios-Marcel/discordemojimap"
@@ -20,6 +25,7 @@ import (
 	"github.com/Bios-Marcel/cordless/readstate"
 	"github.com/Bios-Marcel/cordless/scripting"
 	"github.com/Bios-Marcel/cordless/scripting/js"
+	"github.com/Bios-Marcel/cordless/scripting/lua"
 	"github.com/Bios-Marcel/cordless/shortcuts"
 	"github.com/Bios-Marcel/cordless/times"
 	"github.com/Bios-Marcel/cordless/ui/tviewutil"
@@ -33,6 +39,8 @@ const (
 	guildPageName    = "Guilds"
 	privatePageName  = "Private"
 	userInactiveTime = 10 * time.Second
+
+	scriptWatchInterval = 2 * time.Second
 )
 
 var (
@@ -61,6 +69,9 @@ type Window struct {
 	messageInput     *Editor
 
 	editingMessageID *string
+	replyingTo       *discordgo.Message
+
+	loadingMessages bool
 
 	userList *UserTree
 
@@ -75,15 +86,30 @@ type Window struct {
 	selectedChannel     *discordgo.Channel
 	previousChannel     *discordgo.Channel
 
-	jsEngine scripting.Engine
+	// scriptEngine runs the users scripts, no matter which language they are
+	// written in.
+	scriptEngine scripting.Engine
+	// stopScriptWatcher stops watching the script directory for changes. It
+	// is nil if the scripts aren't being watched.
+	stopScriptWatcher func()
 
 	commandMode bool
 	commandView *CommandView
 	commands    []commands.Command
+	// scriptCommands are commands registered by scripts. They are kept
+	// separately, since builtin commands always take precedence.
+	scriptCommands []commands.Command
+	// activeAliases contains the names of all aliases that are currently
+	// being executed. This prevents aliases from expanding endlessly.
+	activeAliases map[string]bool
 
 	userActive      bool
 	userActiveTimer *time.Timer
 
+	// backgroundOutput is shared by all background writers, so that their
+	// output stays in order.
+	backgroundOutput *backgroundOutput
+
 	doRestart chan bool
 }
 
@@ -91,13 +117,18 @@ type Window struct {
 //necessary handlers and functions. If this function returns an error, we can't
 //start the application.
 func NewWindow(doRestart chan bool, app *tview.Application, session *discordgo.Session, readyEvent *discordgo.Ready) (*Window, error) {
+	// References of the previous account mustn't be kept around.
+	discordutil.ClearAllMessageReferences()
+
 	window := &Window{
 		doRestart:       doRestart,
 		session:         session,
 		app:             app,
-		jsEngine:        js.New(),
+		scriptEngine:    scripting.NewCompositeEngine(js.New(), lua.New()),
+		activeAliases:   make(map[string]bool),
 		userActiveTimer: time.NewTimer(userInactiveTime),
 	}
+	window.backgroundOutput = &backgroundOutput{window: window}
 
 	go func() {
 		for {
@@ -106,13 +137,50 @@ func NewWindow(doRestart chan bool, app *tview.Application, session *discordgo.S
 		}
 	}()
 
-	window.commandView = NewCommandView(window.ExecuteCommand)
+	window.commandView = NewCommandView(window.ExecuteCommand, func(command string) bool {
+		return commands.IsSensitive(command, window.FindCommand)
+	})
+	window.commandView.SetOnComplete(window.completeCommand)
+	if config.GetConfig().CommandHistorySize > 0 {
+		history, historyError := loadCommandHistory()
+		if historyError != nil {
+			fmt.Fprintf(window.commandView, "[red]Error loading command history:\n\t[red]%s\n", historyError)
+		} else {
+			window.commandView.SetHistory(history)
+		}
+	}
 	log.SetOutput(window.commandView)
 
-	window.jsEngine.SetErrorOutput(window.commandView.commandOutput)
-	if err := window.jsEngine.LoadScripts(config.GetScriptDirectory()); err != nil {
+	//Errors can occur in hooks that aren't called on the UI thread.
+	window.scriptEngine.SetErrorOutput(window.GetBackgroundOutput())
+	window.scriptEngine.SetHost(&scriptHost{window})
+	window.scriptEngine.SetLimits(scripting.Limits{
+		Timeout:        time.Duration(config.GetConfig().ScriptTimeout) * time.Millisecond,
+		MaxStackDepth:  config.GetConfig().ScriptMaxStackDepth,
+		MaxFailures:    config.GetConfig().ScriptMaxFailures,
+		MaxStorageSize: config.GetConfig().ScriptMaxStorageSize,
+	})
+	window.scriptEngine.SetStorageDirectory(config.GetScriptStorageDirectory())
+	for _, disabledScript := range config.GetConfig().DisabledScripts {
+		window.scriptEngine.SetScriptEnabled(disabledScript, false)
+	}
+	if err := window.scriptEngine.LoadScripts(config.GetScriptDirectory()); err != nil {
 		return nil, err
 	}
+	if config.GetConfig().WatchScripts {
+		window.stopScriptWatcher = scripting.WatchDirectory(config.GetScriptDirectory(), scriptWatchInterval, func() {
+			//Reloading happens on the UI thread, since scripts register
+			//commands, which are also accessed by the UI.
+			window.app.QueueUpdateDraw(func() {
+				fmt.Fprintln(window.commandView, "[gray]Scripts have changed, reloading.")
+				reloadError := window.scriptEngine.Reload()
+				if reloadError != nil {
+					fmt.Fprintf(window.commandView, "[red]Error reloading scripts:\n\t[red]%s\n", reloadError)
+				}
+				window.ReformatMessages()
+			})
+		})
+	}
 
 	guilds := readyEvent.Guilds
 
@@ -197,6 +265,7 @@ func NewWindow(doRestart chan bool, app *tview.Application, session *discordgo.S
 
 	window.registerGuildHandlers()
 	window.registerGuildMemberHandlers()
+	window.registerReactionHandlers()
 
 	guildPage.AddItem(guildList, 0, 1, true)
 	guildPage.AddItem(channelTree, 0, 2, true)
@@ -240,7 +309,7 @@ func NewWindow(doRestart chan bool, app *tview.Application, session *discordgo.S
 
 		newChannel, discordError := window.session.UserChannelCreate(userID)
 		if discordError == nil {
-			messages, discordError := window.session.ChannelMessages(newChannel.ID, 100, "", "", "")
+			messages, discordError := discordutil.ChannelMessages(window.session, newChannel.ID, 100, "", "", "")
 			if discordError == nil {
 				for _, message := range messages {
 					window.session.State.MessageAdd(message)
@@ -255,6 +324,20 @@ func NewWindow(doRestart chan bool, app *tview.Application, session *discordgo.S
 		SetDirection(tview.FlexRow)
 
 	window.chatView = NewChatView(window.session.State, window.session.State.User.ID)
+	//The render hook is called on the UI thread, so the scripts are only
+	//waited for briefly. Results arriving later are applied afterwards.
+	var renderer *messageRenderer
+	renderer = newMessageRenderer(window.scriptEngine, window.session.State, func(message *discordgo.Message) {
+		window.app.QueueUpdateDraw(func() {
+			window.chatView.Lock()
+			defer window.chatView.Unlock()
+			window.chatView.UpdateMessage(message)
+			renderer.dropLateResult(message.ID)
+		})
+	})
+	window.chatView.SetOnMessageRender(renderer.render)
+	window.chatView.SetOnReachTop(window.loadOlderMessages)
+	window.chatView.SetOnReachBottom(window.loadNewerMessages)
 	window.chatView.SetOnMessageAction(func(message *discordgo.Message, event *tcell.EventKey) *tcell.EventKey {
 		if shortcuts.QuoteSelectedMessage.Equals(event) {
 			window.insertQuoteOfMessage(message)
@@ -262,8 +345,18 @@ func NewWindow(doRestart chan bool, app *tview.Application, session *discordgo.S
 		}
 
 		if shortcuts.ReplySelectedMessage.Equals(event) {
-			window.messageInput.SetText("@" + message.Author.Username + "#" + message.Author.Discriminator + " " + window.messageInput.GetText())
-			app.SetFocus(window.messageInput.GetPrimitive())
+			window.startReplyingToMessage(message)
+			return nil
+		}
+
+		if shortcuts.JumpToReferencedMessage.Equals(event) {
+			if reference := discordutil.GetMessageReference(message.ChannelID, message.ID); reference != nil &&
+				!window.chatView.SelectMessage(reference.MessageID) {
+				jumpError := window.JumpToMessage(reference.ChannelID, reference.MessageID)
+				if jumpError != nil {
+					window.ShowErrorDialog(fmt.Sprintf("Error jumping to original message: %s", jumpError.Error()))
+				}
+			}
 			return nil
 		}
 
@@ -287,6 +380,15 @@ func NewWindow(doRestart chan bool, app *tview.Application, session *discordgo.S
 			return nil
 		}
 
+		if shortcuts.ReactToSelectedMessage.Equals(event) {
+			if !window.commandMode {
+				window.SetCommandModeEnabled(true)
+			}
+			window.commandView.commandInput.SetText("react --message " + message.ID + " ")
+			app.SetFocus(window.commandView.commandInput.GetPrimitive())
+			return nil
+		}
+
 		if shortcuts.CopySelectedMessage.Equals(event) {
 			copyError := clipboard.WriteAll(message.ContentWithMentionsReplaced())
 			if copyError != nil {
@@ -400,6 +502,7 @@ func NewWindow(doRestart chan bool, app *tview.Application, session *discordgo.S
 
 		if event.Key() == tcell.KeyEsc {
 			window.exitMessageEditMode()
+			window.exitReplyMode()
 			return nil
 		}
 
@@ -691,20 +794,7 @@ func NewWindow(doRestart chan bool, app *tview.Application, session *discordgo.S
 		if readstate.UpdateReadLocal(event.ChannelID, event.MessageID) {
 			channel, stateError := s.State.Channel(event.ChannelID)
 			if stateError == nil && event.MessageID == channel.LastMessageID {
-				if channel.GuildID == "" {
-					window.privateList.MarkChannelAsRead(channel.ID)
-				} else {
-					if window.selectedGuild != nil && channel.GuildID == window.selectedGuild.ID {
-						window.channelTree.MarkChannelAsRead(channel.ID)
-					} else {
-						for _, guildNode := range window.guildList.GetRoot().GetChildren() {
-							if guildNode.GetReference() == channel.GuildID {
-								window.updateServerReadStatus(channel.GuildID, guildNode, false)
-								break
-							}
-						}
-					}
-				}
+				window.updateChannelReadStatus(channel)
 			}
 		}
 	})
@@ -839,6 +929,8 @@ func NewWindow(doRestart chan bool, app *tview.Application, session *discordgo.S
 
 	window.registerMouseFocusListeners()
 
+	window.scriptEngine.OnStartup()
+
 	return window, nil
 }
 
@@ -923,16 +1015,88 @@ func (window *Window) TrySendMessage(targetChannel *discordgo.Channel, message s
 		return
 	}
 
-	go window.sendMessage(targetChannel.ID, message)
+	var reference *discordutil.MessageReference
+	if window.replyingTo != nil && window.replyingTo.ChannelID == targetChannel.ID {
+		reference = &discordutil.MessageReference{
+			MessageID: window.replyingTo.ID,
+			ChannelID: targetChannel.ID,
+			GuildID:   targetChannel.GuildID,
+		}
+	}
+
+	go window.sendMessage(targetChannel.ID, message, reference)
+}
+
+// sendMessage lets the scripts decide about the message and sends it
+// afterwards. Scripts may cancel the message, redirect it into a different
+// channel or require the user to confirm sending it. If the message is a
+// reply, the reference is dropped when the message is redirected, since
+// replies have to be in the same channel as the original message.
+func (window *Window) sendMessage(targetChannelID, message string, reference *discordutil.MessageReference) {
+	var scriptChannel *scripting.Channel
+	targetChannel, stateError := window.session.State.Channel(targetChannelID)
+	if stateError == nil {
+		scriptChannel = scripting.NewChannel(window.session.State, targetChannel)
+	} else {
+		scriptChannel = &scripting.Channel{ID: targetChannelID}
+	}
+
+	result := window.scriptEngine.OnMessageSend(scriptChannel, message)
+	if result.Cancel {
+		window.app.QueueUpdateDraw(func() {
+			dialogText := "A script prevented sending this message."
+			if result.Reason != "" {
+				dialogText = fmt.Sprintf("A script prevented sending this message:\n\n%s", result.Reason)
+			}
+			window.ShowDialog(tcell.ColorRed, dialogText, func(_ string) {}, "Okay")
+		})
+		return
+	}
+
+	if result.ChannelID != "" && result.ChannelID != targetChannelID {
+		targetChannelID = result.ChannelID
+		reference = nil
+	}
+
+	if !result.Confirm {
+		window.deliverMessage(targetChannelID, result.Text, reference)
+		return
+	}
+
+	window.app.QueueUpdateDraw(func() {
+		send := "Send"
+		cancel := "Cancel"
+		dialogText := "A script requires confirmation before sending this message."
+		if result.Reason != "" {
+			dialogText = result.Reason
+		}
+		if redirectChannel, stateError := window.session.State.Channel(targetChannelID); stateError == nil && targetChannelID != scriptChannel.ID {
+			dialogText = fmt.Sprintf("%s\n\nThe message will be sent to '%s'.", dialogText, redirectChannel.Name)
+		}
+		window.ShowDialog(tcell.ColorYellow, dialogText+"\n\nDo you want to send it?",
+			func(button string) {
+				if button == send {
+					go window.deliverMessage(targetChannelID, result.Text, reference)
+				}
+			}, send, cancel)
+	})
 }
 
-func (window *Window) sendMessage(targetChannelID, message string) {
-	messageText := window.jsEngine.OnMessageSend(message)
+// deliverMessage sends the message without consulting the scripts. In case
+// of an error the user is asked whether to retry sending the message.
+func (window *Window) deliverMessage(targetChannelID, messageText string, reference *discordutil.MessageReference) {
 	window.app.QueueUpdateDraw(func() {
 		window.messageInput.SetText("")
+		window.exitReplyMode()
 		window.chatView.internalTextView.ScrollToEnd()
 	})
-	_, sendError := window.session.ChannelMessageSend(targetChannelID, messageText)
+
+	var sendError error
+	if reference != nil {
+		_, sendError = discordutil.SendReply(window.session, targetChannelID, messageText, reference)
+	} else {
+		_, sendError = window.session.ChannelMessageSend(targetChannelID, messageText)
+	}
 	if sendError != nil {
 		window.app.QueueUpdateDraw(func() {
 			retry := "Retry sending"
@@ -943,7 +1107,7 @@ func (window *Window) sendMessage(targetChannelID, message string) {
 				func(button string) {
 					switch button {
 					case retry:
-						go window.sendMessage(targetChannelID, message)
+						go window.deliverMessage(targetChannelID, messageText, reference)
 					case edit:
 						window.messageInput.SetText(messageText)
 					}
@@ -1012,7 +1176,84 @@ func (window *Window) PopulateMentionWindow(mentionWindow *tview.TreeView, nameP
 	}
 }
 
+// updateChannelReadStatus updates the nodes of the given channel and its
+// guild after the channel has been read.
+func (window *Window) updateChannelReadStatus(channel *discordgo.Channel) {
+	if channel.GuildID == "" {
+		window.privateList.MarkChannelAsRead(channel.ID)
+		return
+	}
+
+	isSelected := window.selectedGuild != nil && channel.GuildID == window.selectedGuild.ID
+	if isSelected {
+		window.channelTree.MarkChannelAsRead(channel.ID)
+	}
+	for _, guildNode := range window.guildList.GetRoot().GetChildren() {
+		if guildNode.GetReference() == channel.GuildID {
+			window.updateServerReadStatus(channel.GuildID, guildNode, isSelected)
+			break
+		}
+	}
+}
+
+// MarkGuildsAsRead acknowledges all unread channels of the given guilds and
+// updates their nodes. Since the acknowledgements are rate-limited, this
+// blocks until all channels have been read. The number of channels that
+// have been marked as read is returned. Only one guild or everything can be
+// marked as read at a time.
+func (window *Window) MarkGuildsAsRead(guildIDs ...string) (int, error) {
+	var unreadChannels []*discordgo.Channel
+	for _, guildID := range guildIDs {
+		unreadChannels = append(unreadChannels, readstate.GetUnreadGuildChannels(guildID)...)
+	}
+
+	return window.markChannelsAsRead(unreadChannels)
+}
+
+// MarkEverythingAsRead acknowledges all unread channels of all guilds and
+// all unread private chats. Just like MarkGuildsAsRead, this blocks until
+// all channels have been read.
+func (window *Window) MarkEverythingAsRead() (int, error) {
+	unreadChannels := readstate.GetUnreadPrivateChannels()
+	for _, guild := range window.session.State.Guilds {
+		unreadChannels = append(unreadChannels, readstate.GetUnreadGuildChannels(guild.ID)...)
+	}
+
+	return window.markChannelsAsRead(unreadChannels)
+}
+
+func (window *Window) markChannelsAsRead(channels []*discordgo.Channel) (int, error) {
+	var read int
+	ackError := readstate.UpdateReadForChannels(window.session, channels, func(channel *discordgo.Channel) {
+		read++
+		window.app.QueueUpdateDraw(func() {
+			window.updateChannelReadStatus(channel)
+		})
+	})
+
+	return read, ackError
+}
+
+// markAsReadInBackground runs the given function for marking channels as
+// read in the background, showing an error dialog if it fails.
+func (window *Window) markAsReadInBackground(markAsRead func() (int, error)) {
+	go func() {
+		if _, ackError := markAsRead(); ackError != nil {
+			window.app.QueueUpdateDraw(func() {
+				window.ShowErrorDialog(fmt.Sprintf("Error marking channels as read: %s", ackError))
+			})
+		}
+	}()
+}
+
+// updateServerReadStatus updates the colour and the unread badge of the node
+// that represents the given guild.
 func (window *Window) updateServerReadStatus(guildID string, guildNode *tview.TreeNode, isSelected bool) {
+	guild, cacheError := window.session.State.Guild(guildID)
+	if cacheError == nil {
+		guildNode.SetText(guild.Name + guildUnreadBadge(guildID))
+	}
+
 	if isSelected {
 		guildNode.SetColor(tview.Styles.ContrastBackgroundColor)
 	} else {
@@ -1146,9 +1387,9 @@ func (window *Window) registerMouseFocusListeners() {
 		if event.Buttons() == tcell.Button1 {
 			window.app.SetFocus(window.chatView.internalTextView)
 		} else if event.Buttons() == tcell.WheelDown {
-			window.chatView.internalTextView.ScrollDown()
+			window.chatView.ScrollDown()
 		} else if event.Buttons() == tcell.WheelUp {
-			window.chatView.internalTextView.ScrollUp()
+			window.chatView.ScrollUp()
 		} else {
 			return false
 		}
@@ -1230,6 +1471,20 @@ func (window *Window) registerMouseFocusListeners() {
 }
 
 func (window *Window) registerMessageEventHandler(input, edit, delete chan *discordgo.Message, bulkDelete chan *discordgo.MessageDeleteBulk) {
+	//discordgo doesn't know about replies, so we read them from the raw data.
+	window.session.AddHandler(func(s *discordgo.Session, event *discordgo.Event) {
+		if event.Type != "MESSAGE_CREATE" && event.Type != "MESSAGE_UPDATE" {
+			return
+		}
+
+		// The originals of replies in other channels are only resolved once
+		// those channels are loaded.
+		replies, parseError := discordutil.ParseMessageReferences(event.RawData)
+		selectedChannel := window.selectedChannel
+		if parseError == nil && len(replies) > 0 && selectedChannel != nil && replies[0].ChannelID == selectedChannel.ID {
+			window.resolveReplies(replies)
+		}
+	})
 	window.session.AddHandler(func(s *discordgo.Session, m *discordgo.MessageCreate) {
 		input <- m.Message
 	})
@@ -1249,6 +1504,57 @@ func (window *Window) registerMessageEventHandler(input, edit, delete chan *disc
 	})
 }
 
+// loadReferencedMessages makes sure that the messages replied to by any of
+// the given messages are available for the reply previews.
+func (window *Window) loadReferencedMessages(messages []*discordgo.Message) {
+	var replies []*discordutil.Reply
+	for _, message := range messages {
+		if discordutil.GetMessageReference(message.ChannelID, message.ID) != nil {
+			replies = append(replies, &discordutil.Reply{MessageID: message.ID, ChannelID: message.ChannelID})
+		}
+	}
+
+	if len(replies) > 0 {
+		window.resolveReplies(replies)
+	}
+}
+
+// resolveReplies retrieves the messages replied to by the given replies in
+// the background, unless they are already known, and updates the displayed
+// replies afterwards.
+func (window *Window) resolveReplies(replies []*discordutil.Reply) {
+	go func() {
+		for _, reply := range replies {
+			reference := discordutil.GetMessageReference(reply.ChannelID, reply.MessageID)
+			if reference == nil || discordutil.GetReferencedMessage(reference) != nil {
+				continue
+			}
+			if _, stateError := window.session.State.Message(reference.ChannelID, reference.MessageID); stateError == nil {
+				continue
+			}
+
+			original, discordError := window.session.ChannelMessage(reference.ChannelID, reference.MessageID)
+			if discordError == nil {
+				discordutil.StoreReferencedMessage(original)
+			}
+		}
+
+		window.chatView.Lock()
+		defer window.chatView.Unlock()
+		for _, message := range window.chatView.data {
+			for _, reply := range replies {
+				if message.ID == reply.MessageID {
+					tempMessage := message
+					window.app.QueueUpdateDraw(func() {
+						window.chatView.UpdateMessage(tempMessage)
+					})
+					break
+				}
+			}
+		}
+	}()
+}
+
 // startMessageHandlerRoutines registers the handlers for certain message
 // events. It updates the cache and the UI if necessary.
 func (window *Window) startMessageHandlerRoutines(input, edit, delete chan *discordgo.Message, bulkDelete chan *discordgo.MessageDeleteBulk) {
@@ -1262,29 +1568,23 @@ func (window *Window) startMessageHandlerRoutines(input, edit, delete chan *disc
 				continue
 			}
 
+			window.scriptEngine.OnMessageReceive(scripting.NewMessage(window.session.State, tempMessage))
+
 			window.chatView.Lock()
 			if window.selectedChannel != nil && tempMessage.ChannelID == window.selectedChannel.ID {
 				if tempMessage.Author.ID != window.session.State.User.ID {
 					readstate.UpdateReadBuffered(window.session, channel, tempMessage.ID)
 				}
 
-				window.app.QueueUpdateDraw(func() {
-					window.chatView.AddMessage(tempMessage)
-				})
-			}
-			window.chatView.Unlock()
-
-			if channel.Type == discordgo.ChannelTypeGuildText {
-				for _, guildNode := range window.guildList.GetRoot().GetChildren() {
-					if guildNode.GetReference() == channel.GuildID &&
-						(window.selectedGuild == nil || window.selectedGuild.ID != channel.GuildID) {
-						window.app.QueueUpdateDraw(func() {
-							window.updateServerReadStatus(channel.GuildID, guildNode, false)
-						})
-						break
-					}
+				//If older messages are displayed, the new message will be
+				//retrieved once the user scrolls down to the latest messages.
+				if window.chatView.IsEndReached() {
+					window.app.QueueUpdateDraw(func() {
+						window.chatView.AddMessage(tempMessage)
+					})
 				}
 			}
+			window.chatView.Unlock()
 
 			if channel.Type == discordgo.ChannelTypeGuildText || channel.Type == discordgo.ChannelTypeDM ||
 				channel.Type == discordgo.ChannelTypeGroupDM {
@@ -1297,21 +1597,35 @@ func (window *Window) startMessageHandlerRoutines(input, edit, delete chan *disc
 				})
 			}
 
-			if tempMessage.Author.ID == window.session.State.User.ID {
+			// The read state and the mention count have to be up to date
+			// before the unread badge of the guild gets updated.
+			isOwnMessage := tempMessage.Author.ID == window.session.State.User.ID
+			isUnread := window.selectedChannel == nil || tempMessage.ChannelID != window.selectedChannel.ID ||
+				!window.userActive
+			mentionsYou := discordutil.MentionsCurrentUser(window.session.State, tempMessage)
+			if isOwnMessage {
 				readstate.UpdateReadLocal(tempMessage.ChannelID, tempMessage.ID)
-				continue
+			} else if isUnread && mentionsYou && channel.Type == discordgo.ChannelTypeGuildText {
+				readstate.AddMention(channel.ID)
 			}
 
-			if (window.selectedChannel == nil || tempMessage.ChannelID != window.selectedChannel.ID) ||
-				!window.userActive {
-				mentionsYou := false
-				for _, user := range tempMessage.Mentions {
-					if user.ID == window.session.State.User.ID {
-						mentionsYou = true
+			if channel.Type == discordgo.ChannelTypeGuildText {
+				for _, guildNode := range window.guildList.GetRoot().GetChildren() {
+					if guildNode.GetReference() == channel.GuildID {
+						window.app.QueueUpdateDraw(func() {
+							isSelected := window.selectedGuild != nil && window.selectedGuild.ID == channel.GuildID
+							window.updateServerReadStatus(channel.GuildID, guildNode, isSelected)
+						})
 						break
 					}
 				}
+			}
+
+			if isOwnMessage {
+				continue
+			}
 
+			if isUnread {
 				if config.GetConfig().DesktopNotifications {
 					if !mentionsYou {
 						if channel.Type == discordgo.ChannelTypeDM || channel.Type == discordgo.ChannelTypeGroupDM {
@@ -1384,6 +1698,12 @@ func (window *Window) startMessageHandlerRoutines(input, edit, delete chan *disc
 	go func() {
 		for messageDeleted := range delete {
 			tempMessageDeleted := messageDeleted
+			cachedMessage, stateError := window.session.State.Message(tempMessageDeleted.ChannelID, tempMessageDeleted.ID)
+			if stateError == nil {
+				window.scriptEngine.OnMessageDelete(scripting.NewMessage(window.session.State, cachedMessage))
+			} else {
+				window.scriptEngine.OnMessageDelete(scripting.NewMessage(window.session.State, tempMessageDeleted))
+			}
 			window.session.State.MessageRemove(tempMessageDeleted)
 			window.chatView.Lock()
 			if window.selectedChannel != nil && window.selectedChannel.ID == tempMessageDeleted.ChannelID {
@@ -1401,7 +1721,11 @@ func (window *Window) startMessageHandlerRoutines(input, edit, delete chan *disc
 			for _, messageID := range messagesDeleted.Messages {
 				message, stateError := window.session.State.Message(tempMessagesDeleted.ChannelID, messageID)
 				if stateError == nil {
+					window.scriptEngine.OnMessageDelete(scripting.NewMessage(window.session.State, message))
 					window.session.State.MessageRemove(message)
+				} else {
+					window.scriptEngine.OnMessageDelete(scripting.NewMessage(window.session.State,
+						&discordgo.Message{ID: messageID, ChannelID: tempMessagesDeleted.ChannelID}))
 				}
 			}
 
@@ -1419,6 +1743,12 @@ func (window *Window) startMessageHandlerRoutines(input, edit, delete chan *disc
 		for messageEdited := range edit {
 			tempMessageEdited := messageEdited
 			window.session.State.MessageAdd(tempMessageEdited)
+			cachedMessage, stateError := window.session.State.Message(tempMessageEdited.ChannelID, tempMessageEdited.ID)
+			if stateError == nil {
+				window.scriptEngine.OnMessageEdit(scripting.NewMessage(window.session.State, cachedMessage))
+			} else {
+				window.scriptEngine.OnMessageEdit(scripting.NewMessage(window.session.State, tempMessageEdited))
+			}
 			window.chatView.Lock()
 			if window.selectedChannel != nil && window.selectedChannel.ID == tempMessageEdited.ChannelID {
 				for _, message := range window.chatView.data {
@@ -1441,6 +1771,47 @@ func (window *Window) startMessageHandlerRoutines(input, edit, delete chan *disc
 	}()
 }
 
+// registerReactionHandlers keeps the reactions of the displayed messages up
+// to date, since discordgo doesn't track them.
+func (window *Window) registerReactionHandlers() {
+	window.session.AddHandler(func(s *discordgo.Session, event *discordgo.MessageReactionAdd) {
+		window.updateReactions(event.MessageReaction, func(message *discordgo.Message) {
+			discordutil.AddReaction(message, &event.Emoji, event.UserID == s.State.User.ID)
+		})
+	})
+	window.session.AddHandler(func(s *discordgo.Session, event *discordgo.MessageReactionRemove) {
+		window.updateReactions(event.MessageReaction, func(message *discordgo.Message) {
+			discordutil.RemoveReaction(message, &event.Emoji, event.UserID == s.State.User.ID)
+		})
+	})
+	window.session.AddHandler(func(s *discordgo.Session, event *discordgo.MessageReactionRemoveAll) {
+		window.updateReactions(event.MessageReaction, func(message *discordgo.Message) {
+			message.Reactions = nil
+		})
+	})
+}
+
+// updateReactions applies the change to the affected message if it is
+// currently displayed and rerenders it.
+func (window *Window) updateReactions(reaction *discordgo.MessageReaction, update func(message *discordgo.Message)) {
+	window.chatView.Lock()
+	defer window.chatView.Unlock()
+
+	if window.selectedChannel == nil || window.selectedChannel.ID != reaction.ChannelID {
+		return
+	}
+
+	for _, message := range window.chatView.data {
+		if message.ID == reaction.MessageID {
+			window.app.QueueUpdateDraw(func() {
+				update(message)
+				window.chatView.UpdateMessage(message)
+			})
+			break
+		}
+	}
+}
+
 func (window *Window) registerGuildHandlers() {
 	//Using buffered channels with a size of three, since this shouldn't really happen often
 
@@ -1553,6 +1924,7 @@ func (window *Window) registerPrivateChatsHandler() {
 			})
 			readstate.ClearReadStateFor(event.ID)
 		}
+		discordutil.ClearMessageReferences(event.ID)
 	})
 
 	window.session.AddHandler(func(s *discordgo.Session, event *discordgo.ChannelUpdate) {
@@ -1756,7 +2128,10 @@ func (window *Window) handleGlobalShortcuts(event *tcell.EventKey) *tcell.EventK
 		}
 
 		window.app.SetFocus(window.commandView.commandOutput)
-	} else if shortcuts.FocusCommandInput.Equals(event) {
+	} else if shortcuts.FocusCommandInput.Equals(event) &&
+		window.app.GetFocus() != window.commandView.commandInput.internalTextView {
+		//The default shortcut is the same as Tab, which the command input
+		//uses for completion as soon as it is focused.
 		if !window.commandMode {
 			window.SetCommandModeEnabled(true)
 		}
@@ -1786,6 +2161,26 @@ func (window *Window) handleGlobalShortcuts(event *tcell.EventKey) *tcell.EventK
 	} else if shortcuts.FocusGuildContainer.Equals(event) {
 		window.SwitchToGuildsPage()
 		window.app.SetFocus(window.guildList)
+	} else if shortcuts.JumpToFirstUnreadMessage.Equals(event) {
+		window.app.SetFocus(window.chatView.internalTextView)
+		window.chatView.SelectFirstUnreadMessage()
+	} else if shortcuts.MarkGuildAsRead.Equals(event) {
+		// The guild highlighted in the guild list takes precedence over
+		// the loaded one.
+		var guildID string
+		if node := window.guildList.GetCurrentNode(); node != nil {
+			guildID, _ = node.GetReference().(string)
+		}
+		if guildID == "" && window.selectedGuild != nil {
+			guildID = window.selectedGuild.ID
+		}
+		if guildID != "" {
+			window.markAsReadInBackground(func() (int, error) {
+				return window.MarkGuildsAsRead(guildID)
+			})
+		}
+	} else if shortcuts.MarkEverythingAsRead.Equals(event) {
+		window.markAsReadInBackground(window.MarkEverythingAsRead)
 	} else if shortcuts.FocusMessageContainer.Equals(event) {
 		window.app.SetFocus(window.chatView.internalTextView)
 	} else if shortcuts.FocusUserContainer.Equals(event) {
@@ -1801,8 +2196,31 @@ func (window *Window) handleGlobalShortcuts(event *tcell.EventKey) *tcell.EventK
 	return nil
 }
 
+// FindCommand returns the command that has the given name or alias.
+// User-defined aliases take precedence over builtin commands, which in turn
+// take precedence over commands registered by scripts. An alias is ignored
+// while it is being executed, so that it can refer to the command it
+// shadows. If no command can be found, nil is returned.
 func (window *Window) FindCommand(name string) commands.Command {
-	for _, cmd := range window.commands {
+	commandLines, isAlias := config.GetAliases()[name]
+	if isAlias && !window.activeAliases[name] {
+		return &aliasCommand{
+			window:       window,
+			name:         name,
+			commandLines: commandLines,
+		}
+	}
+
+	command := findCommandIn(window.commands, name)
+	if command != nil {
+		return command
+	}
+
+	return findCommandIn(window.scriptCommands, name)
+}
+
+func findCommandIn(commandList []commands.Command, name string) commands.Command {
+	for _, cmd := range commandList {
 		if cmd.Name() == name {
 			return cmd
 		}
@@ -1817,25 +2235,116 @@ func (window *Window) FindCommand(name string) commands.Command {
 	return nil
 }
 
-//ExecuteCommand tries to execute the given input as a command. The first word
-//will be passed as the commands name and the rest will be parameters. If a
-//command can't be found, that info will be printed onto the command output.
+// loadCommandHistory loads the command history from the configuration
+// directory.
+func loadCommandHistory() (*commands.History, error) {
+	historyFile, historyFileError := config.GetCommandHistoryFile()
+	if historyFileError != nil {
+		return nil, historyFileError
+	}
+
+	return commands.LoadHistory(historyFile, config.GetConfig().CommandHistorySize)
+}
+
+// GetCommandHistory returns the history of the commands that the user has
+// executed.
+func (window *Window) GetCommandHistory() *commands.History {
+	return window.commandView.GetHistory()
+}
+
+// completeCommand completes the last word of the given command line. Command
+// names are completed using the registered commands and the users aliases.
+func (window *Window) completeCommand(input string) (string, []string) {
+	registeredCommands := window.GetRegisteredCommands()
+	commandNames := make([]string, 0, len(registeredCommands)+len(config.GetAliases()))
+	for _, command := range registeredCommands {
+		commandNames = append(commandNames, command.Name())
+	}
+	for alias := range config.GetAliases() {
+		commandNames = append(commandNames, alias)
+	}
+
+	return commands.Complete(input, commandNames, window.FindCommand)
+}
+
+//ExecuteCommand tries to execute the given input as a command line. The
+//input may consist of multiple pipelines separated by ';', which are executed
+//one after another. If a command can't be found or the input is invalid,
+//that info will be printed onto the command output.
 func (window *Window) ExecuteCommand(input string) {
-	parts := commands.ParseCommand(input)
 	fmt.Fprintf(window.commandView, "[gray]$ %s\n", input)
+	window.executeCommandLine(window.commandView, nil, input)
+}
 
-	if len(parts) > 0 {
-		command := window.FindCommand(parts[0])
-		if command != nil {
-			command.Execute(window.commandView, parts[1:])
-		} else {
-			fmt.Fprintf(window.commandView, "[red]The command '%s' doesn't exist[white]\n", parts[0])
+// executeCommandLine parses the given command line and executes all of its
+// pipelines, writing their output into the given writer. If input isn't
+// nil, it is passed to the first pipeline.
+func (window *Window) executeCommandLine(writer io.Writer, input io.Reader, commandLine string) {
+	pipelines, parseError := commands.ParseCommandLine(commandLine)
+	if parseError != nil {
+		fmt.Fprintf(writer, "[red]Error parsing command:\n\t[red]%s\n", parseError)
+		return
+	}
+
+	for _, pipeline := range pipelines {
+		window.executePipeline(writer, input, pipeline)
+		input = nil
+	}
+}
+
+// executePipeline runs the given pipeline and either prints its output into
+// the writer or writes it into the pipelines output file. Colors are
+// removed from output that is written into a file.
+func (window *Window) executePipeline(writer io.Writer, input io.Reader, pipeline *commands.Pipeline) {
+	if pipeline.OutputFile == "" {
+		executeError := pipeline.ExecuteWithInput(writer, input, window.FindCommand)
+		if executeError != nil {
+			fmt.Fprintf(writer, "[red]Error executing command:\n\t[red]%s\n", executeError)
 		}
+		return
+	}
+
+	output := &bytes.Buffer{}
+	executeError := pipeline.ExecuteWithInput(output, input, window.FindCommand)
+	if executeError != nil {
+		fmt.Fprintf(writer, "[red]Error executing command:\n\t[red]%s\n", executeError)
+		return
+	}
+
+	resolvedPath := pipeline.OutputFile
+	if strings.HasPrefix(resolvedPath, "~") {
+		currentUser, userResolveError := user.Current()
+		if userResolveError != nil {
+			fmt.Fprintf(writer, "[red]Error resolving path:\n\t[red]%s\n", userResolveError)
+			return
+		}
+
+		resolvedPath = filepath.Join(currentUser.HomeDir, strings.TrimPrefix(resolvedPath, "~"))
+	}
+
+	flags := os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	if pipeline.Append {
+		flags = os.O_WRONLY | os.O_CREATE | os.O_APPEND
+	}
+	file, openError := os.OpenFile(resolvedPath, flags, 0644)
+	if openError != nil {
+		fmt.Fprintf(writer, "[red]Error writing output:\n\t[red]%s\n", openError)
+		return
+	}
+
+	_, writeError := file.WriteString(tviewutil.StripTags(output.String()))
+	closeError := file.Close()
+	if writeError == nil {
+		writeError = closeError
+	}
+	if writeError != nil {
+		fmt.Fprintf(writer, "[red]Error writing output:\n\t[red]%s\n", writeError)
 	}
 }
 
 func (window *Window) startEditingMessage(message *discordgo.Message) {
 	if message.Author.ID == window.session.State.User.ID {
+		window.exitReplyMode()
 		window.messageInput.SetText(message.Content)
 		window.messageInput.SetBorderColor(tcell.ColorYellow)
 		window.messageInput.SetBorderFocusColor(tcell.ColorYellow)
@@ -1855,6 +2364,29 @@ func (window *Window) exitMessageEditModeAndKeepText() {
 	window.messageInput.SetBorderFocusColor(tview.Styles.BorderFocusColor)
 }
 
+// startReplyingToMessage makes the next message that is sent a reply to the
+// given message. The message input shows whom is being replied to.
+func (window *Window) startReplyingToMessage(message *discordgo.Message) {
+	window.exitMessageEditModeAndKeepText()
+	window.replyingTo = message
+	window.messageInput.SetTitle("Replying to " + tview.Escape(discordutil.GetDisplayName(window.session.State, message.GuildID, message.Author)))
+	window.messageInput.SetBorderColor(tcell.ColorBlue)
+	window.messageInput.SetBorderFocusColor(tcell.ColorBlue)
+	window.app.SetFocus(window.messageInput.GetPrimitive())
+}
+
+// exitReplyMode makes the next message a normal message again.
+func (window *Window) exitReplyMode() {
+	if window.replyingTo == nil {
+		return
+	}
+
+	window.replyingTo = nil
+	window.messageInput.SetTitle("")
+	window.messageInput.SetBorderColor(tview.Styles.BorderColor)
+	window.messageInput.SetBorderFocusColor(tview.Styles.BorderFocusColor)
+}
+
 //ShowErrorDialog shows a simple error dialog that has only an Okay button,
 // a generic title and the given text.
 func (window *Window) ShowErrorDialog(text string) {
@@ -1978,13 +2510,18 @@ func (window *Window) RefreshLayout() {
 //LoadChannel eagerly loads the channels messages.
 func (window *Window) LoadChannel(channel *discordgo.Channel) error {
 	var messages []*discordgo.Message
+	//Only known if the channel is empty or all of its messages were retrieved.
+	beginningReached := channel.LastMessageID == ""
+	//Has to be retrieved before the channel is marked as read.
+	lastReadMessageID := readstate.GetLastReadMessageID(channel.ID)
 
 	if channel.LastMessageID != "" && len(channel.Messages) == 0 {
 		cache, cacheError := window.session.State.Channel(channel.ID)
 		if cacheError == nil || cache != nil && len(cache.Messages) == 0 {
 			var discordError error
-			messages, discordError = window.session.ChannelMessages(channel.ID, 100, "", "", "")
+			messages, discordError = discordutil.ChannelMessages(window.session, channel.ID, 100, "", "", "")
 			if discordError == nil {
+				beginningReached = len(messages) < 100
 				if channel.GuildID != "" {
 					for _, message := range messages {
 						message.GuildID = channel.GuildID
@@ -2003,9 +2540,12 @@ func (window *Window) LoadChannel(channel *discordgo.Channel) error {
 
 	window.chatView.Lock()
 	window.chatView.SetMessages(messages)
+	window.chatView.SetBeginningReached(beginningReached)
+	window.chatView.SetLastReadMessage(lastReadMessageID)
 	window.chatView.ClearSelection()
 	window.chatView.internalTextView.ScrollToEnd()
 	window.chatView.Unlock()
+	window.loadReferencedMessages(messages)
 
 	window.UpdateChatHeader(channel)
 
@@ -2052,12 +2592,15 @@ func (window *Window) LoadChannel(channel *discordgo.Channel) error {
 	window.channelTree.Unlock()
 
 	window.exitMessageEditModeAndKeepText()
+	window.exitReplyMode()
 
 	if config.GetConfig().FocusMessageInputAfterChannelSelection {
 		window.app.SetFocus(window.messageInput.internalTextView)
 	}
 
 	go func() {
+		window.scriptEngine.OnChannelSwitch(scripting.NewChannel(window.session.State, channel))
+
 		readstate.UpdateRead(window.session, channel, channel.LastMessageID)
 
 		// Here we make the assumption that the channel we are loading must be part
@@ -2071,7 +2614,7 @@ func (window *Window) LoadChannel(channel *discordgo.Channel) error {
 					for _, guildNode := range window.guildList.GetRoot().GetChildren() {
 						if guildNode.GetReference() == channel.GuildID {
 							window.guildList.SetCurrentNode(guildNode)
-							guildNode.SetColor(tview.Styles.ContrastBackgroundColor)
+							window.updateServerReadStatus(channel.GuildID, guildNode, true)
 							window.selectedGuildNode = guildNode
 							break
 						}
@@ -2084,25 +2627,216 @@ func (window *Window) LoadChannel(channel *discordgo.Channel) error {
 	return nil
 }
 
+// JumpToMessage loads the channel containing the given message and selects
+// the message in the ChatView. If the message isn't part of the recently
+// loaded messages, the messages surrounding it are loaded in the background
+// instead. Errors that occur while doing so are shown in a dialog.
+func (window *Window) JumpToMessage(channelID, messageID string) error {
+	channel, stateError := window.session.State.Channel(channelID)
+	if stateError != nil {
+		return stateError
+	}
+
+	switch channel.Type {
+	case discordgo.ChannelTypeDM, discordgo.ChannelTypeGroupDM:
+		window.SwitchToFriendsPage()
+		var channelNode *tview.TreeNode
+		for _, node := range window.privateList.chatsNode.GetChildren() {
+			if node.GetReference() == channelID {
+				channelNode = node
+				window.privateList.internalTreeView.SetCurrentNode(node)
+				break
+			}
+		}
+		window.privateList.onChannelSelect(channelNode, channelID)
+	case discordgo.ChannelTypeGuildText:
+		if !discordutil.HasReadMessagesPermission(channelID, window.session.State) {
+			return fmt.Errorf("no read permissions for channel: %s", channel.Name)
+		}
+
+		window.SwitchToGuildsPage()
+		if window.selectedGuild == nil || window.selectedGuild.ID != channel.GuildID {
+			for _, guildNode := range window.guildList.GetRoot().GetChildren() {
+				if guildNode.GetReference() == channel.GuildID {
+					window.guildList.SetCurrentNode(guildNode)
+					window.guildList.onGuildSelect(guildNode, channel.GuildID)
+					break
+				}
+			}
+		}
+
+		window.channelTree.GetRoot().Walk(func(node, parent *tview.TreeNode) bool {
+			if node.GetReference() == channelID {
+				window.channelTree.SetCurrentNode(node)
+				return false
+			}
+			return true
+		})
+		window.channelTree.onChannelSelect(channelID)
+	default:
+		return fmt.Errorf("invalid channel type: %v", channel.Type)
+	}
+
+	window.chatView.Lock()
+	selected := window.chatView.SelectMessage(messageID)
+	window.chatView.Unlock()
+	if selected {
+		window.app.SetFocus(window.chatView.internalTextView)
+		return nil
+	}
+
+	// The surrounding messages are retrieved in the background, errors
+	// occurring from here on are shown in a dialog.
+	go func() {
+		messages, discordError := discordutil.ChannelMessages(window.session, channelID, 100, "", "", messageID)
+		window.app.QueueUpdateDraw(func() {
+			if discordError != nil {
+				window.ShowErrorDialog(fmt.Sprintf("Error loading message: %s", discordError))
+				return
+			}
+
+			window.chatView.Lock()
+			defer window.chatView.Unlock()
+			if window.selectedChannel == nil || window.selectedChannel.ID != channelID {
+				return
+			}
+
+			for _, message := range messages {
+				message.GuildID = channel.GuildID
+			}
+			discordutil.SortMessagesByTimestamp(messages)
+			window.chatView.SetMessages(messages)
+			window.chatView.SetEndReached(len(messages) == 0 ||
+				messages[len(messages)-1].ID == channel.LastMessageID)
+			window.loadReferencedMessages(messages)
+
+			if !window.chatView.SelectMessage(messageID) {
+				window.ShowErrorDialog(fmt.Sprintf("The message %s doesn't exist anymore.", messageID))
+				return
+			}
+			window.app.SetFocus(window.chatView.internalTextView)
+		})
+	}()
+
+	return nil
+}
+
+// loadOlderMessages retrieves the page of messages preceding the oldest
+// displayed message of the loaded channel in the background and prepends it
+// to the chat view. The messages aren't added to the state cache, since it
+// only holds the latest messages of each channel.
+func (window *Window) loadOlderMessages() {
+	window.chatView.Lock()
+	defer window.chatView.Unlock()
+	if window.loadingMessages || window.selectedChannel == nil || len(window.chatView.data) == 0 {
+		return
+	}
+
+	channel := window.selectedChannel
+	oldestMessageID := window.chatView.data[0].ID
+	window.loadingMessages = true
+	go func() {
+		messages, discordError := discordutil.ChannelMessages(window.session, channel.ID, 100, oldestMessageID, "", "")
+		window.app.QueueUpdateDraw(func() {
+			window.chatView.Lock()
+			defer window.chatView.Unlock()
+			window.loadingMessages = false
+			if discordError != nil {
+				window.ShowErrorDialog(fmt.Sprintf("Error loading older messages: %s", discordError.Error()))
+				return
+			}
+
+			if window.selectedChannel == nil || window.selectedChannel.ID != channel.ID {
+				return
+			}
+
+			for _, message := range messages {
+				message.GuildID = channel.GuildID
+			}
+			discordutil.SortMessagesByTimestamp(messages)
+			window.chatView.PrependMessages(messages)
+			if len(messages) < 100 {
+				window.chatView.SetBeginningReached(true)
+			}
+			window.loadReferencedMessages(messages)
+		})
+	}()
+}
+
+// loadNewerMessages retrieves the page of messages following the newest
+// displayed message of the loaded channel in the background and appends it
+// to the chat view. This is only required after jumping to an older message,
+// since incoming messages aren't displayed until the gap to the latest
+// messages has been closed.
+func (window *Window) loadNewerMessages() {
+	window.chatView.Lock()
+	defer window.chatView.Unlock()
+	if window.loadingMessages || window.selectedChannel == nil || len(window.chatView.data) == 0 {
+		return
+	}
+
+	channel := window.selectedChannel
+	newestMessageID := window.chatView.data[len(window.chatView.data)-1].ID
+	window.loadingMessages = true
+	go func() {
+		messages, discordError := discordutil.ChannelMessages(window.session, channel.ID, 100, "", newestMessageID, "")
+		window.app.QueueUpdateDraw(func() {
+			window.chatView.Lock()
+			defer window.chatView.Unlock()
+			window.loadingMessages = false
+			if discordError != nil {
+				window.ShowErrorDialog(fmt.Sprintf("Error loading newer messages: %s", discordError.Error()))
+				return
+			}
+
+			if window.selectedChannel == nil || window.selectedChannel.ID != channel.ID {
+				return
+			}
+
+			for _, message := range messages {
+				message.GuildID = channel.GuildID
+			}
+			discordutil.SortMessagesByTimestamp(messages)
+			window.chatView.AddMessages(messages)
+			if len(messages) < 100 {
+				window.chatView.SetEndReached(true)
+			}
+			window.loadReferencedMessages(messages)
+		})
+	}()
+}
+
 // UpdateChatHeader updates the bordertitle of the chatviews container.o
 // The title consist of the channel name and its topic for guild channels.
 // For private channels it's either the recipient in a dm, or all recipients
 // in a group dm channel. If the channel has a nickname, that is chosen.
+// The amount of unread messages that were displayed when the channel was
+// loaded is added to the name.
 func (window *Window) UpdateChatHeader(channel *discordgo.Channel) {
 	if channel == nil {
 		return
 	}
 
+	var unreadText string
+	unreadCount, moreUnread := window.chatView.GetUnreadCount()
+	if moreUnread {
+		unreadText = fmt.Sprintf(" (%d+ new messages)", unreadCount)
+	} else if unreadCount == 1 {
+		unreadText = " (1 new message)"
+	} else if unreadCount > 1 {
+		unreadText = fmt.Sprintf(" (%d new messages)", unreadCount)
+	}
+
 	if channel.Type == discordgo.ChannelTypeGuildText {
 		if channel.Topic != "" {
-			window.chatView.SetTitle(channel.Name + " - " + channel.Topic)
+			window.chatView.SetTitle(channel.Name + unreadText + " - " + channel.Topic)
 		} else {
-			window.chatView.SetTitle(channel.Name)
+			window.chatView.SetTitle(channel.Name + unreadText)
 		}
 	} else if channel.Type == discordgo.ChannelTypeDM {
-		window.chatView.SetTitle(channel.Recipients[0].Username)
+		window.chatView.SetTitle(channel.Recipients[0].Username + unreadText)
 	} else {
-		window.chatView.SetTitle(discordutil.GetPrivateChannelName(channel))
+		window.chatView.SetTitle(discordutil.GetPrivateChannelName(channel) + unreadText)
 	}
 }
 
@@ -2113,10 +2847,52 @@ func (window *Window) RegisterCommand(command commands.Command) {
 	window.commands = append(window.commands, command)
 }
 
-// GetRegisteredCommands returns the map of all registered commands.
+// registerScriptCommand registers a command that has been defined by a
+// script. In case the name or one of the aliases is already taken, an
+// error is returned.
+func (window *Window) registerScriptCommand(command commands.Command) error {
+	names := append([]string{command.Name()}, command.Aliases()...)
+	for _, name := range names {
+		if window.FindCommand(name) != nil {
+			return fmt.Errorf("the command name '%s' is already in use", name)
+		}
+	}
+
+	window.scriptCommands = append(window.scriptCommands, command)
+	return nil
+}
+
+// unregisterScriptCommand removes a command that has previously been
+// registered by a script.
+func (window *Window) unregisterScriptCommand(command commands.Command) {
+	for index, registered := range window.scriptCommands {
+		if registered == command {
+			window.scriptCommands = append(window.scriptCommands[:index], window.scriptCommands[index+1:]...)
+			return
+		}
+	}
+}
+
+// ReformatMessages formats all messages in the chatview again. This is
+// necessary whenever scripts have changed, since they might change how
+// messages are rendered.
+func (window *Window) ReformatMessages() {
+	window.chatView.Lock()
+	defer window.chatView.Unlock()
+	window.chatView.ReformatMessages()
+}
+
+// GetScriptEngine returns the engine that runs all user scripts.
+func (window *Window) GetScriptEngine() scripting.Engine {
+	return window.scriptEngine
+}
+
+// GetRegisteredCommands returns all registered commands, including the ones
+// registered by scripts.
 func (window *Window) GetRegisteredCommands() []commands.Command {
-	//FIXME eh, should this be a copy?
-	return window.commands
+	registeredCommands := make([]commands.Command, 0, len(window.commands)+len(window.scriptCommands))
+	registeredCommands = append(registeredCommands, window.commands...)
+	return append(registeredCommands, window.scriptCommands...)
 }
 
 // GetSelectedGuild returns a reference to the currently selected Guild.
@@ -2129,6 +2905,29 @@ func (window *Window) GetSelectedChannel() *discordgo.Channel {
 	return window.selectedChannel
 }
 
+// GetLoadedMessage returns the message with the given ID if it is displayed
+// in the chat view. If the ID is empty, the latest displayed message is
+// returned. If there's no such message, nil is returned.
+func (window *Window) GetLoadedMessage(messageID string) *discordgo.Message {
+	window.chatView.Lock()
+	defer window.chatView.Unlock()
+
+	if messageID == "" {
+		if len(window.chatView.data) == 0 {
+			return nil
+		}
+		return window.chatView.data[len(window.chatView.data)-1]
+	}
+
+	for _, message := range window.chatView.data {
+		if message.ID == messageID {
+			return message
+		}
+	}
+
+	return nil
+}
+
 // PromptSecretInput shows an input dialog that masks the user input. The
 // returned value will either be empty or what the user has entered.
 func (window *Window) PromptSecretInput(title, message string) string {
@@ -2177,6 +2976,49 @@ func (window *Window) PromptSecretInput(title, message string) string {
 	return output
 }
 
+// backgroundOutput writes into the command view via the update queue of the
+// application, therefore it can be used from any goroutine. Writes never
+// block, since the update queue is bounded and writing from the UI thread
+// could otherwise deadlock. Text written until the next update is printed
+// at once.
+type backgroundOutput struct {
+	window *Window
+
+	mutex       sync.Mutex
+	pending     strings.Builder
+	flushQueued bool
+}
+
+func (output *backgroundOutput) Write(p []byte) (int, error) {
+	output.mutex.Lock()
+	defer output.mutex.Unlock()
+
+	output.pending.Write(p)
+	if !output.flushQueued {
+		output.flushQueued = true
+		go output.window.app.QueueUpdateDraw(output.flush)
+	}
+	return len(p), nil
+}
+
+func (output *backgroundOutput) flush() {
+	output.mutex.Lock()
+	text := output.pending.String()
+	output.pending.Reset()
+	output.flushQueued = false
+	output.mutex.Unlock()
+
+	fmt.Fprint(output.window.commandView, text)
+}
+
+// GetBackgroundOutput returns a writer that prints into the command view.
+// It is meant for commands that keep running in the background after
+// Execute has returned, since the writer passed to Execute mustn't be
+// used anymore at that point.
+func (window *Window) GetBackgroundOutput() io.Writer {
+	return window.backgroundOutput
+}
+
 // ForceRedraw triggers ForceDraw on the underlying tview application, causing
 // it to redraw all currently shown components.
 func (window *Window) ForceRedraw() {
@@ -2190,6 +3032,9 @@ func (window *Window) Run() error {
 
 // Shutdown disconnects from the discord API and stops the tview application.
 func (window *Window) Shutdown() {
+	if window.stopScriptWatcher != nil {
+		window.stopScriptWatcher()
+	}
 	if config.GetConfig().ShortenLinks {
 		window.chatView.shortener.Close()
 	}